@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"github.com/andrewpthorp/stripe-go/webhook"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Forwarder signs Events with Secret, as Stripe signs webhooks, and POSTs
+// them to URL. It prints each Event and the response of the endpoint to Out.
+type Forwarder struct {
+	URL    string
+	Secret string
+	Client *http.Client
+	Out    io.Writer
+	Now    func() time.Time
+}
+
+// Forward POSTs event to the endpoint, and prints the status it responds
+// with. The endpoint failing is not an error: it is printed, and Forward goes
+// on with the next Event, as Stripe would. Only ctx being done is returned.
+func (f *Forwarder) Forward(ctx context.Context, event *stripe.Event) error {
+	if event.Object == "" {
+		event.Object = "event"
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now
+	if f.Now != nil {
+		now = f.Now
+	}
+	fmt.Fprintf(f.Out, "%s   --> %s [%s]\n", now().Format("2006-01-02 15:04:05"), event.Type, event.Id)
+
+	req, err := http.NewRequestWithContext(ctx, "POST", f.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(webhook.SignatureHeader, webhook.GenerateHeader(payload, now(), f.Secret))
+
+	client := f.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		fmt.Fprintf(f.Out, "%s   <-- [ERR] POST %s [%s]: %v\n", now().Format("2006-01-02 15:04:05"), f.URL, event.Id, err)
+		return nil
+	}
+	io.Copy(ioutil.Discard, res.Body)
+	res.Body.Close()
+
+	fmt.Fprintf(f.Out, "%s   <-- [%d] POST %s [%s]\n", now().Format("2006-01-02 15:04:05"), res.StatusCode, f.URL, event.Id)
+	return nil
+}
+
+// matchType reports whether an Event of type t is to be forwarded, given the
+// types asked for, as stripe.EventTypes matches them.
+func matchType(types []string, t string) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, pattern := range types {
+		if stripe.MatchEventType(pattern, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// memoryCheckpoint keeps the stripe.EventCheckpoint of a forwarder for as long
+// as it runs: each run starts from the newest Event.
+type memoryCheckpoint struct {
+	checkpoint stripe.EventCheckpoint
+}
+
+func (m *memoryCheckpoint) Load() (stripe.EventCheckpoint, error) { return m.checkpoint, nil }
+
+func (m *memoryCheckpoint) Save(checkpoint stripe.EventCheckpoint) error {
+	m.checkpoint = checkpoint
+	return nil
+}
+
+// latest lists the Events newest first, and returns the last n of the given
+// types, oldest first, along with the checkpoint of the newest Event, from
+// which to tail those that follow.
+func latest(ctx context.Context, events *stripe.EventClient, types []string, n int) ([]*stripe.Event, stripe.EventCheckpoint, error) {
+	params := stripe.EventListParams{}
+	params.Limit = 100
+	if n > 0 && n < 100 {
+		params.Limit = n + 1
+	}
+
+	var replay []*stripe.Event
+	var checkpoint stripe.EventCheckpoint
+	i := events.IterContext(ctx, &params)
+	for i.Next() {
+		event := i.Event()
+		if checkpoint.Created == 0 {
+			checkpoint.Created = event.Created
+		}
+
+		// All of the Events of the newest second are in the checkpoint, so
+		// that none of them is tailed again.
+		if event.Created == checkpoint.Created {
+			checkpoint.Ids = append(checkpoint.Ids, event.Id)
+		} else if len(replay) == n {
+			break
+		}
+
+		if len(replay) < n && matchType(types, event.Type) {
+			replay = append(replay, event)
+		}
+	}
+	if err := i.Err(); err != nil {
+		return nil, checkpoint, err
+	}
+
+	// Without any Event, tail those from now on.
+	if checkpoint.Created == 0 {
+		checkpoint.Created = time.Now().Unix()
+	}
+
+	for l, r := 0, len(replay)-1; l < r; l, r = l+1, r-1 {
+		replay[l], replay[r] = replay[r], replay[l]
+	}
+	return replay, checkpoint, nil
+}
+
+// Listen forwards the last replay Events of the given types, then tails those
+// that follow, every interval, until ctx is done.
+func (f *Forwarder) Listen(ctx context.Context, events *stripe.EventClient, types []string, replay int, interval time.Duration) error {
+	last, checkpoint, err := latest(ctx, events, types, replay)
+	if err != nil {
+		return err
+	}
+	for _, event := range last {
+		if err := f.Forward(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	return events.Tail(ctx, &memoryCheckpoint{checkpoint}, f.Forward,
+		stripe.PollInterval(interval),
+		stripe.EventTypes(types...),
+		stripe.OnPollError(func(err error) {
+			fmt.Fprintf(f.Out, "Error listing events: %v\n", err)
+		}),
+	)
+}
+
+// loadFixtures reads the Events in the JSON files under dir, each holding an
+// Event or a list of them, as the API returns. Other files, and other objects,
+// are ignored. The Events are returned oldest first, each once.
+func loadFixtures(dir string) ([]*stripe.Event, error) {
+	var events []*stripe.Event
+	seen := map[string]bool{}
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Ext(path) != ".json" {
+			return err
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		var list struct {
+			Object string            `json:"object"`
+			Data   []json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil
+		}
+		objects := list.Data
+		if list.Object != "list" {
+			objects = []json.RawMessage{data}
+		}
+
+		for _, object := range objects {
+			if !isEvent(object) {
+				continue
+			}
+			event := &stripe.Event{}
+			if err := json.Unmarshal(object, event); err != nil {
+				return fmt.Errorf("%s: %v", path, err)
+			}
+			if seen[event.Id] {
+				continue
+			}
+			seen[event.Id] = true
+			events = append(events, event)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Created < events[j].Created })
+	return events, nil
+}
+
+// isEvent reports whether the JSON object is an Event. Fixtures may leave
+// out its "object".
+func isEvent(object json.RawMessage) bool {
+	var probe struct {
+		Id     string          `json:"id"`
+		Object string          `json:"object"`
+		Type   string          `json:"type"`
+		Data   json.RawMessage `json:"data"`
+	}
+	if json.Unmarshal(object, &probe) != nil || probe.Id == "" {
+		return false
+	}
+	return probe.Object == "event" || (probe.Object == "" && probe.Type != "" && probe.Data != nil)
+}
+
+// Replay forwards the last replay Events of the given types among events, or
+// all of them if replay is 0.
+func (f *Forwarder) Replay(ctx context.Context, events []*stripe.Event, types []string, replay int) error {
+	var matching []*stripe.Event
+	for _, event := range events {
+		if matchType(types, event.Type) {
+			matching = append(matching, event)
+		}
+	}
+	if replay > 0 && replay < len(matching) {
+		matching = matching[len(matching)-replay:]
+	}
+
+	for _, event := range matching {
+		if err := f.Forward(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
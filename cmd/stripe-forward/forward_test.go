@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"github.com/andrewpthorp/stripe-go/webhook"
+	"github.com/bmizerany/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const secret = "whsec_123456789"
+
+// endpoint is a webhook endpoint that verifies the events it receives, and
+// responds with status.
+type endpoint struct {
+	mu       sync.Mutex
+	status   int
+	received []string
+	onEvent  func(event *stripe.Event)
+}
+
+func (e *endpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	payload, _ := ioutil.ReadAll(r.Body)
+	event, err := webhook.ConstructEvent(payload, r.Header.Get(webhook.SignatureHeader), []string{secret})
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	e.mu.Lock()
+	e.received = append(e.received, event.Id)
+	e.mu.Unlock()
+	if e.onEvent != nil {
+		e.onEvent(event)
+	}
+	w.WriteHeader(e.status)
+}
+
+func (e *endpoint) ids() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string(nil), e.received...)
+}
+
+func newForwarder(url string) (*Forwarder, *bytes.Buffer) {
+	out := &bytes.Buffer{}
+	now := time.Now()
+	return &Forwarder{URL: url, Secret: secret, Out: out, Now: func() time.Time { return now }}, out
+}
+
+func TestForward(t *testing.T) {
+	e := &endpoint{status: http.StatusInternalServerError}
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	f, out := newForwarder(server.URL + "/webhook")
+	event := &stripe.Event{Id: "evt_123", Type: "charge.succeeded", Data: &stripe.EventData{Object: json.RawMessage(`{"id":"ch_123"}`)}}
+	assert.Equal(t, f.Forward(context.Background(), event), nil)
+	assert.Equal(t, e.ids(), []string{"evt_123"})
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, len(lines), 2)
+	assert.T(t, strings.HasSuffix(lines[0], "--> charge.succeeded [evt_123]"), lines[0])
+	assert.T(t, strings.HasSuffix(lines[1], "<-- [500] POST "+server.URL+"/webhook [evt_123]"), lines[1])
+}
+
+func TestForwardUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	f, out := newForwarder(server.URL)
+	assert.Equal(t, f.Forward(context.Background(), &stripe.Event{Id: "evt_123", Type: "charge.succeeded"}), nil)
+	assert.T(t, strings.Contains(out.String(), "<-- [ERR] POST "+server.URL+" [evt_123]: "), out.String())
+}
+
+func TestReplayFixtures(t *testing.T) {
+	e := &endpoint{status: http.StatusOK}
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	// The fixtures hold the same Event, alone and in a list.
+	events, err := loadFixtures("../../fixtures")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(events), 1)
+	assert.Equal(t, events[0].Id, "evt_123456789")
+
+	f, _ := newForwarder(server.URL)
+	assert.Equal(t, f.Replay(context.Background(), events, []string{"invoice.*"}, 0), nil)
+	assert.Equal(t, len(e.ids()), 0)
+	assert.Equal(t, f.Replay(context.Background(), events, []string{"customer.subscription.*"}, 0), nil)
+	assert.Equal(t, e.ids(), []string{"evt_123456789"})
+}
+
+func TestReplayLast(t *testing.T) {
+	e := &endpoint{status: http.StatusOK}
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	events := []*stripe.Event{
+		{Id: "evt_1", Type: "charge.succeeded"},
+		{Id: "evt_2", Type: "invoice.created"},
+		{Id: "evt_3", Type: "charge.succeeded"},
+		{Id: "evt_4", Type: "charge.succeeded"},
+	}
+	f, _ := newForwarder(server.URL)
+	f.Replay(context.Background(), events, []string{"charge.succeeded"}, 2)
+	assert.Equal(t, e.ids(), []string{"evt_3", "evt_4"})
+}
+
+// eventLog is a fake of the /events endpoint, listing its Events newest
+// first, filtered by created[gte], and paged with starting_after and limit.
+type eventLog struct {
+	mu     sync.Mutex
+	events []stripe.Event // newest first
+}
+
+func (l *eventLog) add(id string, created int64, typ string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append([]stripe.Event{{Id: id, Created: created, Type: typ}}, l.events...)
+}
+
+func (l *eventLog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	q := r.URL.Query()
+	gte, _ := strconv.ParseInt(q.Get("created[gte]"), 10, 64)
+	limit, _ := strconv.Atoi(q.Get("limit"))
+
+	data := []stripe.Event{}
+	started := q.Get("starting_after") == ""
+	more := false
+	for _, event := range l.events {
+		if !started {
+			started = event.Id == q.Get("starting_after")
+			continue
+		}
+		if event.Created < gte {
+			continue
+		}
+		if len(data) == limit {
+			more = true
+			break
+		}
+		data = append(data, event)
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"object": "list", "has_more": more, "data": data})
+}
+
+func TestListen(t *testing.T) {
+	log := &eventLog{}
+	log.add("evt_1", 1400000000, "charge.succeeded")
+	log.add("evt_2", 1400000001, "invoice.created")
+	log.add("evt_3", 1400000002, "charge.succeeded")
+	log.add("evt_4", 1400000002, "charge.succeeded")
+	api := httptest.NewServer(log)
+	defer api.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	e := &endpoint{status: http.StatusOK}
+	e.onEvent = func(event *stripe.Event) {
+		switch event.Id {
+		case "evt_4":
+			// Created after the forwarder started.
+			log.add("evt_5", 1400000002, "invoice.created")
+			log.add("evt_6", 1400000003, "charge.succeeded")
+		case "evt_6":
+			cancel()
+		}
+	}
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	client := stripe.NewClientWith(nil, api.URL, "sk_test_123")
+	f, out := newForwarder(server.URL)
+	err := f.Listen(ctx, client.Events, []string{"charge.*"}, 2, time.Millisecond)
+	assert.Equal(t, err, context.Canceled)
+	assert.Equal(t, e.ids(), []string{"evt_3", "evt_4", "evt_6"})
+	assert.T(t, strings.Contains(out.String(), "<-- [200] POST "+server.URL+" [evt_4]"), out.String())
+}
+
+func TestListenWithoutReplay(t *testing.T) {
+	log := &eventLog{}
+	log.add("evt_1", 1400000000, "charge.succeeded")
+	log.add("evt_2", 1400000000, "charge.succeeded")
+	api := httptest.NewServer(log)
+	defer api.Close()
+
+	client := stripe.NewClientWith(nil, api.URL, "sk_test_123")
+	events, checkpoint, err := latest(context.Background(), client.Events, nil, 0)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(events), 0)
+	assert.Equal(t, checkpoint, stripe.EventCheckpoint{Created: 1400000000, Ids: []string{"evt_2", "evt_1"}})
+}
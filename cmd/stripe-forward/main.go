@@ -0,0 +1,114 @@
+// Command stripe-forward forwards the events of a test mode Stripe account to
+// a webhook endpoint running locally, signed as Stripe signs them, so that
+// the endpoint can be developed without exposing it to the internet:
+//
+//	STRIPE_SECRET_KEY=sk_test_your_key go run ./cmd/stripe-forward -forward-to http://localhost:4242/webhook
+//
+// It polls the account for new events, and prints each event it forwards
+// along with the status the endpoint responds with. The endpoint verifies
+// them with the secret printed at start, or set with -secret.
+//
+// The flags are:
+//
+//	-forward-to URL  the endpoint to POST events to (required).
+//	-secret SECRET   the webhook signing secret, whsec_ and random by default.
+//	-events TYPES    comma separated event types to forward, such as
+//	                 "charge.succeeded,invoice.*". All of them by default.
+//	-replay N        forward the last N events first.
+//	-interval D      how often to poll for new events, 2s by default.
+//	-fixtures DIR    forward the events in the JSON files under DIR, such as
+//	                 those of this library's fixtures/, instead of polling the
+//	                 API, then exit. It works offline. All of them are
+//	                 forwarded, unless -replay is set.
+//	-api URL         the base URL of the API.
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+func main() {
+	forwardTo := flag.String("forward-to", "", "the endpoint to POST events to")
+	secret := flag.String("secret", "", "the webhook signing secret (random by default)")
+	events := flag.String("events", "", "comma separated event types to forward (all by default)")
+	replay := flag.Int("replay", 0, "forward the last N events first")
+	interval := flag.Duration("interval", 2*time.Second, "how often to poll for new events")
+	fixtures := flag.String("fixtures", "", "forward the events in the JSON files under this directory, offline")
+	api := flag.String("api", "https://api.stripe.com/v1", "the base URL of the API")
+	flag.Parse()
+
+	if *forwardTo == "" {
+		fmt.Fprintln(os.Stderr, "stripe-forward: -forward-to is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var types []string
+	if *events != "" {
+		types = strings.Split(*events, ",")
+	}
+
+	if *secret == "" {
+		*secret = randomSecret()
+	}
+	fmt.Printf("Your webhook signing secret is %s\n", *secret)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		cancel()
+	}()
+
+	f := &Forwarder{URL: *forwardTo, Secret: *secret, Out: os.Stdout}
+
+	if *fixtures != "" {
+		fixtureEvents, err := loadFixtures(*fixtures)
+		if err != nil {
+			fail(err)
+		}
+		if err := f.Replay(ctx, fixtureEvents, types, *replay); err != nil {
+			fail(err)
+		}
+		return
+	}
+
+	apiKey := os.Getenv("STRIPE_SECRET_KEY")
+	if apiKey == "" {
+		fail(fmt.Errorf("STRIPE_SECRET_KEY is not set"))
+	}
+	if strings.HasPrefix(apiKey, "sk_live_") || strings.HasPrefix(apiKey, "rk_live_") {
+		fail(fmt.Errorf("refusing to forward the events of live mode, use a test mode key"))
+	}
+
+	client := stripe.NewClientWith(nil, *api, apiKey)
+	fmt.Printf("Ready! Forwarding events to %s (^C to quit)\n", *forwardTo)
+	if err := f.Listen(ctx, client.Events, types, *replay, *interval); err != nil && err != context.Canceled {
+		fail(err)
+	}
+}
+
+// randomSecret returns a webhook signing secret, formatted as Stripe's.
+func randomSecret() string {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		fail(err)
+	}
+	return "whsec_" + hex.EncodeToString(b)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "stripe-forward:", stripe.Redact(err.Error()))
+	os.Exit(1)
+}
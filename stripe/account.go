@@ -1,6 +1,9 @@
 package stripe
 
+import "context"
+
 type Account struct {
+	APIResource
 	Id                  string   `json:"id"`
 	Object              string   `json:"object"`
 	ChargeEnabled       bool     `json:"charge_enabled"`
@@ -21,8 +24,16 @@ type AccountClient struct {
 // Retrieve loads a account.
 //
 // For more information: https://stripe.com/docs/api#retrieve_account
-func (c *AccountClient) Retrieve() (*Account, error) {
+func (c *AccountClient) Retrieve(opts ...RequestOption) (*Account, error) {
+	return c.RetrieveContext(context.Background(), opts...)
+}
+
+// RetrieveContext is like Retrieve, but uses ctx for the underlying request.
+func (c *AccountClient) RetrieveContext(ctx context.Context, opts ...RequestOption) (*Account, error) {
 	account := Account{}
-	err := c.client.get("/account", nil, &account)
-	return &account, err
+	err := c.client.get(ctx, "/account", nil, &account, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
 }
@@ -1,10 +1,9 @@
 package stripe
 
-import (
-	"net/url"
-)
+import "context"
 
 type ApplicationFee struct {
+	APIResource
 	Id                 string   `json:"id"`
 	Object             string   `json:"object"`
 	Livemode           bool     `json:"livemode"`
@@ -25,6 +24,17 @@ type ApplicationFeeListResponse struct {
 	Data []ApplicationFee `json:"data"`
 }
 
+// ApplicationFeeIter is an Iter over ApplicationFees.
+type ApplicationFeeIter struct {
+	*Iter
+}
+
+// ApplicationFee returns the ApplicationFee Next advanced to.
+func (it *ApplicationFeeIter) ApplicationFee() *ApplicationFee {
+	a, _ := it.Current().(*ApplicationFee)
+	return a
+}
+
 type ApplicationFeeClient struct {
 	client Client
 }
@@ -32,38 +42,97 @@ type ApplicationFeeClient struct {
 // Retrieve loads an application fee.
 //
 // For more information: https://stripe.com/docs/api#retrieve_application_fee
-func (c *ApplicationFeeClient) Retrieve(id string) (*ApplicationFee, error) {
+func (c *ApplicationFeeClient) Retrieve(id string, opts ...RequestOption) (*ApplicationFee, error) {
+	return c.RetrieveContext(context.Background(), id, opts...)
+}
+
+// RetrieveContext is like Retrieve, but uses ctx for the underlying request.
+func (c *ApplicationFeeClient) RetrieveContext(ctx context.Context, id string, opts ...RequestOption) (*ApplicationFee, error) {
 	fee := ApplicationFee{}
-	err := c.client.get("/application_fees/"+id, nil, &fee)
-	return &fee, err
+	err := c.client.get(ctx, "/application_fees/"+id, nil, &fee, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &fee, nil
 }
 
 // Refund refunds an application fee.
 //
 // For more information: https://stripe.com/docs/api#refund_application_fee
-func (c *ApplicationFeeClient) Refund(id string, params *RefundParams) (*ApplicationFee, error) {
+func (c *ApplicationFeeClient) Refund(id string, params *RefundParams, opts ...RequestOption) (*ApplicationFee, error) {
+	return c.RefundContext(context.Background(), id, params, opts...)
+}
+
+// RefundContext is like Refund, but uses ctx for the underlying request.
+func (c *ApplicationFeeClient) RefundContext(ctx context.Context, id string, params *RefundParams, opts ...RequestOption) (*ApplicationFee, error) {
 	fee := ApplicationFee{}
-	values := url.Values{}
-	addParamsToValues(params, &values)
-	err := c.client.post("/application_fees/"+id+"/refund", values, &fee)
-	return &fee, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.post(ctx, "/application_fees/"+id+"/refund", values, &fee, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &fee, nil
 }
 
-// All lists the first 10 application fees. It calls AllWithFilters with a blank
-// Filters so all defaults are used.
+// All lists the first 10 application fees. It calls AllWithParams with no
+// params so all defaults are used.
 //
 // For more information: https://stripe.com/docs/api#list_application_fees
-func (c *ApplicationFeeClient) All() (*ApplicationFeeListResponse, error) {
-	return c.AllWithFilters(Filters{})
+func (c *ApplicationFeeClient) All(opts ...RequestOption) (*ApplicationFeeListResponse, error) {
+	return c.AllContext(context.Background(), opts...)
+}
+
+// AllContext is like All, but uses ctx for the underlying request.
+func (c *ApplicationFeeClient) AllContext(ctx context.Context, opts ...RequestOption) (*ApplicationFeeListResponse, error) {
+	return c.AllWithParamsContext(ctx, nil, opts...)
 }
 
-// AllWithFilters takes a Filters and applies all valid filters for the action.
+// AllWithParams lists the ApplicationFees matching params.
 //
 // For more information: https://stripe.com/docs/api#list_application_fees
-func (c *ApplicationFeeClient) AllWithFilters(filters Filters) (*ApplicationFeeListResponse, error) {
+func (c *ApplicationFeeClient) AllWithParams(params *ApplicationFeeListParams, opts ...RequestOption) (*ApplicationFeeListResponse, error) {
+	return c.AllWithParamsContext(context.Background(), params, opts...)
+}
+
+// AllWithParamsContext is like AllWithParams, but uses ctx for the
+// underlying request.
+func (c *ApplicationFeeClient) AllWithParamsContext(ctx context.Context, params *ApplicationFeeListParams, opts ...RequestOption) (*ApplicationFeeListResponse, error) {
 	response := ApplicationFeeListResponse{}
-	values := url.Values{}
-	addFiltersToValues([]string{"count", "offset", "charge"}, filters, &values)
-	err := c.client.get("/application_fees", values, &response)
-	return &response, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.get(ctx, "/application_fees", values, &response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Iter returns an iterator over the ApplicationFees matching params,
+// fetching pages as they are needed.
+//
+// For more information: https://stripe.com/docs/api#pagination
+func (c *ApplicationFeeClient) Iter(params *ApplicationFeeListParams, opts ...RequestOption) *ApplicationFeeIter {
+	return c.IterContext(context.Background(), params, opts...)
+}
+
+// IterContext is like Iter, but uses ctx for the underlying requests.
+func (c *ApplicationFeeClient) IterContext(ctx context.Context, params *ApplicationFeeListParams, opts ...RequestOption) *ApplicationFeeIter {
+	p := ApplicationFeeListParams{}
+	if params != nil {
+		p = *params
+	}
+
+	return &ApplicationFeeIter{newIter(ctx, p.ListParams, func(ctx context.Context, page ListParams) ([]interface{}, ListResponse, error) {
+		p.ListParams = page
+		list, err := c.AllWithParamsContext(ctx, &p, opts...)
+		if err != nil {
+			return nil, ListResponse{}, err
+		}
+		return pageOf(list.Data), list.ListResponse, nil
+	})}
 }
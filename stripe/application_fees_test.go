@@ -30,11 +30,11 @@ func TestApplicationFeesAll(t *testing.T) {
 	assert.Equal(t, fees.Data[0].Id, "fee_123456789")
 }
 
-func TestApplicationFeesAllWithFilters(t *testing.T) {
+func TestApplicationFeesAllWithParams(t *testing.T) {
 	setup()
 	defer teardown()
 	handleWithJSON("/application_fees", "application_fees/application_fees.json")
-	fees, _ := client.ApplicationFees.AllWithFilters(Filters{})
+	fees, _ := client.ApplicationFees.AllWithParams(&ApplicationFeeListParams{})
 	assert.Equal(t, fees.Count, 1)
 	assert.Equal(t, fees.Data[0].Id, "fee_123456789")
 }
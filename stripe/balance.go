@@ -1,8 +1,6 @@
 package stripe
 
-import (
-	"net/url"
-)
+import "context"
 
 type Fund struct {
 	Amount   int64  `json:"amount"`
@@ -10,6 +8,7 @@ type Fund struct {
 }
 
 type Balance struct {
+	APIResource
 	Object    string `json:"object"`
 	Livemode  bool   `json:"livemode"`
 	Available []Fund `json:"available"`
@@ -25,18 +24,19 @@ type FeeDetails struct {
 }
 
 type BalanceTransaction struct {
-	Id          string       `json:"id"`
-	Object      string       `json:"object"`
-	Source      string       `json:"source"`
-	Amount      int64        `json:"amount"`
-	Currency    string       `json:"currency"`
-	Net         int64        `json:"net"`
-	Type        string       `json:"type"`
-	Created     int64        `json:"created"`
-	AvailableOn int64        `json:"available_on"`
-	Status      string       `json:"status"`
-	Fee         int64        `json:"fee"`
-	FeeDetails  []FeeDetails `json:"fee_details"`
+	APIResource
+	Id          string                   `json:"id"`
+	Object      string                   `json:"object"`
+	Source      BalanceTransactionSource `json:"source"`
+	Amount      int64                    `json:"amount"`
+	Currency    string                   `json:"currency"`
+	Net         int64                    `json:"net"`
+	Type        string                   `json:"type"`
+	Created     int64                    `json:"created"`
+	AvailableOn int64                    `json:"available_on"`
+	Status      string                   `json:"status"`
+	Fee         int64                    `json:"fee"`
+	FeeDetails  []FeeDetails             `json:"fee_details"`
 }
 
 type BalanceTransactionListResponse struct {
@@ -44,6 +44,17 @@ type BalanceTransactionListResponse struct {
 	Data []BalanceTransaction `json:"data"`
 }
 
+// BalanceTransactionIter is an Iter over BalanceTransactions.
+type BalanceTransactionIter struct {
+	*Iter
+}
+
+// BalanceTransaction returns the BalanceTransaction Next advanced to.
+func (it *BalanceTransactionIter) BalanceTransaction() *BalanceTransaction {
+	b, _ := it.Current().(*BalanceTransaction)
+	return b
+}
+
 type BalanceClient struct {
 	client Client
 }
@@ -51,36 +62,95 @@ type BalanceClient struct {
 // Retrieve loads a balance.
 //
 // For more information: https://stripe.com/docs/api#retrieve_balance
-func (c *BalanceClient) Retrieve() (*Balance, error) {
+func (c *BalanceClient) Retrieve(opts ...RequestOption) (*Balance, error) {
+	return c.RetrieveContext(context.Background(), opts...)
+}
+
+// RetrieveContext is like Retrieve, but uses ctx for the underlying request.
+func (c *BalanceClient) RetrieveContext(ctx context.Context, opts ...RequestOption) (*Balance, error) {
 	balance := Balance{}
-	err := c.client.get("/balance", nil, &balance)
-	return &balance, err
+	err := c.client.get(ctx, "/balance", nil, &balance, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &balance, nil
 }
 
 // RetrieveTransaction loads a balance transaction.
 //
 // For more information: https://stripe.com/docs/api#retrieve_balance_transaction
-func (c *BalanceClient) RetrieveTransaction(id string) (*BalanceTransaction, error) {
+func (c *BalanceClient) RetrieveTransaction(id string, opts ...RequestOption) (*BalanceTransaction, error) {
+	return c.RetrieveTransactionContext(context.Background(), id, opts...)
+}
+
+// RetrieveTransactionContext is like RetrieveTransaction, but uses ctx for the
+// underlying request.
+func (c *BalanceClient) RetrieveTransactionContext(ctx context.Context, id string, opts ...RequestOption) (*BalanceTransaction, error) {
 	balanceTransaction := BalanceTransaction{}
-	err := c.client.get("/balance/history/"+id, nil, &balanceTransaction)
-	return &balanceTransaction, err
+	err := c.client.get(ctx, "/balance/history/"+id, nil, &balanceTransaction, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &balanceTransaction, nil
 }
 
 // History lists the first 10 balances in the balance history. It calls
-// HistoryWithFilters with a blank Filters so all defaults are used.
+// HistoryWithParams with no params so all defaults are used.
 //
 // For more information: https://stripe.com/docs/api#balance_history
-func (c *BalanceClient) History() (*BalanceTransactionListResponse, error) {
-	return c.HistoryWithFilters(Filters{})
+func (c *BalanceClient) History(opts ...RequestOption) (*BalanceTransactionListResponse, error) {
+	return c.HistoryContext(context.Background(), opts...)
+}
+
+// HistoryContext is like History, but uses ctx for the underlying request.
+func (c *BalanceClient) HistoryContext(ctx context.Context, opts ...RequestOption) (*BalanceTransactionListResponse, error) {
+	return c.HistoryWithParamsContext(ctx, nil, opts...)
 }
 
-// HistoryWithFilters takes a Filters and applies all valid filters for the action.
+// HistoryWithParams lists the BalanceTransactions in the balance history
+// matching params.
 //
 // For more information: https://stripe.com/docs/api#balance_history
-func (c *BalanceClient) HistoryWithFilters(filters Filters) (*BalanceTransactionListResponse, error) {
+func (c *BalanceClient) HistoryWithParams(params *BalanceHistoryParams, opts ...RequestOption) (*BalanceTransactionListResponse, error) {
+	return c.HistoryWithParamsContext(context.Background(), params, opts...)
+}
+
+// HistoryWithParamsContext is like HistoryWithParams, but uses ctx for the
+// underlying request.
+func (c *BalanceClient) HistoryWithParamsContext(ctx context.Context, params *BalanceHistoryParams, opts ...RequestOption) (*BalanceTransactionListResponse, error) {
 	response := BalanceTransactionListResponse{}
-	values := url.Values{}
-	addFiltersToValues([]string{"count", "offset", "currency", "source", "transfer", "type"}, filters, &values)
-	err := c.client.get("/balance/history", values, &response)
-	return &response, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.get(ctx, "/balance/history", values, &response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// HistoryIter returns an iterator over the BalanceTransactions in the
+// balance history matching params, fetching pages as they are needed.
+//
+// For more information: https://stripe.com/docs/api#pagination
+func (c *BalanceClient) HistoryIter(params *BalanceHistoryParams, opts ...RequestOption) *BalanceTransactionIter {
+	return c.HistoryIterContext(context.Background(), params, opts...)
+}
+
+// HistoryIterContext is like HistoryIter, but uses ctx for the underlying requests.
+func (c *BalanceClient) HistoryIterContext(ctx context.Context, params *BalanceHistoryParams, opts ...RequestOption) *BalanceTransactionIter {
+	p := BalanceHistoryParams{}
+	if params != nil {
+		p = *params
+	}
+
+	return &BalanceTransactionIter{newIter(ctx, p.ListParams, func(ctx context.Context, page ListParams) ([]interface{}, ListResponse, error) {
+		p.ListParams = page
+		list, err := c.HistoryWithParamsContext(ctx, &p, opts...)
+		if err != nil {
+			return nil, ListResponse{}, err
+		}
+		return pageOf(list.Data), list.ListResponse, nil
+	})}
 }
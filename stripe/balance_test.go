@@ -31,11 +31,11 @@ func TestBalanceHistory(t *testing.T) {
 	assert.Equal(t, history.Data[0].Id, "txn_123456789")
 }
 
-func TestBalanceHistoryWithFilters(t *testing.T) {
+func TestBalanceHistoryWithParams(t *testing.T) {
 	setup()
 	defer teardown()
 	handleWithJSON("/balance/history", "balances/balance_history.json")
-	history, _ := client.Balance.HistoryWithFilters(Filters{})
+	history, _ := client.Balance.HistoryWithParams(&BalanceHistoryParams{})
 	assert.Equal(t, history.Count, 1)
 	assert.Equal(t, history.Data[0].Id, "txn_123456789")
 }
@@ -1,7 +1,5 @@
 package stripe
 
-import "net/url"
-
 type BankAccount struct {
 	Id          string `json:"id"`
 	Object      string `json:"object"`
@@ -13,10 +11,3 @@ type BankAccount struct {
 	Verified    bool   `json:"verified"`
 	Fingerprint string `json:"fingerprint"`
 }
-
-// parseBankAccountParams takes a pointer to a BankAccountParams and a pointer
-// to a url.Values. It iterates over everything in the BankAccountParams struct
-// and Adds what is there to the url.Values.
-func parseBankAccountParams(params *BankAccountParams, values *url.Values) {
-	addParamsToValues(params, values)
-}
@@ -1,11 +1,9 @@
 package stripe
 
-import (
-	"net/url"
-	"strconv"
-)
+import "context"
 
 type Card struct {
+	APIResource
 	Id                string `json:"id"`
 	Object            string `json:"object"`
 	ExpMonth          int64  `json:"exp_month"`
@@ -32,6 +30,17 @@ type CardListResponse struct {
 	Data []Card `json:"data"`
 }
 
+// CardIter is an Iter over Cards.
+type CardIter struct {
+	*Iter
+}
+
+// Card returns the Card Next advanced to.
+func (it *CardIter) Card() *Card {
+	c, _ := it.Current().(*Card)
+	return c
+}
+
 type CardClient struct {
 	client Client
 }
@@ -39,127 +48,140 @@ type CardClient struct {
 // Create creates a card for a customer.
 //
 // For more information: https://stripe.com/docs/api#create_card
-func (c *CardClient) Create(customerId string, params *CardParams) (*Card, error) {
+func (c *CardClient) Create(customerId string, params *CardParams, opts ...RequestOption) (*Card, error) {
+	return c.CreateContext(context.Background(), customerId, params, opts...)
+}
+
+// CreateContext is like Create, but uses ctx for the underlying request.
+func (c *CardClient) CreateContext(ctx context.Context, customerId string, params *CardParams, opts ...RequestOption) (*Card, error) {
 	card := Card{}
-	values := url.Values{}
-	parseCardParams(params, &values, true)
-	err := c.client.post("/customers/"+customerId+"/cards", values, &card)
-	return &card, err
+	values, err := encodeParams(&cardCreateParams{params})
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.post(ctx, "/customers/"+customerId+"/cards", values, &card, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &card, nil
 }
 
 // Retrieve loads a customers card.
 //
 // For more information: https://stripe.com/docs/api#retrieve_card
-func (c *CardClient) Retrieve(customerId, id string) (*Card, error) {
+func (c *CardClient) Retrieve(customerId, id string, opts ...RequestOption) (*Card, error) {
+	return c.RetrieveContext(context.Background(), customerId, id, opts...)
+}
+
+// RetrieveContext is like Retrieve, but uses ctx for the underlying request.
+func (c *CardClient) RetrieveContext(ctx context.Context, customerId, id string, opts ...RequestOption) (*Card, error) {
 	card := Card{}
-	err := c.client.get("/customers/"+customerId+"/cards/"+id, nil, &card)
-	return &card, err
+	err := c.client.get(ctx, "/customers/"+customerId+"/cards/"+id, nil, &card, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &card, nil
 }
 
 // Update updates a customers card.
 //
 // For more information: https://stripe.com/docs/api#update_card
-func (c *CardClient) Update(customerId, id string, params *CardParams) (*Card, error) {
+func (c *CardClient) Update(customerId, id string, params *CardParams, opts ...RequestOption) (*Card, error) {
+	return c.UpdateContext(context.Background(), customerId, id, params, opts...)
+}
+
+// UpdateContext is like Update, but uses ctx for the underlying request.
+func (c *CardClient) UpdateContext(ctx context.Context, customerId, id string, params *CardParams, opts ...RequestOption) (*Card, error) {
 	card := Card{}
-	values := url.Values{}
-	parseCardParams(params, &values, false)
-	err := c.client.post("/customers/"+customerId+"/cards/"+id, values, &card)
-	return &card, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.post(ctx, "/customers/"+customerId+"/cards/"+id, values, &card, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &card, nil
 }
 
 // Delete deletes a customers card.
 //
 // For more information: https://stripe.com/docs/api#delete_card
-func (c *CardClient) Delete(customerId, id string) (*DeleteResponse, error) {
+func (c *CardClient) Delete(customerId, id string, opts ...RequestOption) (*DeleteResponse, error) {
+	return c.DeleteContext(context.Background(), customerId, id, opts...)
+}
+
+// DeleteContext is like Delete, but uses ctx for the underlying request.
+func (c *CardClient) DeleteContext(ctx context.Context, customerId, id string, opts ...RequestOption) (*DeleteResponse, error) {
 	response := DeleteResponse{}
-	err := c.client.delete("/customers/"+customerId+"/cards/"+id, nil, &response)
-	return &response, err
+	err := c.client.delete(ctx, "/customers/"+customerId+"/cards/"+id, nil, &response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
 }
 
-// All lists the first 10 cards for a customer. It calls AllWithFilters with
-// a blank Filters so all defaults are used.
+// All lists the first 10 cards for a customer. It calls AllWithParams with
+// no params so all defaults are used.
 //
 // For more information: https://stripe.com/docs/api#list_cards
-func (c *CardClient) All(customerId string) (*CardListResponse, error) {
-	return c.AllWithFilters(customerId, Filters{})
+func (c *CardClient) All(customerId string, opts ...RequestOption) (*CardListResponse, error) {
+	return c.AllContext(context.Background(), customerId, opts...)
 }
 
-// AllWithFilters takes a Filters and applies all valid filters for the action.
-//
-// For more information: https://stripe.com/docs/api#list_cards
-func (c *CardClient) AllWithFilters(customerId string, filters Filters) (*CardListResponse, error) {
-	response := CardListResponse{}
-	values := url.Values{}
-	addFiltersToValues([]string{"count", "offset"}, filters, &values)
-	err := c.client.get("/customers/"+customerId+"/cards", values, &response)
-	return &response, err
+// AllContext is like All, but uses ctx for the underlying request.
+func (c *CardClient) AllContext(ctx context.Context, customerId string, opts ...RequestOption) (*CardListResponse, error) {
+	return c.AllWithParamsContext(ctx, customerId, nil, opts...)
 }
 
-// parseCardParams takes a pointer to a CardParams and a pointer to a
-// url.Values. It iterates over everything in the CardParams struct and Adds
-// what is there to the url.Values.
+// AllWithParams lists the Cards of the Customer matching params.
 //
-// If a Token is set on CardParams, that will be Added as "card" to the
-// url.Values and the rest of the CardParams are ignored.
-//
-// The last argument, `includeRoot`, determines whether the values are added
-// inside of a card[]. This is normally true for creates and false for updates.
-func parseCardParams(params *CardParams, values *url.Values, includeRoot bool) {
-
-	// If a token is passed, we are using that and not allowing a dictionary.
-	if params.Token != "" {
-		values.Add("card", params.Token)
-		return
-	}
-
-	var prefix, suffix string
-
-	if includeRoot {
-		prefix = "card["
-		suffix = "]"
-	}
-
-	if params.Number != "" {
-		values.Add(prefix+"number"+suffix, params.Number)
-	}
-
-	if params.CVC != "" {
-		values.Add(prefix+"cvc"+suffix, params.CVC)
-	}
-
-	if params.ExpMonth != 0 {
-		values.Add(prefix+"exp_month"+suffix, strconv.Itoa(params.ExpMonth))
-	}
-
-	if params.ExpYear != 0 {
-		values.Add(prefix+"exp_year"+suffix, strconv.Itoa(params.ExpYear))
-	}
-
-	if params.Name != "" {
-		values.Add(prefix+"name"+suffix, params.Name)
-	}
+// For more information: https://stripe.com/docs/api#list_cards
+func (c *CardClient) AllWithParams(customerId string, params *CardListParams, opts ...RequestOption) (*CardListResponse, error) {
+	return c.AllWithParamsContext(context.Background(), customerId, params, opts...)
+}
 
-	if params.AddressLine1 != "" {
-		values.Add(prefix+"address_line1"+suffix, params.AddressLine1)
+// AllWithParamsContext is like AllWithParams, but uses ctx for the
+// underlying request.
+func (c *CardClient) AllWithParamsContext(ctx context.Context, customerId string, params *CardListParams, opts ...RequestOption) (*CardListResponse, error) {
+	response := CardListResponse{}
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
 	}
-
-	if params.AddressLine2 != "" {
-		values.Add(prefix+"address_line2"+suffix, params.AddressLine2)
+	err = c.client.get(ctx, "/customers/"+customerId+"/cards", values, &response, opts...)
+	if err != nil {
+		return nil, err
 	}
+	return &response, nil
+}
 
-	if params.AddressCity != "" {
-		values.Add(prefix+"address_city"+suffix, params.AddressCity)
-	}
+// Iter returns an iterator over the Cards of the Customer matching params,
+// fetching pages as they are needed.
+//
+// For more information: https://stripe.com/docs/api#pagination
+func (c *CardClient) Iter(customerId string, params *CardListParams, opts ...RequestOption) *CardIter {
+	return c.IterContext(context.Background(), customerId, params, opts...)
+}
 
-	if params.AddressZip != "" {
-		values.Add(prefix+"address_zip"+suffix, params.AddressZip)
+// IterContext is like Iter, but uses ctx for the underlying requests.
+func (c *CardClient) IterContext(ctx context.Context, customerId string, params *CardListParams, opts ...RequestOption) *CardIter {
+	p := CardListParams{}
+	if params != nil {
+		p = *params
 	}
 
-	if params.AddressState != "" {
-		values.Add(prefix+"address_state"+suffix, params.AddressState)
-	}
+	return &CardIter{newIter(ctx, p.ListParams, func(ctx context.Context, page ListParams) ([]interface{}, ListResponse, error) {
+		p.ListParams = page
+		list, err := c.AllWithParamsContext(ctx, customerId, &p, opts...)
+		if err != nil {
+			return nil, ListResponse{}, err
+		}
+		return pageOf(list.Data), list.ListResponse, nil
+	})}
+}
 
-	if params.AddressCountry != "" {
-		values.Add(prefix+"address_country"+suffix, params.AddressCountry)
-	}
+// cardCreateParams nests CardParams under card[], as creating a Card expects.
+type cardCreateParams struct {
+	Card *CardParams `stripe_field:"card"`
 }
@@ -2,7 +2,6 @@ package stripe
 
 import (
 	"github.com/bmizerany/assert"
-	"net/url"
 	"strconv"
 	"testing"
 )
@@ -49,63 +48,62 @@ func TestCardsAll(t *testing.T) {
 	assert.Equal(t, cards.Data[0].Id, "card_123456789")
 }
 
-func TestCardsAllWithFilters(t *testing.T) {
+func TestCardsAllWithParams(t *testing.T) {
 	setup()
 	defer teardown()
 	handleWithJSON("/customers/cus_123456789/cards", "cards/cards.json")
-	cards, _ := client.Cards.AllWithFilters("cus_123456789", Filters{})
+	cards, _ := client.Cards.AllWithParams("cus_123456789", &CardListParams{})
 	assert.Equal(t, cards.Count, 1)
 	assert.Equal(t, cards.Data[0].Id, "card_123456789")
 }
 
-func TestParseCardParamsWithRoot(t *testing.T) {
+func TestEncodeCardParamsWithRoot(t *testing.T) {
 	params := CardParams{
-		Number:         "4242424242424242",
-		ExpMonth:       01,
-		ExpYear:        2020,
-		CVC:            "111",
-		Name:           "Andrew Thorp",
-		AddressLine1:   "1 Something Lane",
-		AddressLine2:   "Suite 200",
-		AddressCity:    "San Francisco",
-		AddressZip:     "94110",
-		AddressState:   "CA",
-		AddressCountry: "USA",
+		Number:         String("4242424242424242"),
+		ExpMonth:       Int(01),
+		ExpYear:        Int(2020),
+		CVC:            String("111"),
+		Name:           String("Andrew Thorp"),
+		AddressLine1:   String("1 Something Lane"),
+		AddressLine2:   String("Suite 200"),
+		AddressCity:    String("San Francisco"),
+		AddressZip:     String("94110"),
+		AddressState:   String("CA"),
+		AddressCountry: String("USA"),
 	}
-	values := url.Values{}
 
 	// With Root card[]
-	parseCardParams(&params, &values, true)
-	assert.Equal(t, values.Get("card[number]"), params.Number)
-	assert.Equal(t, values.Get("card[exp_month]"), strconv.Itoa(params.ExpMonth))
-	assert.Equal(t, values.Get("card[exp_year]"), strconv.Itoa(params.ExpYear))
-	assert.Equal(t, values.Get("card[cvc]"), params.CVC)
-	assert.Equal(t, values.Get("card[name]"), params.Name)
-	assert.Equal(t, values.Get("card[address_line1]"), params.AddressLine1)
-	assert.Equal(t, values.Get("card[address_line2]"), params.AddressLine2)
-	assert.Equal(t, values.Get("card[address_city]"), params.AddressCity)
-	assert.Equal(t, values.Get("card[address_zip]"), params.AddressZip)
-	assert.Equal(t, values.Get("card[address_state]"), params.AddressState)
-	assert.Equal(t, values.Get("card[address_country]"), params.AddressCountry)
+	values, _ := encodeParams(&cardCreateParams{&params})
+	assert.Equal(t, values.Get("card[number]"), *params.Number)
+	assert.Equal(t, values.Get("card[exp_month]"), strconv.Itoa(*params.ExpMonth))
+	assert.Equal(t, values.Get("card[exp_year]"), strconv.Itoa(*params.ExpYear))
+	assert.Equal(t, values.Get("card[cvc]"), *params.CVC)
+	assert.Equal(t, values.Get("card[name]"), *params.Name)
+	assert.Equal(t, values.Get("card[address_line1]"), *params.AddressLine1)
+	assert.Equal(t, values.Get("card[address_line2]"), *params.AddressLine2)
+	assert.Equal(t, values.Get("card[address_city]"), *params.AddressCity)
+	assert.Equal(t, values.Get("card[address_zip]"), *params.AddressZip)
+	assert.Equal(t, values.Get("card[address_state]"), *params.AddressState)
+	assert.Equal(t, values.Get("card[address_country]"), *params.AddressCountry)
 
 	// Without root card[]
-	parseCardParams(&params, &values, false)
-	assert.Equal(t, values.Get("number"), params.Number)
-	assert.Equal(t, values.Get("exp_month"), strconv.Itoa(params.ExpMonth))
-	assert.Equal(t, values.Get("exp_year"), strconv.Itoa(params.ExpYear))
-	assert.Equal(t, values.Get("cvc"), params.CVC)
-	assert.Equal(t, values.Get("name"), params.Name)
-	assert.Equal(t, values.Get("address_line1"), params.AddressLine1)
-	assert.Equal(t, values.Get("address_line2"), params.AddressLine2)
-	assert.Equal(t, values.Get("address_city"), params.AddressCity)
-	assert.Equal(t, values.Get("address_zip"), params.AddressZip)
-	assert.Equal(t, values.Get("address_state"), params.AddressState)
-	assert.Equal(t, values.Get("address_country"), params.AddressCountry)
+	values, _ = encodeParams(&params)
+	assert.Equal(t, values.Get("number"), *params.Number)
+	assert.Equal(t, values.Get("exp_month"), strconv.Itoa(*params.ExpMonth))
+	assert.Equal(t, values.Get("exp_year"), strconv.Itoa(*params.ExpYear))
+	assert.Equal(t, values.Get("cvc"), *params.CVC)
+	assert.Equal(t, values.Get("name"), *params.Name)
+	assert.Equal(t, values.Get("address_line1"), *params.AddressLine1)
+	assert.Equal(t, values.Get("address_line2"), *params.AddressLine2)
+	assert.Equal(t, values.Get("address_city"), *params.AddressCity)
+	assert.Equal(t, values.Get("address_zip"), *params.AddressZip)
+	assert.Equal(t, values.Get("address_state"), *params.AddressState)
+	assert.Equal(t, values.Get("address_country"), *params.AddressCountry)
 
 	// Token
-	params = CardParams{Token: "tok_123456789"}
-	parseCardParams(&params, &values, false)
-	assert.Equal(t, values.Get("card"), params.Token)
-	assert.NotEqual(t, values.Get("number"), params.Number)
-	assert.NotEqual(t, values.Get("card[number]"), params.Number)
+	params = CardParams{Token: String("tok_123456789"), Number: String("4242424242424242")}
+	values, _ = encodeParams(&params)
+	assert.Equal(t, values.Get("card"), *params.Token)
+	assert.Equal(t, values.Get("number"), "")
+	assert.Equal(t, values.Get("card[number]"), "")
 }
@@ -0,0 +1,292 @@
+package stripe
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FieldChange is a field of the resource an Event is about, which the update
+// the Event reports changed. Path is the name of the field in the API, after
+// those of the fields it is nested in: "quantity", "plan.id" or
+// "metadata.order_id".
+//
+// Old and New hold the field before and after the update, with the type of the
+// matching field of the resource's struct: an int64 for the "quantity" of a
+// Subscription, a string for its "plan.id". Fields the struct does not have
+// hold the decoded JSON instead.
+type FieldChange struct {
+	Path string
+	Old  interface{}
+	New  interface{}
+}
+
+// FieldChanges are the fields an update changed, sorted by Path.
+type FieldChanges []FieldChange
+
+// Get returns the FieldChange of the field at path, and whether it changed.
+func (c FieldChanges) Get(path string) (FieldChange, bool) {
+	for _, change := range c {
+		if change.Path == path {
+			return change, true
+		}
+	}
+	return FieldChange{}, false
+}
+
+// Changed reports whether the field at path changed.
+func (c FieldChanges) Changed(path string) bool {
+	_, ok := c.Get(path)
+	return ok
+}
+
+// SubscriptionDiff is the update a "customer.subscription.updated" Event
+// reports: the Subscription before and after it, and the fields it changed.
+//
+//	diff, err := event.SubscriptionDiff()
+//	if change, ok := diff.Changes.Get("plan.id"); ok {
+//		fmt.Println("moved from", change.Old, "to", change.New)
+//	}
+type SubscriptionDiff struct {
+	Previous *Subscription
+	Current  *Subscription
+	Changes  FieldChanges
+}
+
+// CustomerDiff is the update a "customer.updated" Event reports: the Customer
+// before and after it, and the fields it changed.
+type CustomerDiff struct {
+	Previous *Customer
+	Current  *Customer
+	Changes  FieldChanges
+}
+
+// InvoiceDiff is the update an "invoice.updated" Event reports: the Invoice
+// before and after it, and the fields it changed.
+type InvoiceDiff struct {
+	Previous *Invoice
+	Current  *Invoice
+	Changes  FieldChanges
+}
+
+// SubscriptionDiff returns the update the Event reports to its Subscription,
+// or an error if it is about another kind of resource.
+func (e *Event) SubscriptionDiff() (*SubscriptionDiff, error) {
+	diff := SubscriptionDiff{Previous: &Subscription{}, Current: &Subscription{}}
+	changes, err := e.diff("subscription", diff.Previous, diff.Current)
+	if err != nil {
+		return nil, err
+	}
+	diff.Changes = changes
+	return &diff, nil
+}
+
+// CustomerDiff returns the update the Event reports to its Customer, or an
+// error if it is about another kind of resource.
+func (e *Event) CustomerDiff() (*CustomerDiff, error) {
+	diff := CustomerDiff{Previous: &Customer{}, Current: &Customer{}}
+	changes, err := e.diff("customer", diff.Previous, diff.Current)
+	if err != nil {
+		return nil, err
+	}
+	diff.Changes = changes
+	return &diff, nil
+}
+
+// InvoiceDiff returns the update the Event reports to its Invoice, or an error
+// if it is about another kind of resource.
+func (e *Event) InvoiceDiff() (*InvoiceDiff, error) {
+	diff := InvoiceDiff{Previous: &Invoice{}, Current: &Invoice{}}
+	changes, err := e.diff("invoice", diff.Previous, diff.Current)
+	if err != nil {
+		return nil, err
+	}
+	diff.Changes = changes
+	return &diff, nil
+}
+
+// Changes returns the fields of the resource the Event is about that the
+// update it reports changed, whatever kind of resource it is. Events that do
+// not report an update, without previous attributes, have no changes.
+func (e *Event) Changes() (FieldChanges, error) {
+	object := e.DataObjectType()
+	newObject, ok := eventObjects[object]
+	if !ok {
+		return e.diff(object, nil, nil)
+	}
+	return e.diff(object, newObject(), newObject())
+}
+
+// diff decodes the resource the Event is about into current, and the resource
+// as it was before the update into previous, which both point to the type of
+// the given object, or are nil to only compare the JSON. It returns the
+// changes between them.
+func (e *Event) diff(object string, previous, current interface{}) (FieldChanges, error) {
+	if t := e.DataObjectType(); t != object {
+		return nil, fmt.Errorf("stripe: event %s is about a %q, not a %q", e.Id, t, object)
+	}
+	if e.Data == nil || len(e.Data.Object) == 0 {
+		return nil, nil
+	}
+
+	// The resource before the update is the current one, with the previous
+	// attributes put back.
+	currentJSON, previousJSON := map[string]interface{}{}, map[string]interface{}{}
+	if err := json.Unmarshal(e.Data.Object, &currentJSON); err != nil {
+		return nil, newObjectDecodeError(e.Data.Object, &currentJSON, err)
+	}
+	if json.Unmarshal(e.Data.Object, &previousJSON); previousJSON == nil {
+		previousJSON = map[string]interface{}{}
+	}
+	mergeAttributes(previousJSON, e.Data.PreviousAttributes)
+
+	if current != nil {
+		if err := json.Unmarshal(e.Data.Object, current); err != nil {
+			return nil, newObjectDecodeError(e.Data.Object, current, err)
+		}
+	}
+	if previous != nil {
+		data, err := json.Marshal(previousJSON)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, previous); err != nil {
+			return nil, newObjectDecodeError(data, previous, err)
+		}
+	}
+
+	var changes FieldChanges
+	for _, path := range changedPaths(nil, e.Data.PreviousAttributes, currentJSON) {
+		change := FieldChange{
+			Path: strings.Join(path, "."),
+			Old:  valueAt(previousJSON, path),
+			New:  valueAt(currentJSON, path),
+		}
+		if v, ok := fieldAt(reflect.ValueOf(previous), path); ok {
+			change.Old = v.Interface()
+		}
+		if v, ok := fieldAt(reflect.ValueOf(current), path); ok {
+			change.New = v.Interface()
+		}
+		changes = append(changes, change)
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// mergeAttributes sets the attributes in object, merging those that are
+// objects in both rather than replacing them.
+func mergeAttributes(object, attributes map[string]interface{}) {
+	for k, v := range attributes {
+		nested, ok := v.(map[string]interface{})
+		if into, isObject := object[k].(map[string]interface{}); ok && isObject {
+			mergeAttributes(into, nested)
+			continue
+		}
+		object[k] = v
+	}
+}
+
+// changedPaths returns the path, under prefix, of each value in previous that
+// differs from the one in current. Values that are objects in both are
+// compared field by field, anything else as a whole.
+func changedPaths(prefix []string, previous, current map[string]interface{}) [][]string {
+	var paths [][]string
+	for k, old := range previous {
+		path := append(append([]string(nil), prefix...), k)
+
+		oldObject, ok := old.(map[string]interface{})
+		if newObject, isObject := current[k].(map[string]interface{}); ok && isObject {
+			paths = append(paths, changedPaths(path, oldObject, newObject)...)
+			continue
+		}
+
+		if !reflect.DeepEqual(old, current[k]) {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// valueAt returns the value at path in the decoded JSON object, or nil.
+func valueAt(object map[string]interface{}, path []string) interface{} {
+	var v interface{} = object
+	for _, k := range path {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[k]
+	}
+	return v
+}
+
+// fieldAt returns the field of v at path, following the json tags of structs
+// and the keys of maps. Nil pointers and missing map keys are walked as the
+// zero value of their type, so that the field has its type even if unset. It
+// returns false if there is no field at path.
+func fieldAt(v reflect.Value, path []string) (reflect.Value, bool) {
+	if !v.IsValid() {
+		return v, false
+	}
+
+	for _, name := range path {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v = reflect.Zero(v.Type().Elem())
+			} else {
+				v = v.Elem()
+			}
+		}
+
+		switch v.Kind() {
+		case reflect.Struct:
+			field, ok := fieldByJSONName(v, name)
+			if !ok {
+				return v, false
+			}
+			v = field
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return v, false
+			}
+			value := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+			if !value.IsValid() {
+				value = reflect.Zero(v.Type().Elem())
+			}
+			v = value
+		default:
+			return v, false
+		}
+	}
+
+	return v, true
+}
+
+// fieldByJSONName returns the field of the struct v whose json tag is name,
+// looking into embedded structs too.
+func fieldByJSONName(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if tag == "" && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			if f, ok := fieldByJSONName(v.Field(i), name); ok {
+				return f, true
+			}
+			continue
+		}
+
+		if tag == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
@@ -0,0 +1,137 @@
+package stripe
+
+import (
+	"encoding/json"
+	"github.com/bmizerany/assert"
+	"testing"
+)
+
+// eventUpdating returns an Event whose data object is the JSON in the fixture
+// f, with the given previous attributes.
+func eventUpdating(f, previous string) *Event {
+	event := eventAbout(f)
+	json.Unmarshal([]byte(previous), &event.Data.PreviousAttributes)
+	return event
+}
+
+// paths returns the Path of each FieldChange.
+func paths(changes FieldChanges) []string {
+	var p []string
+	for _, change := range changes {
+		p = append(p, change.Path)
+	}
+	return p
+}
+
+func TestEventSubscriptionDiff(t *testing.T) {
+	event := Event{}
+	json.Unmarshal([]byte(loadFixture("events/event.json")), &event)
+
+	diff, err := event.SubscriptionDiff()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, diff.Previous.Plan.Id, "monthly_plan_id")
+	assert.Equal(t, diff.Current.Plan.Id, "plan_id")
+	assert.Equal(t, diff.Previous.Id, "sub_123456789")
+
+	// Attributes that are the same as before are not changes.
+	assert.Equal(t, paths(diff.Changes), []string{"plan.amount", "plan.id", "plan.interval", "plan.name"})
+	assert.T(t, !diff.Changes.Changed("start"))
+	assert.T(t, !diff.Changes.Changed("plan.currency"))
+
+	change, ok := diff.Changes.Get("plan.id")
+	assert.T(t, ok)
+	assert.Equal(t, change, FieldChange{Path: "plan.id", Old: "monthly_plan_id", New: "plan_id"})
+
+	change, _ = diff.Changes.Get("plan.amount")
+	assert.Equal(t, change.Old, int64(1500))
+	assert.Equal(t, change.New, int64(10000))
+}
+
+func TestEventCustomerDiff(t *testing.T) {
+	event := eventUpdating("customers/customer.json", `{
+		"account_balance": 500,
+		"description": "A customer",
+		"discount": {"object": "discount", "coupon": {"id": "FREE"}, "start": 123456789},
+		"metadata": {"twitter": "@apt", "plan": "gold"}
+	}`)
+
+	diff, err := event.CustomerDiff()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, paths(diff.Changes), []string{
+		"account_balance", "description", "discount", "metadata.plan", "metadata.twitter",
+	})
+
+	change, _ := diff.Changes.Get("account_balance")
+	assert.Equal(t, change.Old, int64(500))
+	assert.Equal(t, change.New, int64(0))
+
+	// Customer has no Description, so it is left as decoded JSON.
+	change, _ = diff.Changes.Get("description")
+	assert.Equal(t, change.Old, "A customer")
+	assert.Equal(t, change.New, "A pretty awesome customer")
+
+	// A removed discount is a nil *Discount.
+	change, _ = diff.Changes.Get("discount")
+	assert.Equal(t, change.Old.(*Discount).Coupon.Id, "FREE")
+	assert.Equal(t, change.New, (*Discount)(nil))
+
+	// A removed metadata key is an empty string.
+	change, _ = diff.Changes.Get("metadata.plan")
+	assert.Equal(t, change.Old, "gold")
+	assert.Equal(t, change.New, "")
+	assert.Equal(t, diff.Previous.Metadata, Metadata{"twitter": "@apt", "plan": "gold"})
+	assert.Equal(t, diff.Current.Metadata, Metadata{"twitter": "@andrewpthorp"})
+}
+
+func TestEventInvoiceDiff(t *testing.T) {
+	event := eventUpdating("invoices/invoice.json", `{"paid": false, "attempt_count": 0, "charge": null}`)
+
+	diff, err := event.InvoiceDiff()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, paths(diff.Changes), []string{"charge", "paid"})
+
+	change, _ := diff.Changes.Get("paid")
+	assert.Equal(t, change.Old, false)
+	assert.Equal(t, change.New, true)
+
+	change, _ = diff.Changes.Get("charge")
+	assert.Equal(t, change.Old, ChargeField{})
+	assert.Equal(t, change.New, ChargeField{Id: "ch_123456789"})
+}
+
+func TestEventDiffWrongType(t *testing.T) {
+	diff, err := eventAbout("charges/charge.json").SubscriptionDiff()
+	assert.Equal(t, diff, (*SubscriptionDiff)(nil))
+	assert.Equal(t, err.Error(), `stripe: event evt_123456789 is about a "charge", not a "subscription"`)
+}
+
+func TestEventChanges(t *testing.T) {
+	changes, err := eventUpdating("charges/charge.json", `{"refunded": false, "amount_refunded": 0}`).Changes()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, paths(changes), []string(nil))
+
+	changes, err = eventUpdating("plans/plan.json", `{"name": "Old Plan"}`).Changes()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(changes), 1)
+	assert.Equal(t, changes[0].Path, "name")
+
+	// Resources without a type are compared as JSON.
+	event := eventUpdating("sample.json", `{"amount": 5, "owner": {"email": "old@stripe.com"}}`)
+	event.Data.Object = json.RawMessage(`{"object": "bitcoin_receiver", "amount": 10, "owner": {"email": "new@stripe.com"}}`)
+	changes, err = event.Changes()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, changes, FieldChanges{
+		{Path: "amount", Old: float64(5), New: float64(10)},
+		{Path: "owner.email", Old: "old@stripe.com", New: "new@stripe.com"},
+	})
+}
+
+func TestEventChangesWithoutPreviousAttributes(t *testing.T) {
+	changes, err := eventAbout("customers/customer.json").Changes()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(changes), 0)
+
+	changes, err = (&Event{}).Changes()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, len(changes), 0)
+}
@@ -1,30 +1,29 @@
 package stripe
 
-import (
-	"net/url"
-)
+import "context"
 
 type Charge struct {
-	Id                 string   `json:"id"`
-	Object             string   `json:"object"`
-	Livemode           bool     `json:"livemode"`
-	Amount             int64    `json:"amount"`
-	Captured           bool     `json:"captured"`
-	Card               *Card    `json:"card"`
-	Created            int64    `json:"created"`
-	Currency           string   `json:"currency"`
-	Paid               bool     `json:"paid"`
-	Refunded           bool     `json:"refunded"`
-	Refunds            []Refund `json:"refunds"`
-	AmountRefunded     int64    `json:"amount_refunded"`
-	BalanceTransaction string   `json:"balance_transaction"`
-	Customer           string   `json:"customer"`
-	Description        string   `json:"description"`
-	Dispute            *Dispute `json:"dispute"`
-	FailureCode        string   `json:"failure_code"`
-	FailureMessage     string   `json:"failure_message"`
-	Invoice            string   `json:"invoice"`
-	Metadata           Metadata `json:"metadata"`
+	APIResource
+	Id                 string                  `json:"id"`
+	Object             string                  `json:"object"`
+	Livemode           bool                    `json:"livemode"`
+	Amount             int64                   `json:"amount"`
+	Captured           bool                    `json:"captured"`
+	Card               *Card                   `json:"card"`
+	Created            int64                   `json:"created"`
+	Currency           string                  `json:"currency"`
+	Paid               bool                    `json:"paid"`
+	Refunded           bool                    `json:"refunded"`
+	Refunds            []Refund                `json:"refunds"`
+	AmountRefunded     int64                   `json:"amount_refunded"`
+	BalanceTransaction BalanceTransactionField `json:"balance_transaction"`
+	Customer           CustomerField           `json:"customer"`
+	Description        string                  `json:"description"`
+	Dispute            *Dispute                `json:"dispute"`
+	FailureCode        string                  `json:"failure_code"`
+	FailureMessage     string                  `json:"failure_message"`
+	Invoice            InvoiceField            `json:"invoice"`
+	Metadata           Metadata                `json:"metadata"`
 }
 
 type ChargeListResponse struct {
@@ -32,6 +31,17 @@ type ChargeListResponse struct {
 	Data []Charge `json:"data"`
 }
 
+// ChargeIter is an Iter over Charges.
+type ChargeIter struct {
+	*Iter
+}
+
+// Charge returns the Charge Next advanced to.
+func (it *ChargeIter) Charge() *Charge {
+	c, _ := it.Current().(*Charge)
+	return c
+}
+
 type ChargeClient struct {
 	client Client
 }
@@ -39,90 +49,161 @@ type ChargeClient struct {
 // Create creates a charge.
 //
 // For more information: https://stripe.com/docs/api#create_charge
-func (c *ChargeClient) Create(params *ChargeParams) (*Charge, error) {
+func (c *ChargeClient) Create(params *ChargeParams, opts ...RequestOption) (*Charge, error) {
+	return c.CreateContext(context.Background(), params, opts...)
+}
+
+// CreateContext is like Create, but uses ctx for the underlying request.
+func (c *ChargeClient) CreateContext(ctx context.Context, params *ChargeParams, opts ...RequestOption) (*Charge, error) {
 	charge := Charge{}
-	values := url.Values{}
-	parseChargeParams(params, &values)
-	err := c.client.post("/charges", values, &charge)
-	return &charge, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.post(ctx, "/charges", values, &charge, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &charge, nil
 }
 
 // Capture captures an existing, uncaptured charge.
 //
 // For more information: https://stripe.com/docs/api#charge_capture
-func (c *ChargeClient) Capture(id string, params *ChargeParams) (*Charge, error) {
+func (c *ChargeClient) Capture(id string, params *ChargeParams, opts ...RequestOption) (*Charge, error) {
+	return c.CaptureContext(context.Background(), id, params, opts...)
+}
+
+// CaptureContext is like Capture, but uses ctx for the underlying request.
+func (c *ChargeClient) CaptureContext(ctx context.Context, id string, params *ChargeParams, opts ...RequestOption) (*Charge, error) {
 	charge := Charge{}
-	values := url.Values{}
-	parseChargeParams(params, &values)
-	err := c.client.post("/charges/"+id+"/capture", values, &charge)
-	return &charge, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.post(ctx, "/charges/"+id+"/capture", values, &charge, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &charge, nil
 }
 
 // Retrieve loads a charge.
 //
 // For more information: https://stripe.com/docs/api#retrieve_charge
-func (c *ChargeClient) Retrieve(id string) (*Charge, error) {
+func (c *ChargeClient) Retrieve(id string, opts ...RequestOption) (*Charge, error) {
+	return c.RetrieveContext(context.Background(), id, opts...)
+}
+
+// RetrieveContext is like Retrieve, but uses ctx for the underlying request.
+func (c *ChargeClient) RetrieveContext(ctx context.Context, id string, opts ...RequestOption) (*Charge, error) {
 	charge := Charge{}
-	err := c.client.get("/charges/"+id, nil, &charge)
-	return &charge, err
+	err := c.client.get(ctx, "/charges/"+id, nil, &charge, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &charge, nil
 }
 
 // Update updates a charge.
 //
 // For more information: https://stripe.com/docs/api#update_charge
-func (c *ChargeClient) Update(id string, params *ChargeParams) (*Charge, error) {
+func (c *ChargeClient) Update(id string, params *ChargeParams, opts ...RequestOption) (*Charge, error) {
+	return c.UpdateContext(context.Background(), id, params, opts...)
+}
+
+// UpdateContext is like Update, but uses ctx for the underlying request.
+func (c *ChargeClient) UpdateContext(ctx context.Context, id string, params *ChargeParams, opts ...RequestOption) (*Charge, error) {
 	charge := Charge{}
-	values := url.Values{}
-	parseChargeParams(params, &values)
-	err := c.client.post("/charges/"+id, values, &charge)
-	return &charge, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.post(ctx, "/charges/"+id, values, &charge, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &charge, nil
 }
 
-// All lists the first 10 charges. It calls AllWithFilters with a blank Filters
+// All lists the first 10 charges. It calls AllWithParams with no params
 // so all defaults are used.
 //
 // For more information: https://stripe.com/docs/api#list_charges
-func (c *ChargeClient) All() (*ChargeListResponse, error) {
-	return c.AllWithFilters(Filters{})
+func (c *ChargeClient) All(opts ...RequestOption) (*ChargeListResponse, error) {
+	return c.AllContext(context.Background(), opts...)
 }
 
-// AllWithFilters takes a Filters and applies all valid filters for the action.
+// AllContext is like All, but uses ctx for the underlying request.
+func (c *ChargeClient) AllContext(ctx context.Context, opts ...RequestOption) (*ChargeListResponse, error) {
+	return c.AllWithParamsContext(ctx, nil, opts...)
+}
+
+// AllWithParams lists the Charges matching params.
 //
 // For more information: https://stripe.com/docs/api#list_charges
-func (c *ChargeClient) AllWithFilters(filters Filters) (*ChargeListResponse, error) {
+func (c *ChargeClient) AllWithParams(params *ChargeListParams, opts ...RequestOption) (*ChargeListResponse, error) {
+	return c.AllWithParamsContext(context.Background(), params, opts...)
+}
+
+// AllWithParamsContext is like AllWithParams, but uses ctx for the
+// underlying request.
+func (c *ChargeClient) AllWithParamsContext(ctx context.Context, params *ChargeListParams, opts ...RequestOption) (*ChargeListResponse, error) {
 	response := ChargeListResponse{}
-	values := url.Values{}
-	addFiltersToValues([]string{"count", "offset", "customer"}, filters, &values)
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.client.get(ctx, "/charges", values, &response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Iter returns an iterator over the Charges matching params, fetching pages
+// as they are needed.
+//
+// For more information: https://stripe.com/docs/api#pagination
+func (c *ChargeClient) Iter(params *ChargeListParams, opts ...RequestOption) *ChargeIter {
+	return c.IterContext(context.Background(), params, opts...)
+}
 
-	err := c.client.get("/charges", values, &response)
-	return &response, err
+// IterContext is like Iter, but uses ctx for the underlying requests.
+func (c *ChargeClient) IterContext(ctx context.Context, params *ChargeListParams, opts ...RequestOption) *ChargeIter {
+	p := ChargeListParams{}
+	if params != nil {
+		p = *params
+	}
+
+	return &ChargeIter{newIter(ctx, p.ListParams, func(ctx context.Context, page ListParams) ([]interface{}, ListResponse, error) {
+		p.ListParams = page
+		list, err := c.AllWithParamsContext(ctx, &p, opts...)
+		if err != nil {
+			return nil, ListResponse{}, err
+		}
+		return pageOf(list.Data), list.ListResponse, nil
+	})}
 }
 
 // Refund refunds a charge.
 //
 // For more information: https://stripe.com/docs/api#refund_charge
-func (c *ChargeClient) Refund(id string, params *RefundParams) (*Charge, error) {
-	charge := Charge{}
-	values := url.Values{}
-	addParamsToValues(params, &values)
-	err := c.client.post("/charges/"+id+"/refund", values, &charge)
-	return &charge, err
+func (c *ChargeClient) Refund(id string, params *RefundParams, opts ...RequestOption) (*Charge, error) {
+	return c.RefundContext(context.Background(), id, params, opts...)
 }
 
-// parseChargeParams takes a pointer to a ChargeParams and a pointer to a
-// url.Values. It iterates over everything in the ChargeParams struct and Adds
-// what is there to the url.Values.
-func parseChargeParams(params *ChargeParams, values *url.Values) {
-
-	// Use parseCardParams from cards.go to setup the card param
-	if params.CardParams != nil {
-		parseCardParams(params.CardParams, values, true)
+// RefundContext is like Refund, but uses ctx for the underlying request.
+func (c *ChargeClient) RefundContext(ctx context.Context, id string, params *RefundParams, opts ...RequestOption) (*Charge, error) {
+	charge := Charge{}
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
 	}
-
-	// Use parseMetaData from metadata.go to setup the metadata param
-	if params.Metadata != nil {
-		parseMetadata(params.Metadata, values)
+	err = c.client.post(ctx, "/charges/"+id+"/refund", values, &charge, opts...)
+	if err != nil {
+		return nil, err
 	}
-
-	addParamsToValues(params, values)
+	return &charge, nil
 }
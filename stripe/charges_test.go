@@ -1,8 +1,8 @@
 package stripe
 
 import (
+	"context"
 	"github.com/bmizerany/assert"
-	"net/url"
 	"strconv"
 	"testing"
 )
@@ -32,6 +32,19 @@ func TestChargesRetrieve(t *testing.T) {
 	assert.Equal(t, charge.Id, "ch_123456789")
 }
 
+func TestChargesRetrieveContext(t *testing.T) {
+	setup()
+	defer teardown()
+	handleWithJSON("/charges/ch_123456789", "charges/charge.json")
+	charge, _ := client.Charges.RetrieveContext(context.Background(), "ch_123456789")
+	assert.Equal(t, charge.Id, "ch_123456789")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := client.Charges.RetrieveContext(ctx, "ch_123456789")
+	assert.Equal(t, err, context.Canceled)
+}
+
 func TestChargesUpdate(t *testing.T) {
 	setup()
 	defer teardown()
@@ -49,11 +62,11 @@ func TestChargesAll(t *testing.T) {
 	assert.Equal(t, charges.Data[0].Id, "ch_123456789")
 }
 
-func TestChargesAllWithFilters(t *testing.T) {
+func TestChargesAllWithParams(t *testing.T) {
 	setup()
 	defer teardown()
 	handleWithJSON("/charges", "charges/charges.json")
-	charges, _ := client.Charges.AllWithFilters(Filters{})
+	charges, _ := client.Charges.AllWithParams(&ChargeListParams{})
 	assert.Equal(t, charges.Count, 1)
 	assert.Equal(t, charges.Data[0].Id, "ch_123456789")
 }
@@ -66,29 +79,28 @@ func TestChargesRefund(t *testing.T) {
 	assert.Equal(t, charge.Id, "ch_123456789")
 }
 
-func TestParseChargeParams(t *testing.T) {
+func TestEncodeChargeParams(t *testing.T) {
 	params := ChargeParams{
-		Amount:         2500,
-		Currency:       "USD",
-		Customer:       "cus_123456789",
-		Description:    "Charge",
-		DisableCapture: true,
-		ApplicationFee: 100,
+		Amount:         Int(2500),
+		Currency:       String("USD"),
+		Customer:       String("cus_123456789"),
+		Description:    String("Charge"),
+		DisableCapture: Bool(true),
+		ApplicationFee: Int(100),
 		CardParams: &CardParams{
-			Number: "4242424242424242",
+			Number: String("4242424242424242"),
 		},
 		Metadata: Metadata{
 			"foo": "bar",
 		},
 	}
-	values := url.Values{}
-	parseChargeParams(&params, &values)
-	assert.Equal(t, values.Get("amount"), strconv.Itoa(params.Amount))
-	assert.Equal(t, values.Get("currency"), params.Currency)
-	assert.Equal(t, values.Get("customer"), params.Customer)
-	assert.Equal(t, values.Get("description"), params.Description)
+	values, _ := encodeParams(&params)
+	assert.Equal(t, values.Get("amount"), strconv.Itoa(*params.Amount))
+	assert.Equal(t, values.Get("currency"), *params.Currency)
+	assert.Equal(t, values.Get("customer"), *params.Customer)
+	assert.Equal(t, values.Get("description"), *params.Description)
 	assert.Equal(t, values.Get("capture"), "false")
-	assert.Equal(t, values.Get("application_fee"), strconv.Itoa(params.ApplicationFee))
-	assert.Equal(t, values.Get("card[number]"), params.CardParams.Number)
+	assert.Equal(t, values.Get("application_fee"), strconv.Itoa(*params.ApplicationFee))
+	assert.Equal(t, values.Get("card[number]"), *params.CardParams.Number)
 	assert.Equal(t, values.Get("metadata[foo]"), params.Metadata["foo"])
 }
@@ -1,6 +1,7 @@
 package stripe
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"io/ioutil"
@@ -15,6 +16,10 @@ type Client struct {
 	apiUrl          string
 	apiVersion      string
 	userAgent       string
+	retryPolicy     RetryPolicy
+	autoIdempotency bool
+	interceptors    []Interceptor
+	stripeAccount   string
 	Account         *AccountClient
 	ApplicationFees *ApplicationFeeClient
 	Balance         *BalanceClient
@@ -34,13 +39,55 @@ type Client struct {
 	Transfers       *TransferClient
 }
 
+// ClientOption configures optional behaviour of a Client. ClientOptions are
+// passed to NewClient or NewClientWith.
+type ClientOption func(*Client)
+
+// WithRetryPolicy sets the RetryPolicy used for every request made by the
+// Client. Without it, each request is attempted exactly once.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+// WithAutoIdempotencyKeys makes the Client generate an Idempotency-Key for
+// every POST that was not given one through the IdempotencyKey RequestOption.
+// The same key is sent on every retry of that POST, so retrying after a
+// network failure can never perform the action twice.
+func WithAutoIdempotencyKeys() ClientOption {
+	return func(c *Client) {
+		c.autoIdempotency = true
+	}
+}
+
+// WithAPIVersion pins the Client to the given API version, instead of the one
+// this library was written against. It can be overridden for a single request
+// with the APIVersion RequestOption.
+//
+// For more information: https://stripe.com/docs/api#versioning
+func WithAPIVersion(version string) ClientOption {
+	return func(c *Client) {
+		c.apiVersion = version
+	}
+}
+
+// WithInterceptors adds interceptors to the chain every request goes through.
+// They run in the order they are registered, after the built-in headers have
+// been set. See Interceptor.
+func WithInterceptors(interceptors ...Interceptor) ClientOption {
+	return func(c *Client) {
+		c.interceptors = append(c.interceptors, interceptors...)
+	}
+}
+
 // NewClient returns a Client and sets the apiUrl to the live apiUrl.
-func NewClient(client *http.Client, apiKey string) Client {
-	return NewClientWith(client, apiUrl, apiKey)
+func NewClient(client *http.Client, apiKey string, opts ...ClientOption) Client {
+	return NewClientWith(client, apiUrl, apiKey, opts...)
 }
 
 // NewClientWith returns a Client and allows us to access the resource clients.
-func NewClientWith(client *http.Client, apiUrl, apiKey string) Client {
+func NewClientWith(client *http.Client, apiUrl, apiKey string, opts ...ClientOption) Client {
 	c := Client{
 		apiKey:     apiKey,
 		apiUrl:     apiUrl,
@@ -54,86 +101,245 @@ func NewClientWith(client *http.Client, apiUrl, apiKey string) Client {
 		c.client = client
 	}
 
-	c.Account = &AccountClient{client: c}
-	c.ApplicationFees = &ApplicationFeeClient{client: c}
-	c.Balance = &BalanceClient{client: c}
-	c.Cards = &CardClient{client: c}
-	c.Charges = &ChargeClient{client: c}
-	c.Coupons = &CouponClient{client: c}
-	c.Customers = &CustomerClient{client: c}
-	c.Discounts = &DiscountClient{client: c}
-	c.Disputes = &DisputeClient{client: c}
-	c.Events = &EventClient{client: c}
-	c.Invoices = &InvoiceClient{client: c}
-	c.InvoiceItems = &InvoiceItemClient{client: c}
-	c.Plans = &PlanClient{client: c}
-	c.Recipients = &RecipientClient{client: c}
-	c.Subscriptions = &SubscriptionClient{client: c}
-	c.Tokens = &TokenClient{client: c}
-	c.Transfers = &TransferClient{client: c}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	c.setResourceClients()
 
 	return c
 }
 
+// ForAccount returns a copy of the Client that acts on behalf of the connected
+// account with the given id. Every request made by its resource clients sends
+// the id as the Stripe-Account header, unless overridden by the StripeAccount
+// RequestOption.
+//
+// For more information: https://stripe.com/docs/connect/authentication
+func (c *Client) ForAccount(id string) Client {
+	account := *c
+	account.stripeAccount = id
+	account.setResourceClients()
+	return account
+}
+
+// setResourceClients points each resource client at a copy of the Client, so
+// they make requests with its settings.
+func (c *Client) setResourceClients() {
+	c.Account = &AccountClient{client: *c}
+	c.ApplicationFees = &ApplicationFeeClient{client: *c}
+	c.Balance = &BalanceClient{client: *c}
+	c.Cards = &CardClient{client: *c}
+	c.Charges = &ChargeClient{client: *c}
+	c.Coupons = &CouponClient{client: *c}
+	c.Customers = &CustomerClient{client: *c}
+	c.Discounts = &DiscountClient{client: *c}
+	c.Disputes = &DisputeClient{client: *c}
+	c.Events = &EventClient{client: *c}
+	c.Invoices = &InvoiceClient{client: *c}
+	c.InvoiceItems = &InvoiceItemClient{client: *c}
+	c.Plans = &PlanClient{client: *c}
+	c.Recipients = &RecipientClient{client: *c}
+	c.Subscriptions = &SubscriptionClient{client: *c}
+	c.Tokens = &TokenClient{client: *c}
+	c.Transfers = &TransferClient{client: *c}
+}
+
 // get is a shortcut to the underlying request, which sends an HTTP GET.
-func (c *Client) get(path string, params url.Values, v interface{}) error {
-	return c.request("GET", path, params, v)
+func (c *Client) get(ctx context.Context, path string, params url.Values, v interface{}, opts ...RequestOption) error {
+	return c.request(ctx, "GET", path, params, v, opts...)
 }
 
 // post is a shortcut to the underlying request, which sends an HTTP POST.
-func (c *Client) post(path string, params url.Values, v interface{}) error {
-	return c.request("POST", path, params, v)
+func (c *Client) post(ctx context.Context, path string, params url.Values, v interface{}, opts ...RequestOption) error {
+	return c.request(ctx, "POST", path, params, v, opts...)
 }
 
-func (c *Client) delete(path string, params url.Values, v interface{}) error {
-	return c.request("DELETE", path, params, v)
+// delete is a shortcut to the underlying request, which sends an HTTP DELETE.
+func (c *Client) delete(ctx context.Context, path string, params url.Values, v interface{}, opts ...RequestOption) error {
+	return c.request(ctx, "DELETE", path, params, v, opts...)
 }
 
-// request is the method that actually delivers the HTTP Requests.
-func (c *Client) request(method, path string, params url.Values, v interface{}) error {
+// request is the method that actually delivers the HTTP Requests. The request
+// is bound to ctx, so cancelling ctx or letting its deadline pass aborts the
+// call. In that case the error returned is ctx.Err() (context.Canceled or
+// context.DeadlineExceeded) rather than a transport or API error.
+//
+// Failed attempts are retried according to the Client's RetryPolicy. opts
+// apply to every attempt.
+func (c *Client) request(ctx context.Context, method, path string, params url.Values, v interface{}, opts ...RequestOption) error {
+	o := newRequestOptions(opts)
+
+	// Generate the Idempotency-Key up front so every attempt shares it.
+	if o.idempotencyKey == "" && c.autoIdempotency && method == "POST" {
+		key, err := newIdempotencyKey()
+		if err != nil {
+			return err
+		}
+		o.idempotencyKey = key
+	}
+
+	// Add the expand[] params, without changing those of the caller.
+	if len(o.expand) > 0 {
+		expanded := url.Values{}
+		for k, v := range params {
+			expanded[k] = v
+		}
+		for _, field := range o.expand {
+			expanded.Add("expand[]", field)
+		}
+		params = expanded
+	}
 
-	// Parse the URL, path, User, etc.
+	// Parse the URL, path, etc. The API key is sent in the Authorization
+	// header, never in the URL, which ends up in *url.Error messages.
 	u, err := url.Parse(c.apiUrl + path)
 	if err != nil {
 		return err
 	}
 
-	// Much Authentication!
-	u.User = url.User(c.apiKey)
+	// Every attempt goes through the built-in headers, then the Client's
+	// Interceptors, then the http.Client.
+	do := chain(append([]Interceptor{c.headers(o)}, c.interceptors...), c.transport)
 
-	// Build HTTP Request.
-	bodyReader := parseParams(method, params, u)
-	req, err := http.NewRequest(method, u.String(), bodyReader)
-	if err != nil {
-		return err
+	var res *http.Response
+	var body []byte
+
+	for attempt := 1; ; attempt++ {
+
+		// Build HTTP Request. It is rebuilt for every attempt, as the body of
+		// the previous one has already been consumed.
+		bodyReader := parseParams(method, params, u)
+		req, err := http.NewRequestWithContext(ctx, method, u.String(), bodyReader)
+		if err != nil {
+			return err
+		}
+
+		res, body, err = c.send(ctx, do, req)
+
+		delay, retry := c.retryPolicy.retry(attempt, req, res, err)
+		if !retry {
+			if err != nil {
+				return err
+			}
+			break
+		}
+
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+
+	// If the API didn't return a 200, parse the error and return it.
+	if res.StatusCode != 200 {
+		return newAPIError(res, body)
 	}
 
-	// Pin API Version, simplify maintenance.
-	req.Header.Set("Stripe-Version", c.apiVersion)
-	req.Header.Set("User-Agent", c.userAgent)
+	// Parse the body, store it in v.
+	if err := json.Unmarshal(body, v); err != nil {
+		return newDecodeError(res, body, v, err)
+	}
+
+	// Record the version that produced the response.
+	if resource, ok := v.(interface{ setAPIVersion(string) }); ok {
+		version := res.Header.Get("Stripe-Version")
+		if version == "" {
+			version = c.version(o)
+		}
+		resource.setAPIVersion(version)
+	}
+
+	return nil
+}
+
+// send delivers a single HTTP Request through do and reads the whole response
+// body. If ctx is done by the time the request fails, ctx.Err() is returned.
+func (c *Client) send(ctx context.Context, do Doer, req *http.Request) (*http.Response, []byte, error) {
 
 	// Send HTTP Request.
-	res, err := c.client.Do(req)
+	res, err := do(req)
 	if err != nil {
-		return err
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, nil, ctxErr
+		}
+		return nil, nil, err
+	}
+	if res == nil {
+		return nil, nil, errNoResponse
+	}
+
+	// A short-circuiting Interceptor may not have set a body.
+	if res.Body == nil {
+		return res, nil, nil
 	}
 
 	// Read response.
 	body, err := ioutil.ReadAll(res.Body)
 	defer res.Body.Close()
 	if err != nil {
-		return err
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, nil, ctxErr
+		}
+		return nil, nil, &ConnectionError{Err: err}
 	}
 
-	// If the API didn't return a 200, parse the error and return it.
-	if res.StatusCode != 200 {
-		err := ErrorResponse{}
-		json.Unmarshal(body, &err)
-		return &err
+	return res, body, nil
+}
+
+// transport is the Doer at the end of every chain. It sends req with the
+// http.Client, wrapping any failure in a ConnectionError.
+func (c *Client) transport(req *http.Request) (*http.Response, error) {
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, &ConnectionError{Err: err}
+	}
+	return res, nil
+}
+
+// headers returns the built-in Interceptor, which sets the headers every
+// request carries, as well as those asked for by the RequestOptions in o.
+func (c *Client) headers(o *requestOptions) Interceptor {
+	return func(req *http.Request, next Doer) (*http.Response, error) {
+
+		// Much Authentication!
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+		// Pin API Version, simplify maintenance.
+		req.Header.Set("Stripe-Version", c.version(o))
+		req.Header.Set("User-Agent", c.userAgent)
+
+		if req.Body != nil {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+
+		if o.idempotencyKey != "" {
+			req.Header.Set("Idempotency-Key", o.idempotencyKey)
+		}
+
+		if account := c.account(o); account != "" {
+			req.Header.Set("Stripe-Account", account)
+		}
+
+		return next(req)
+	}
+}
+
+// version returns the API version of a request: the one set through the
+// APIVersion RequestOption if any, or the Client's.
+func (c *Client) version(o *requestOptions) string {
+	if o.apiVersion != "" {
+		return o.apiVersion
 	}
+	return c.apiVersion
+}
 
-	// Parse the body, store it in v, return the result of Unmarshal.
-	return json.Unmarshal(body, v)
+// account returns the connected account a request acts on behalf of: the one
+// set through the StripeAccount RequestOption if any, or the Client's.
+func (c *Client) account(o *requestOptions) string {
+	if o.stripeAccount != "" {
+		return o.stripeAccount
+	}
+	return c.stripeAccount
 }
 
 // parseParams takes a method, url.Values and a pointer to a url.URL. If the
@@ -1,6 +1,8 @@
 package stripe
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/bmizerany/assert"
 	"io/ioutil"
@@ -9,6 +11,7 @@ import (
 	"net/url"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestNewClient(t *testing.T) {
@@ -41,6 +44,15 @@ func TestNewClientWith(t *testing.T) {
 	assert.Equal(t, client.client, c)
 }
 
+func TestNewClientWithOptions(t *testing.T) {
+	client := NewClient(nil, "abc123")
+	assert.Equal(t, client.retryPolicy, RetryPolicy{})
+
+	client = NewClientWith(nil, "http://foo.bar", "token", WithRetryPolicy(DefaultRetryPolicy))
+	assert.Equal(t, client.retryPolicy, DefaultRetryPolicy)
+	assert.Equal(t, client.Charges.client.retryPolicy, DefaultRetryPolicy)
+}
+
 func TestResourceClients(t *testing.T) {
 	client := NewClient(nil, "abc123")
 	assert.Equal(t, reflect.TypeOf(*client.Account).Name(), "AccountClient")
@@ -72,7 +84,7 @@ func TestGet(t *testing.T) {
 	})
 
 	var response struct{ Foo string }
-	client.get("/get", nil, &response)
+	client.get(context.Background(), "/get", nil, &response)
 	assert.Equal(t, response.Foo, "bar")
 }
 
@@ -86,7 +98,7 @@ func TestPost(t *testing.T) {
 	})
 
 	var response struct{ Foo string }
-	client.post("/post", nil, &response)
+	client.post(context.Background(), "/post", nil, &response)
 	assert.Equal(t, response.Foo, "bar")
 }
 
@@ -100,7 +112,7 @@ func TestDelete(t *testing.T) {
 	})
 
 	var response struct{ Foo string }
-	client.delete("/delete", nil, &response)
+	client.delete(context.Background(), "/delete", nil, &response)
 	assert.Equal(t, response.Foo, "bar")
 }
 
@@ -110,6 +122,10 @@ func TestRequest(t *testing.T) {
 
 	serveMux.HandleFunc("/get", func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, r.Header.Get("Authorization"), "Bearer sk_abc123")
+		assert.Equal(t, r.URL.User, (*url.Userinfo)(nil))
+		_, _, hasBasicAuth := r.BasicAuth()
+		assert.T(t, !hasBasicAuth)
 		assert.Equal(t, r.Header.Get("Stripe-Version"), apiVersion)
 		assert.Equal(t, r.Header.Get("User-Agent"), userAgent)
 		fmt.Fprint(w, loadFixture("sample.json"))
@@ -122,12 +138,40 @@ func TestRequest(t *testing.T) {
 
 	// Success
 	var response struct{ Foo string }
-	client.request("GET", "/get", nil, &response)
+	client.request(context.Background(), "GET", "/get", nil, &response)
 	assert.Equal(t, response.Foo, "bar")
 
 	// Error
-	err := client.request("POST", "/error", nil, nil)
-	assert.Equal(t, err.Error(), "An error occurred.")
+	err := client.request(context.Background(), "POST", "/error", nil, nil)
+	assert.Equal(t, err.Error(), "stripe: invalid_request_error: An error occurred. (status 400)")
+}
+
+func TestRequestContextCanceled(t *testing.T) {
+	setup()
+	defer teardown()
+
+	release := make(chan struct{})
+	defer close(release)
+	serveMux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+
+	// Deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := client.request(ctx, "GET", "/slow", nil, nil)
+	assert.T(t, errors.Is(err, context.DeadlineExceeded))
+
+	// Cancellation
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	err = client.request(ctx, "GET", "/slow", nil, nil)
+	assert.T(t, errors.Is(err, context.Canceled))
+	_, isAPIError := err.(*ErrorResponse)
+	assert.T(t, !isAPIError)
 }
 
 func TestParseParamsGET(t *testing.T) {
@@ -148,3 +192,59 @@ func TestParseParamsPOST(t *testing.T) {
 	assert.Equal(t, u.RawQuery, "")
 	assert.Equal(t, string(body), "foo=bar")
 }
+
+func TestForAccount(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var accounts []string
+	record := func(w http.ResponseWriter, r *http.Request) {
+		accounts = append(accounts, r.Header.Get("Stripe-Account"))
+		fmt.Fprint(w, "{}")
+	}
+	serveMux.HandleFunc("/charges", record)
+	serveMux.HandleFunc("/customers", record)
+
+	connected := client.ForAccount("acct_123456789")
+	assert.Equal(t, connected.apiKey, client.apiKey)
+
+	connected.Charges.Create(new(ChargeParams))
+	connected.Customers.All()
+	connected.Charges.Create(new(ChargeParams), StripeAccount("acct_987654321"))
+	client.Charges.Create(new(ChargeParams))
+	assert.Equal(t, accounts, []string{"acct_123456789", "acct_123456789", "acct_987654321", ""})
+}
+
+func TestAPIVersion(t *testing.T) {
+	setup()
+	defer teardown()
+	serveMux.HandleFunc("/charges/ch_123456789", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, loadFixture("charges/charge.json"))
+	})
+	serveMux.HandleFunc("/customers/cus_123456789", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Stripe-Version", r.Header.Get("Stripe-Version"))
+		fmt.Fprint(w, loadFixture("customers/customer.json"))
+	})
+	serveMux.HandleFunc("/customers", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Stripe-Version", r.Header.Get("Stripe-Version"))
+		fmt.Fprint(w, loadFixture("customers/customers.json"))
+	})
+
+	// Default
+	charge, _ := client.Charges.Retrieve("ch_123456789")
+	assert.Equal(t, charge.APIVersion, apiVersion)
+
+	// Per client
+	client = NewClientWith(nil, server.URL, "sk_abc123", WithAPIVersion("2014-05-19"))
+	assert.Equal(t, client.apiVersion, "2014-05-19")
+	customer, _ := client.Customers.Retrieve("cus_123456789")
+	assert.Equal(t, customer.APIVersion, "2014-05-19")
+
+	// Per request
+	customer, _ = client.Customers.Retrieve("cus_123456789", APIVersion("2014-06-13"))
+	assert.Equal(t, customer.APIVersion, "2014-06-13")
+
+	// Lists
+	customers, _ := client.Customers.All()
+	assert.Equal(t, customers.APIVersion, "2014-05-19")
+}
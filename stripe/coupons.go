@@ -1,10 +1,9 @@
 package stripe
 
-import (
-	"net/url"
-)
+import "context"
 
 type Coupon struct {
+	APIResource
 	Id               string `json:"id"`
 	Object           string `json:"object"`
 	Livemode         bool   `json:"livemode"`
@@ -24,6 +23,17 @@ type CouponListResponse struct {
 	Data []Coupon `json:"data"`
 }
 
+// CouponIter is an Iter over Coupons.
+type CouponIter struct {
+	*Iter
+}
+
+// Coupon returns the Coupon Next advanced to.
+func (it *CouponIter) Coupon() *Coupon {
+	c, _ := it.Current().(*Coupon)
+	return c
+}
+
 type CouponClient struct {
 	client Client
 }
@@ -31,54 +41,114 @@ type CouponClient struct {
 // Create creates a coupon.
 //
 // For more information: https://stripe.com/docs/api#create_coupon
-func (c *CouponClient) Create(params *CouponParams) (*Coupon, error) {
+func (c *CouponClient) Create(params *CouponParams, opts ...RequestOption) (*Coupon, error) {
+	return c.CreateContext(context.Background(), params, opts...)
+}
+
+// CreateContext is like Create, but uses ctx for the underlying request.
+func (c *CouponClient) CreateContext(ctx context.Context, params *CouponParams, opts ...RequestOption) (*Coupon, error) {
 	coupon := Coupon{}
-	values := url.Values{}
-	parseCouponParams(params, &values)
-	err := c.client.post("/coupons", values, &coupon)
-	return &coupon, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.post(ctx, "/coupons", values, &coupon, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &coupon, nil
 }
 
 // Retrieve loads a coupon.
 //
 // For more information: https://stripe.com/docs/api#retrieve_coupon
-func (c *CouponClient) Retrieve(id string) (*Coupon, error) {
+func (c *CouponClient) Retrieve(id string, opts ...RequestOption) (*Coupon, error) {
+	return c.RetrieveContext(context.Background(), id, opts...)
+}
+
+// RetrieveContext is like Retrieve, but uses ctx for the underlying request.
+func (c *CouponClient) RetrieveContext(ctx context.Context, id string, opts ...RequestOption) (*Coupon, error) {
 	coupon := Coupon{}
-	err := c.client.get("/coupons/"+id, nil, &coupon)
-	return &coupon, err
+	err := c.client.get(ctx, "/coupons/"+id, nil, &coupon, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &coupon, nil
 }
 
 // Delete deletes a coupon.
 //
 // For more information: https://stripe.com/docs/api#delete_coupon
-func (c *CouponClient) Delete(id string) (*DeleteResponse, error) {
+func (c *CouponClient) Delete(id string, opts ...RequestOption) (*DeleteResponse, error) {
+	return c.DeleteContext(context.Background(), id, opts...)
+}
+
+// DeleteContext is like Delete, but uses ctx for the underlying request.
+func (c *CouponClient) DeleteContext(ctx context.Context, id string, opts ...RequestOption) (*DeleteResponse, error) {
 	response := DeleteResponse{}
-	err := c.client.delete("/coupons/"+id, nil, &response)
-	return &response, err
+	err := c.client.delete(ctx, "/coupons/"+id, nil, &response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
 }
 
-// All lists the first 10 coupons. It calls AllWithFilters with a blank Filters
+// All lists the first 10 coupons. It calls AllWithParams with no params
 // so all defaults are used.
 //
 // For more information: https://stripe.com/docs/api#list_coupons
-func (c *CouponClient) All() (*CouponListResponse, error) {
-	return c.AllWithFilters(Filters{})
+func (c *CouponClient) All(opts ...RequestOption) (*CouponListResponse, error) {
+	return c.AllContext(context.Background(), opts...)
+}
+
+// AllContext is like All, but uses ctx for the underlying request.
+func (c *CouponClient) AllContext(ctx context.Context, opts ...RequestOption) (*CouponListResponse, error) {
+	return c.AllWithParamsContext(ctx, nil, opts...)
 }
 
-// AllWithFilters takes a Filters and applies all valid filters for the action.
+// AllWithParams lists the Coupons matching params.
 //
 // For more information: https://stripe.com/docs/api#list_coupons
-func (c *CouponClient) AllWithFilters(filters Filters) (*CouponListResponse, error) {
+func (c *CouponClient) AllWithParams(params *CouponListParams, opts ...RequestOption) (*CouponListResponse, error) {
+	return c.AllWithParamsContext(context.Background(), params, opts...)
+}
+
+// AllWithParamsContext is like AllWithParams, but uses ctx for the
+// underlying request.
+func (c *CouponClient) AllWithParamsContext(ctx context.Context, params *CouponListParams, opts ...RequestOption) (*CouponListResponse, error) {
 	response := CouponListResponse{}
-	values := url.Values{}
-	addFiltersToValues([]string{"count", "offset"}, filters, &values)
-	err := c.client.get("/coupons", values, &response)
-	return &response, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.get(ctx, "/coupons", values, &response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
 }
 
-// parseCouponParams takes a pointer to a CouponParams and a pointer to a url.Values,
-// it iterates over everything in the CouponParams struct and Adds what is there
-// to the url.Values.
-func parseCouponParams(params *CouponParams, values *url.Values) {
-	addParamsToValues(params, values)
+// Iter returns an iterator over the Coupons matching params, fetching pages
+// as they are needed.
+//
+// For more information: https://stripe.com/docs/api#pagination
+func (c *CouponClient) Iter(params *CouponListParams, opts ...RequestOption) *CouponIter {
+	return c.IterContext(context.Background(), params, opts...)
+}
+
+// IterContext is like Iter, but uses ctx for the underlying requests.
+func (c *CouponClient) IterContext(ctx context.Context, params *CouponListParams, opts ...RequestOption) *CouponIter {
+	p := CouponListParams{}
+	if params != nil {
+		p = *params
+	}
+
+	return &CouponIter{newIter(ctx, p.ListParams, func(ctx context.Context, page ListParams) ([]interface{}, ListResponse, error) {
+		p.ListParams = page
+		list, err := c.AllWithParamsContext(ctx, &p, opts...)
+		if err != nil {
+			return nil, ListResponse{}, err
+		}
+		return pageOf(list.Data), list.ListResponse, nil
+	})}
 }
@@ -2,7 +2,6 @@ package stripe
 
 import (
 	"github.com/bmizerany/assert"
-	"net/url"
 	"strconv"
 	"testing"
 )
@@ -41,34 +40,33 @@ func TestCouponsAll(t *testing.T) {
 	assert.Equal(t, coupons.Data[0].Id, "coupon_code")
 }
 
-func TestCouponsAllWithFilters(t *testing.T) {
+func TestCouponsAllWithParams(t *testing.T) {
 	setup()
 	defer teardown()
 	handleWithJSON("/coupons", "coupons/coupons.json")
-	coupons, _ := client.Coupons.AllWithFilters(Filters{})
+	coupons, _ := client.Coupons.AllWithParams(&CouponListParams{})
 	assert.Equal(t, coupons.Count, 1)
 	assert.Equal(t, coupons.Data[0].Id, "coupon_code")
 }
 
-func TestParseCouponParams(t *testing.T) {
+func TestEncodeCouponParams(t *testing.T) {
 	params := CouponParams{
-		Id:               "coupon_id",
-		Duration:         "once",
-		AmountOff:        1000,
-		Currency:         "USD",
-		DurationInMonths: 1,
-		MaxRedemptions:   10,
-		PercentOff:       20,
-		RedeemBy:         123456789,
+		Id:               String("coupon_id"),
+		Duration:         String("once"),
+		AmountOff:        Int(1000),
+		Currency:         String("USD"),
+		DurationInMonths: Int(1),
+		MaxRedemptions:   Int(10),
+		PercentOff:       Int(20),
+		RedeemBy:         Int(123456789),
 	}
-	values := url.Values{}
-	parseCouponParams(&params, &values)
-	assert.Equal(t, values.Get("id"), params.Id)
-	assert.Equal(t, values.Get("duration"), params.Duration)
-	assert.Equal(t, values.Get("amount_off"), strconv.Itoa(params.AmountOff))
-	assert.Equal(t, values.Get("currency"), params.Currency)
-	assert.Equal(t, values.Get("duration_in_months"), strconv.Itoa(params.DurationInMonths))
-	assert.Equal(t, values.Get("max_redemptions"), strconv.Itoa(params.MaxRedemptions))
-	assert.Equal(t, values.Get("percent_off"), strconv.Itoa(params.PercentOff))
-	assert.Equal(t, values.Get("redeem_by"), strconv.Itoa(params.RedeemBy))
+	values, _ := encodeParams(&params)
+	assert.Equal(t, values.Get("id"), *params.Id)
+	assert.Equal(t, values.Get("duration"), *params.Duration)
+	assert.Equal(t, values.Get("amount_off"), strconv.Itoa(*params.AmountOff))
+	assert.Equal(t, values.Get("currency"), *params.Currency)
+	assert.Equal(t, values.Get("duration_in_months"), strconv.Itoa(*params.DurationInMonths))
+	assert.Equal(t, values.Get("max_redemptions"), strconv.Itoa(*params.MaxRedemptions))
+	assert.Equal(t, values.Get("percent_off"), strconv.Itoa(*params.PercentOff))
+	assert.Equal(t, values.Get("redeem_by"), strconv.Itoa(*params.RedeemBy))
 }
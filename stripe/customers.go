@@ -1,10 +1,9 @@
 package stripe
 
-import (
-	"net/url"
-)
+import "context"
 
 type Customer struct {
+	APIResource
 	Id             string            `json:"id"`
 	Object         string            `json:"object"`
 	Livemode       bool              `json:"livemode"`
@@ -25,6 +24,17 @@ type CustomerListResponse struct {
 	Data []Customer `json:"data"`
 }
 
+// CustomerIter is an Iter over Customers.
+type CustomerIter struct {
+	*Iter
+}
+
+// Customer returns the Customer Next advanced to.
+func (it *CustomerIter) Customer() *Customer {
+	c, _ := it.Current().(*Customer)
+	return c
+}
+
 type CustomerClient struct {
 	client Client
 }
@@ -32,76 +42,135 @@ type CustomerClient struct {
 // Create creates a customer.
 //
 // For more information: https://stripe.com/docs/api#create_customer
-func (c *CustomerClient) Create(params *CustomerParams) (*Customer, error) {
+func (c *CustomerClient) Create(params *CustomerParams, opts ...RequestOption) (*Customer, error) {
+	return c.CreateContext(context.Background(), params, opts...)
+}
+
+// CreateContext is like Create, but uses ctx for the underlying request.
+func (c *CustomerClient) CreateContext(ctx context.Context, params *CustomerParams, opts ...RequestOption) (*Customer, error) {
 	customer := Customer{}
-	values := url.Values{}
-	parseCustomerParams(params, &values)
-	err := c.client.post("/customers", values, &customer)
-	return &customer, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.post(ctx, "/customers", values, &customer, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &customer, nil
 }
 
 // Retrieve loads a customer.
 //
 // For more information: https://stripe.com/docs/api#retrieve_customer
-func (c *CustomerClient) Retrieve(id string) (*Customer, error) {
+func (c *CustomerClient) Retrieve(id string, opts ...RequestOption) (*Customer, error) {
+	return c.RetrieveContext(context.Background(), id, opts...)
+}
+
+// RetrieveContext is like Retrieve, but uses ctx for the underlying request.
+func (c *CustomerClient) RetrieveContext(ctx context.Context, id string, opts ...RequestOption) (*Customer, error) {
 	customer := Customer{}
-	err := c.client.get("/customers/"+id, nil, &customer)
-	return &customer, err
+	err := c.client.get(ctx, "/customers/"+id, nil, &customer, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &customer, nil
 }
 
 // Update updates a customer.
 //
 // For more information: https://stripe.com/docs/api#update_customer
-func (c *CustomerClient) Update(id string, params *CustomerParams) (*Customer, error) {
+func (c *CustomerClient) Update(id string, params *CustomerParams, opts ...RequestOption) (*Customer, error) {
+	return c.UpdateContext(context.Background(), id, params, opts...)
+}
+
+// UpdateContext is like Update, but uses ctx for the underlying request.
+func (c *CustomerClient) UpdateContext(ctx context.Context, id string, params *CustomerParams, opts ...RequestOption) (*Customer, error) {
 	customer := Customer{}
-	values := url.Values{}
-	parseCustomerParams(params, &values)
-	err := c.client.post("/customers/"+id, values, &customer)
-	return &customer, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.post(ctx, "/customers/"+id, values, &customer, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &customer, nil
 }
 
 // Delete deletes a customer.
 //
 // For more information: https://stripe.com/docs/api#delete_customer
-func (c *CustomerClient) Delete(id string) (*DeleteResponse, error) {
+func (c *CustomerClient) Delete(id string, opts ...RequestOption) (*DeleteResponse, error) {
+	return c.DeleteContext(context.Background(), id, opts...)
+}
+
+// DeleteContext is like Delete, but uses ctx for the underlying request.
+func (c *CustomerClient) DeleteContext(ctx context.Context, id string, opts ...RequestOption) (*DeleteResponse, error) {
 	response := DeleteResponse{}
-	err := c.client.delete("/customers/"+id, nil, &response)
-	return &response, err
+	err := c.client.delete(ctx, "/customers/"+id, nil, &response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
 }
 
-// All lists the first 10 customers. It calls AllWithFilters with a blank Filters
-// so all defaults are used.
+// All lists the first 10 customers. It calls AllWithParams with no params so
+// all defaults are used.
 //
 // For more information: https://stripe.com/docs/api#list_customers
-func (c *CustomerClient) All() (*CustomerListResponse, error) {
-	return c.AllWithFilters(Filters{})
+func (c *CustomerClient) All(opts ...RequestOption) (*CustomerListResponse, error) {
+	return c.AllContext(context.Background(), opts...)
 }
 
-// AllWithFilters takes a Filters and applies all valid filters for the action.
+// AllContext is like All, but uses ctx for the underlying request.
+func (c *CustomerClient) AllContext(ctx context.Context, opts ...RequestOption) (*CustomerListResponse, error) {
+	return c.AllWithParamsContext(ctx, nil, opts...)
+}
+
+// AllWithParams lists the Customers matching params.
 //
 // For more information: https://stripe.com/docs/api#list_customers
-func (c *CustomerClient) AllWithFilters(filters Filters) (*CustomerListResponse, error) {
-	response := CustomerListResponse{}
-	values := url.Values{}
-	addFiltersToValues([]string{"count", "offset"}, filters, &values)
-	err := c.client.get("/customers", values, &response)
-	return &response, err
+func (c *CustomerClient) AllWithParams(params *CustomerListParams, opts ...RequestOption) (*CustomerListResponse, error) {
+	return c.AllWithParamsContext(context.Background(), params, opts...)
 }
 
-// parseCustomerParams takes a pointer to a CustomerParams and a pointer to a url.Values,
-// it iterates over everything in the CustomerParams struct and Adds what is there
-// to the url.Values.
-func parseCustomerParams(params *CustomerParams, values *url.Values) {
-
-	// Use parseCardParams from cards.go to setup the card param
-	if params.CardParams != nil {
-		parseCardParams(params.CardParams, values, true)
+// AllWithParamsContext is like AllWithParams, but uses ctx for the
+// underlying request.
+func (c *CustomerClient) AllWithParamsContext(ctx context.Context, params *CustomerListParams, opts ...RequestOption) (*CustomerListResponse, error) {
+	response := CustomerListResponse{}
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
 	}
+	err = c.client.get(ctx, "/customers", values, &response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Iter returns an iterator over the Customers matching params, fetching
+// pages as they are needed.
+//
+// For more information: https://stripe.com/docs/api#pagination
+func (c *CustomerClient) Iter(params *CustomerListParams, opts ...RequestOption) *CustomerIter {
+	return c.IterContext(context.Background(), params, opts...)
+}
 
-	// Use parseMetaData from metadata.go to setup the metadata param
-	if params.Metadata != nil {
-		parseMetadata(params.Metadata, values)
+// IterContext is like Iter, but uses ctx for the underlying requests.
+func (c *CustomerClient) IterContext(ctx context.Context, params *CustomerListParams, opts ...RequestOption) *CustomerIter {
+	p := CustomerListParams{}
+	if params != nil {
+		p = *params
 	}
 
-	addParamsToValues(params, values)
+	return &CustomerIter{newIter(ctx, p.ListParams, func(ctx context.Context, page ListParams) ([]interface{}, ListResponse, error) {
+		p.ListParams = page
+		list, err := c.AllWithParamsContext(ctx, &p, opts...)
+		if err != nil {
+			return nil, ListResponse{}, err
+		}
+		return pageOf(list.Data), list.ListResponse, nil
+	})}
 }
@@ -1,7 +1,9 @@
 package stripe
 
 import (
+	"fmt"
 	"github.com/bmizerany/assert"
+	"net/http"
 	"net/url"
 	"strconv"
 	"testing"
@@ -32,6 +34,28 @@ func TestCustomersUpdate(t *testing.T) {
 	assert.Equal(t, customer.Id, "cus_123456789")
 }
 
+func TestCustomersUpdateZeroValues(t *testing.T) {
+	setup()
+	defer teardown()
+	serveMux.HandleFunc("/customers/cus_123456789", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		assert.Equal(t, r.PostForm, url.Values{
+			"account_balance": {"0"},
+			"description":     {""},
+			"metadata[foo]":   {""},
+		})
+		fmt.Fprint(w, loadFixture("customers/customer.json"))
+	})
+
+	params := &CustomerParams{
+		AccountBalance: Int(0),
+		Description:    String(""),
+		Metadata:       Metadata{"foo": ""},
+	}
+	_, err := client.Customers.Update("cus_123456789", params)
+	assert.Equal(t, err, nil)
+}
+
 func TestCustomersDelete(t *testing.T) {
 	setup()
 	defer teardown()
@@ -49,40 +73,39 @@ func TestCustomersAll(t *testing.T) {
 	assert.Equal(t, customers.Data[0].Id, "cus_123456789")
 }
 
-func TestCustomersAllWithFilters(t *testing.T) {
+func TestCustomersAllWithParams(t *testing.T) {
 	setup()
 	defer teardown()
 	handleWithJSON("/customers", "customers/customers.json")
-	customers, _ := client.Customers.AllWithFilters(Filters{})
+	customers, _ := client.Customers.AllWithParams(&CustomerListParams{})
 	assert.Equal(t, customers.Count, 1)
 	assert.Equal(t, customers.Data[0].Id, "cus_123456789")
 }
 
-func TestParseCustomerParams(t *testing.T) {
+func TestEncodeCustomerParams(t *testing.T) {
 	params := CustomerParams{
-		AccountBalance: 2000,
-		Coupon:         "coupon",
-		Description:    "description",
-		Email:          "apt@stripe.com",
-		Plan:           "plan",
-		Quantity:       1,
-		TrialEnd:       123456789,
+		AccountBalance: Int(2000),
+		Coupon:         String("coupon"),
+		Description:    String("description"),
+		Email:          String("apt@stripe.com"),
+		Plan:           String("plan"),
+		Quantity:       Int(1),
+		TrialEnd:       Int(123456789),
 		CardParams: &CardParams{
-			Number: "4242424242424242",
+			Number: String("4242424242424242"),
 		},
 		Metadata: Metadata{
 			"foo": "bar",
 		},
 	}
-	values := url.Values{}
-	parseCustomerParams(&params, &values)
-	assert.Equal(t, values.Get("account_balance"), strconv.Itoa(params.AccountBalance))
-	assert.Equal(t, values.Get("coupon"), params.Coupon)
-	assert.Equal(t, values.Get("description"), params.Description)
-	assert.Equal(t, values.Get("email"), params.Email)
-	assert.Equal(t, values.Get("plan"), params.Plan)
-	assert.Equal(t, values.Get("quantity"), strconv.Itoa(params.Quantity))
-	assert.Equal(t, values.Get("trial_end"), strconv.Itoa(params.TrialEnd))
-	assert.Equal(t, values.Get("card[number]"), params.CardParams.Number)
+	values, _ := encodeParams(&params)
+	assert.Equal(t, values.Get("account_balance"), strconv.Itoa(*params.AccountBalance))
+	assert.Equal(t, values.Get("coupon"), *params.Coupon)
+	assert.Equal(t, values.Get("description"), *params.Description)
+	assert.Equal(t, values.Get("email"), *params.Email)
+	assert.Equal(t, values.Get("plan"), *params.Plan)
+	assert.Equal(t, values.Get("quantity"), strconv.Itoa(*params.Quantity))
+	assert.Equal(t, values.Get("trial_end"), strconv.Itoa(*params.TrialEnd))
+	assert.Equal(t, values.Get("card[number]"), *params.CardParams.Number)
 	assert.Equal(t, values.Get("metadata[foo]"), params.Metadata["foo"])
 }
@@ -1,5 +1,7 @@
 package stripe
 
+import "context"
+
 type Discount struct {
 	Object   string  `json:"object"`
 	Coupon   *Coupon `json:"coupon"`
@@ -15,8 +17,16 @@ type DiscountClient struct {
 // Delete deletes a customers discount.
 //
 // For more information: https://stripe.com/docs/api#delete_discount
-func (c *DiscountClient) Delete(customerId string) (*DeleteResponse, error) {
+func (c *DiscountClient) Delete(customerId string, opts ...RequestOption) (*DeleteResponse, error) {
+	return c.DeleteContext(context.Background(), customerId, opts...)
+}
+
+// DeleteContext is like Delete, but uses ctx for the underlying request.
+func (c *DiscountClient) DeleteContext(ctx context.Context, customerId string, opts ...RequestOption) (*DeleteResponse, error) {
 	response := DeleteResponse{}
-	err := c.client.delete("/customers/"+customerId+"/discount", nil, &response)
-	return &response, err
+	err := c.client.delete(ctx, "/customers/"+customerId+"/discount", nil, &response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
 }
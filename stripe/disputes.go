@@ -1,8 +1,12 @@
 package stripe
 
-import "net/url"
+import (
+	"context"
+	"net/url"
+)
 
 type Dispute struct {
+	APIResource
 	Object             string `json:"object"`
 	Livemode           bool   `json:"livemode"`
 	Amount             int64  `json:"amount"`
@@ -23,18 +27,34 @@ type DisputeClient struct {
 // Update updates a dispute.
 //
 // For more information: https://stripe.com/docs/api#update_dispute
-func (c *DisputeClient) Update(chargeId, evidence string) (*Dispute, error) {
+func (c *DisputeClient) Update(chargeId, evidence string, opts ...RequestOption) (*Dispute, error) {
+	return c.UpdateContext(context.Background(), chargeId, evidence, opts...)
+}
+
+// UpdateContext is like Update, but uses ctx for the underlying request.
+func (c *DisputeClient) UpdateContext(ctx context.Context, chargeId, evidence string, opts ...RequestOption) (*Dispute, error) {
 	dispute := Dispute{}
 	values := url.Values{"evidence": {evidence}}
-	err := c.client.post("/charges/"+chargeId+"/dispute", values, &dispute)
-	return &dispute, err
+	err := c.client.post(ctx, "/charges/"+chargeId+"/dispute", values, &dispute, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &dispute, nil
 }
 
 // Close closes a dispute.
 //
 // For more information: https://stripe.com/docs/api#close_dispute
-func (c *DisputeClient) Close(chargeId string) (*Dispute, error) {
+func (c *DisputeClient) Close(chargeId string, opts ...RequestOption) (*Dispute, error) {
+	return c.CloseContext(context.Background(), chargeId, opts...)
+}
+
+// CloseContext is like Close, but uses ctx for the underlying request.
+func (c *DisputeClient) CloseContext(ctx context.Context, chargeId string, opts ...RequestOption) (*Dispute, error) {
 	dispute := Dispute{}
-	err := c.client.post("/charges/"+chargeId+"/dispute/close", nil, &dispute)
-	return &dispute, err
+	err := c.client.post(ctx, "/charges/"+chargeId+"/dispute/close", nil, &dispute, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &dispute, nil
 }
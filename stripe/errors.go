@@ -0,0 +1,221 @@
+package stripe
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+)
+
+// maxBodySnippet is how much of an unexpected response body is quoted in error
+// messages.
+const maxBodySnippet = 256
+
+// secretKey matches secret (sk_) and restricted (rk_) API keys, live or test.
+var secretKey = regexp.MustCompile(`\b([sr]k_(?:live_|test_)?)[0-9A-Za-z]+`)
+
+// Redact returns s with every secret or restricted API key in it masked, keeping
+// only its prefix: "sk_live_****". The messages of the errors returned by this
+// package are redacted already; Redact is meant for what Interceptors log:
+//
+//	log.Println(stripe.Redact(req.URL.String()), err)
+func Redact(s string) string {
+	return secretKey.ReplaceAllString(s, "${1}****")
+}
+
+// ErrNotFound matches, through errors.Is, the InvalidRequestError returned when
+// the requested resource does not exist:
+//
+//	charge, err := client.Charges.Retrieve("ch_123456789")
+//	if errors.Is(err, stripe.ErrNotFound) {
+//		// charge is nil
+//	}
+var ErrNotFound = errors.New("stripe: resource not found")
+
+// CardError is returned when a card can't be charged, for instance because it
+// was declined. Err.DeclineCode and Err.Param tell why, and which parameter
+// was at fault.
+type CardError struct{ *ErrorResponse }
+
+// Unwrap returns the underlying ErrorResponse.
+func (e *CardError) Unwrap() error { return e.ErrorResponse }
+
+// InvalidRequestError is returned when a request has invalid parameters, or
+// refers to a resource that does not exist.
+type InvalidRequestError struct{ *ErrorResponse }
+
+// Unwrap returns the underlying ErrorResponse.
+func (e *InvalidRequestError) Unwrap() error { return e.ErrorResponse }
+
+// Is reports whether the error is a 404, when target is ErrNotFound.
+func (e *InvalidRequestError) Is(target error) bool {
+	return target == ErrNotFound && e.HTTPStatusCode == http.StatusNotFound
+}
+
+// AuthenticationError is returned when the API key is missing or invalid.
+type AuthenticationError struct{ *ErrorResponse }
+
+// Unwrap returns the underlying ErrorResponse.
+func (e *AuthenticationError) Unwrap() error { return e.ErrorResponse }
+
+// RateLimitError is returned when too many requests hit the API too quickly.
+type RateLimitError struct{ *ErrorResponse }
+
+// Unwrap returns the underlying ErrorResponse.
+func (e *RateLimitError) Unwrap() error { return e.ErrorResponse }
+
+// APIError is returned for any other error the API reports, typically a
+// problem on Stripe's end (a 5xx).
+type APIError struct{ *ErrorResponse }
+
+// Unwrap returns the underlying ErrorResponse.
+func (e *APIError) Unwrap() error { return e.ErrorResponse }
+
+// ConnectionError is returned when the request could not be delivered to, or
+// the response could not be read from, the API. As no response was received,
+// it has no HTTP status, Request-Id or body.
+type ConnectionError struct {
+	Err error
+}
+
+// ConnectionError must implement an Error() method to satisfy the error
+// interface.
+func (e *ConnectionError) Error() string {
+	return Redact("stripe: connection error: " + e.Err.Error())
+}
+
+// Unwrap returns the underlying network error.
+func (e *ConnectionError) Unwrap() error { return e.Err }
+
+// ParamError is returned, before any request is sent, when params hold a value
+// that can't be sent to the API, such as a float with more decimal places than
+// its field allows, or outside of its range.
+type ParamError struct {
+	Param   string
+	Message string
+}
+
+// ParamError must implement an Error() method to satisfy the error interface.
+func (e *ParamError) Error() string {
+	return Redact("stripe: invalid " + e.Param + ": " + e.Message)
+}
+
+// DecodeError is returned when a successful response can't be decoded into
+// the requested resource, for instance because the API returned a string where
+// a number was expected, or the body was cut short. It is also returned when
+// the resource an Event is about can't be decoded, without an HTTP status or
+// Request-Id then.
+type DecodeError struct {
+	Resource       string
+	Field          string
+	HTTPStatusCode int
+	RequestId      string
+	Body           []byte
+	Err            error
+}
+
+// DecodeError must implement an Error() method to satisfy the error interface.
+func (e *DecodeError) Error() string {
+	msg := "stripe: cannot decode " + e.Resource
+	if e.Field != "" {
+		msg += " field " + strconv.Quote(e.Field)
+	}
+	if e.HTTPStatusCode != 0 {
+		msg += fmt.Sprintf(" (status %d)", e.HTTPStatusCode)
+	}
+	return Redact(fmt.Sprintf("%s: %v: %s", msg, e.Err, snippet(e.Body)))
+}
+
+// Unwrap returns the underlying encoding/json error.
+func (e *DecodeError) Unwrap() error { return e.Err }
+
+// newDecodeError takes the response that couldn't be decoded into v, its body
+// and the error returned by json.Unmarshal, and returns a DecodeError.
+func newDecodeError(res *http.Response, body []byte, v interface{}, err error) error {
+	e := newObjectDecodeError(body, v, err)
+	e.HTTPStatusCode = res.StatusCode
+	e.RequestId = res.Header.Get("Request-Id")
+	return e
+}
+
+// newObjectDecodeError takes JSON that couldn't be decoded into v, outside of
+// a response, and the error returned by json.Unmarshal, and returns a
+// DecodeError.
+func newObjectDecodeError(data []byte, v interface{}, err error) *DecodeError {
+	e := &DecodeError{
+		Resource: resourceName(v),
+		Body:     data,
+		Err:      err,
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		e.Field = typeErr.Field
+	}
+
+	return e
+}
+
+// newAPIError takes a response that isn't a 200 and its body, and returns the
+// typed error matching it. If the body is not a JSON error (an HTML page from
+// a proxy, or a truncated response), the error message describes the body
+// instead.
+func newAPIError(res *http.Response, body []byte) error {
+	e := &ErrorResponse{
+		HTTPStatusCode: res.StatusCode,
+		RequestId:      res.Header.Get("Request-Id"),
+		Body:           body,
+	}
+
+	switch err := json.Unmarshal(body, e); {
+	case len(body) == 0:
+		e.Err.Message = "empty error response"
+	case err != nil:
+		e.Err.Message = fmt.Sprintf("malformed error response (%v): %s", err, snippet(body))
+	case e.Err.Type == "" && e.Err.Message == "":
+		e.Err.Message = "error response without error details: " + snippet(body)
+	}
+
+	switch {
+	case res.StatusCode == http.StatusUnauthorized:
+		return &AuthenticationError{e}
+	case res.StatusCode == http.StatusTooManyRequests:
+		return &RateLimitError{e}
+	case e.Err.Type == "card_error", res.StatusCode == http.StatusPaymentRequired:
+		return &CardError{e}
+	case e.Err.Type == "invalid_request_error", res.StatusCode == http.StatusBadRequest, res.StatusCode == http.StatusNotFound:
+		return &InvalidRequestError{e}
+	default:
+		return &APIError{e}
+	}
+}
+
+// snippet quotes body for use in an error message, truncated to
+// maxBodySnippet bytes.
+func snippet(body []byte) string {
+	if len(body) <= maxBodySnippet {
+		return strconv.Quote(string(body))
+	}
+	return strconv.Quote(string(body[:maxBodySnippet])) + "..."
+}
+
+// resourceName returns the name of the type v points to, "Charge" for a
+// *Charge.
+func resourceName(v interface{}) string {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return "<nil>"
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Name() == "" {
+		return t.String()
+	}
+	return t.Name()
+}
@@ -0,0 +1,278 @@
+package stripe
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// DefaultPollInterval is how long Tail waits between polls, unless set with
+// PollInterval.
+const DefaultPollInterval = 10 * time.Second
+
+// EventCheckpoint is how far Tail has delivered Events: every Event created up
+// to Created, a unix timestamp, and those created during that second whose Ids
+// are listed. The Ids tell apart, within the same second, the Events that were
+// delivered from those the API only listed later.
+type EventCheckpoint struct {
+	Created int64    `json:"created"`
+	Ids     []string `json:"ids"`
+}
+
+// delivered reports whether the Event, at the position given by its created
+// time and id, was delivered.
+func (c *EventCheckpoint) delivered(created int64, id string) bool {
+	if created != c.Created {
+		return created < c.Created
+	}
+	for _, seen := range c.Ids {
+		if seen == id {
+			return true
+		}
+	}
+	return false
+}
+
+// advance moves the checkpoint past the given Event.
+func (c *EventCheckpoint) advance(created int64, id string) {
+	if created > c.Created {
+		c.Created, c.Ids = created, nil
+	}
+	c.Ids = append(c.Ids, id)
+}
+
+// CheckpointStore keeps the EventCheckpoint of Tail, so that it resumes where
+// it stopped when it is started again.
+type CheckpointStore interface {
+	// Load returns the saved EventCheckpoint, or the zero EventCheckpoint if
+	// none was saved, to start from the oldest Event the API returns.
+	Load() (EventCheckpoint, error)
+
+	// Save replaces the saved EventCheckpoint.
+	Save(checkpoint EventCheckpoint) error
+}
+
+// FileCheckpoint is a CheckpointStore that keeps the EventCheckpoint, as JSON,
+// in the file at the given path.
+type FileCheckpoint string
+
+// Load reads the EventCheckpoint in the file, if it exists.
+func (f FileCheckpoint) Load() (EventCheckpoint, error) {
+	checkpoint := EventCheckpoint{}
+	data, err := ioutil.ReadFile(string(f))
+	if os.IsNotExist(err) {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return checkpoint, err
+	}
+	err = json.Unmarshal(data, &checkpoint)
+	return checkpoint, err
+}
+
+// Save writes the EventCheckpoint to a temporary file, which then replaces the
+// file at once, so that a crash leaves either the old or the new checkpoint.
+func (f FileCheckpoint) Save(checkpoint EventCheckpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(string(f)), filepath.Base(string(f))+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), string(f))
+}
+
+// TailOption configures optional behaviour of Tail and Poll.
+type TailOption func(*tailOptions)
+
+type tailOptions struct {
+	interval time.Duration
+	types    []string
+	onError  func(error)
+	request  []RequestOption
+}
+
+func newTailOptions(opts []TailOption) *tailOptions {
+	o := &tailOptions{interval: DefaultPollInterval}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// PollInterval sets how long Tail waits between polls.
+func PollInterval(interval time.Duration) TailOption {
+	return func(o *tailOptions) {
+		o.interval = interval
+	}
+}
+
+// EventTypes restricts the Events delivered to those of the given types,
+// matched as MatchEventType does. Events of other types are skipped, and never
+// delivered.
+func EventTypes(types ...string) TailOption {
+	return func(o *tailOptions) {
+		o.types = append(o.types, types...)
+	}
+}
+
+// OnPollError sets the func Tail calls with the errors of the requests that
+// list Events. Tail goes on polling after them.
+func OnPollError(fn func(error)) TailOption {
+	return func(o *tailOptions) {
+		o.onError = fn
+	}
+}
+
+// TailRequestOptions sets the RequestOptions of the requests that list
+// Events, such as StripeAccount.
+func TailRequestOptions(opts ...RequestOption) TailOption {
+	return func(o *tailOptions) {
+		o.request = append(o.request, opts...)
+	}
+}
+
+// matches reports whether Events of type t are to be delivered.
+func (o *tailOptions) matches(t string) bool {
+	if len(o.types) == 0 {
+		return true
+	}
+	for _, pattern := range o.types {
+		if MatchEventType(pattern, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Tail delivers every Event to fn, in the order they were created, as they
+// happen. It polls for new Events every PollInterval, until ctx is done or fn
+// returns an error, which Tail returns. Errors listing Events don't stop it;
+// see OnPollError.
+//
+// Tail starts after the EventCheckpoint loaded from checkpoint, and saves it
+// after each Event fn handles, so that it resumes where it stopped when
+// started again. An Event is delivered again only if the process stops after
+// fn returns, but before the EventCheckpoint is saved. An Event fn returns an
+// error for is delivered again first when Tail is started again.
+//
+// For more information: https://stripe.com/docs/api#list_events
+func (c *EventClient) Tail(ctx context.Context, checkpoint CheckpointStore, fn func(ctx context.Context, event *Event) error, opts ...TailOption) error {
+	o := newTailOptions(opts)
+
+	for {
+		listErr, err := c.poll(ctx, checkpoint, fn, o)
+		if err != nil {
+			return err
+		}
+		if listErr != nil && o.onError != nil && ctx.Err() == nil {
+			o.onError(listErr)
+		}
+
+		if err := sleep(ctx, o.interval); err != nil {
+			return err
+		}
+	}
+}
+
+// Poll delivers the Events created since the EventCheckpoint loaded from
+// checkpoint to fn, once, as Tail does on every poll. It returns the first
+// error, including those listing Events.
+func (c *EventClient) Poll(ctx context.Context, checkpoint CheckpointStore, fn func(ctx context.Context, event *Event) error, opts ...TailOption) error {
+	listErr, err := c.poll(ctx, checkpoint, fn, newTailOptions(opts))
+	if err != nil {
+		return err
+	}
+	return listErr
+}
+
+// poll lists the Events created since the checkpoint and delivers them. It
+// returns the error listing Events apart from the errors of fn and of the
+// CheckpointStore, as only the latter stop Tail.
+func (c *EventClient) poll(ctx context.Context, store CheckpointStore, fn func(ctx context.Context, event *Event) error, o *tailOptions) (listErr, err error) {
+	checkpoint, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	// The Events created during the second of the checkpoint are listed again,
+	// as some may have been listed after the checkpoint was saved.
+	params := EventListParams{Created: &RangeQuery{GTE: time.Unix(checkpoint.Created, 0)}}
+	params.Limit = 100
+	if len(o.types) == 1 {
+		params.Type = o.types[0]
+	}
+
+	// The API lists the newest Events first: all of those since the checkpoint
+	// are listed before delivering the oldest.
+	var events []*Event
+	i := c.IterContext(ctx, &params, o.request...)
+	for i.Next() {
+		events = append(events, i.Event())
+	}
+	if err := i.Err(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return err, nil
+	}
+
+	// Reverse the list, keeping the order of the API within a second.
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Created > events[j].Created })
+	for l, r := 0, len(events)-1; l < r; l, r = l+1, r-1 {
+		events[l], events[r] = events[r], events[l]
+	}
+
+	// The checkpoint is saved after each Event delivered, and once at the end
+	// for those that were skipped.
+	skipped := false
+	for _, event := range events {
+		if checkpoint.delivered(event.Created, event.Id) {
+			continue
+		}
+
+		if !o.matches(event.Type) {
+			checkpoint.advance(event.Created, event.Id)
+			skipped = true
+			continue
+		}
+
+		if err := fn(ctx, event); err != nil {
+			if skipped {
+				store.Save(checkpoint)
+			}
+			return nil, err
+		}
+
+		checkpoint.advance(event.Created, event.Id)
+		if err := store.Save(checkpoint); err != nil {
+			return nil, err
+		}
+		skipped = false
+	}
+
+	if skipped {
+		return nil, store.Save(checkpoint)
+	}
+	return nil, nil
+}
@@ -0,0 +1,263 @@
+package stripe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/bmizerany/assert"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// eventLog is a fake of the /events endpoint. It lists its Events newest
+// first, as the API does, filtered by created[gte] and type, and paged with
+// starting_after and limit.
+type eventLog struct {
+	mu     sync.Mutex
+	events []Event // newest first
+	types  []string
+	fail   int
+}
+
+// add lists an Event created at the given unix time, before those created
+// during the same second that are already listed, as if it was created after
+// them.
+func (l *eventLog) add(id string, created int64, t string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	i := 0
+	for i < len(l.events) && l.events[i].Created > created {
+		i++
+	}
+	l.events = append(l.events[:i], append([]Event{{Id: id, Created: created, Type: t}}, l.events[i:]...)...)
+}
+
+func (l *eventLog) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.fail > 0 {
+		l.fail--
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"error": {"type": "api_error", "message": "try again"}}`)
+		return
+	}
+
+	q := r.URL.Query()
+	gte, _ := strconv.ParseInt(q.Get("created[gte]"), 10, 64)
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	l.types = append(l.types, q.Get("type"))
+
+	var data []Event
+	started := q.Get("starting_after") == ""
+	more := false
+	for _, event := range l.events {
+		if !started {
+			started = event.Id == q.Get("starting_after")
+			continue
+		}
+		if event.Created < gte || (q.Get("type") != "" && event.Type != q.Get("type")) {
+			continue
+		}
+		if len(data) == limit {
+			more = true
+			break
+		}
+		data = append(data, event)
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"object": "list", "has_more": more, "data": data})
+}
+
+// memoryCheckpoint is a CheckpointStore that keeps the EventCheckpoint in
+// memory.
+type memoryCheckpoint struct {
+	checkpoint EventCheckpoint
+	saves      int
+}
+
+func (m *memoryCheckpoint) Load() (EventCheckpoint, error) { return m.checkpoint, nil }
+
+func (m *memoryCheckpoint) Save(checkpoint EventCheckpoint) error {
+	m.checkpoint = checkpoint
+	m.saves++
+	return nil
+}
+
+// collect returns a func for Poll or Tail that appends the ids of the Events
+// it is given to ids.
+func collect(ids *[]string) func(ctx context.Context, event *Event) error {
+	return func(ctx context.Context, event *Event) error {
+		*ids = append(*ids, event.Id)
+		return nil
+	}
+}
+
+func TestEventsPoll(t *testing.T) {
+	setup()
+	defer teardown()
+	log := &eventLog{}
+	serveMux.Handle("/events", log)
+
+	for i := 1; i <= 250; i++ {
+		log.add(fmt.Sprintf("evt_%d", i), int64(1400000000+i/3), "charge.succeeded")
+	}
+
+	var ids []string
+	checkpoint := &memoryCheckpoint{}
+	assert.Equal(t, client.Events.Poll(context.Background(), checkpoint, collect(&ids)), nil)
+
+	// Oldest first, across pages.
+	assert.Equal(t, len(ids), 250)
+	for i, id := range ids {
+		assert.Equal(t, id, fmt.Sprintf("evt_%d", i+1))
+	}
+	assert.Equal(t, checkpoint.checkpoint, EventCheckpoint{Created: 1400000083, Ids: []string{"evt_249", "evt_250"}})
+
+	// Nothing new.
+	ids = nil
+	client.Events.Poll(context.Background(), checkpoint, collect(&ids))
+	assert.Equal(t, len(ids), 0)
+}
+
+func TestEventsPollSameSecond(t *testing.T) {
+	setup()
+	defer teardown()
+	log := &eventLog{}
+	serveMux.Handle("/events", log)
+
+	log.add("evt_1", 1400000000, "charge.succeeded")
+	log.add("evt_2", 1400000001, "charge.succeeded")
+
+	var ids []string
+	checkpoint := &memoryCheckpoint{}
+	client.Events.Poll(context.Background(), checkpoint, collect(&ids))
+	assert.Equal(t, ids, []string{"evt_1", "evt_2"})
+
+	// Listed after the checkpoint was saved, within its second.
+	log.add("evt_3", 1400000001, "charge.succeeded")
+	log.add("evt_4", 1400000002, "charge.succeeded")
+	client.Events.Poll(context.Background(), checkpoint, collect(&ids))
+	assert.Equal(t, ids, []string{"evt_1", "evt_2", "evt_3", "evt_4"})
+}
+
+func TestEventsPollRestart(t *testing.T) {
+	setup()
+	defer teardown()
+	log := &eventLog{}
+	serveMux.Handle("/events", log)
+
+	dir, _ := ioutil.TempDir("", "stripe")
+	defer os.RemoveAll(dir)
+	checkpoint := FileCheckpoint(filepath.Join(dir, "checkpoint.json"))
+
+	saved, err := checkpoint.Load()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, saved, EventCheckpoint{})
+
+	log.add("evt_1", 1400000000, "charge.succeeded")
+	log.add("evt_2", 1400000000, "charge.succeeded")
+
+	// The callback fails on evt_2: it is delivered again first.
+	var ids []string
+	err = client.Events.Poll(context.Background(), checkpoint, func(ctx context.Context, event *Event) error {
+		if event.Id == "evt_2" {
+			return errors.New("database is down")
+		}
+		ids = append(ids, event.Id)
+		return nil
+	})
+	assert.Equal(t, err.Error(), "database is down")
+	assert.Equal(t, ids, []string{"evt_1"})
+
+	saved, _ = checkpoint.Load()
+	assert.Equal(t, saved, EventCheckpoint{Created: 1400000000, Ids: []string{"evt_1"}})
+
+	log.add("evt_3", 1400000005, "charge.succeeded")
+	err = client.Events.Poll(context.Background(), checkpoint, collect(&ids))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, ids, []string{"evt_1", "evt_2", "evt_3"})
+
+	saved, _ = checkpoint.Load()
+	assert.Equal(t, saved, EventCheckpoint{Created: 1400000005, Ids: []string{"evt_3"}})
+}
+
+func TestEventsPollTypes(t *testing.T) {
+	setup()
+	defer teardown()
+	log := &eventLog{}
+	serveMux.Handle("/events", log)
+
+	log.add("evt_1", 1400000000, "charge.succeeded")
+	log.add("evt_2", 1400000001, "invoice.created")
+	log.add("evt_3", 1400000002, "customer.created")
+	log.add("evt_4", 1400000003, "invoice.payment_succeeded")
+
+	var ids []string
+	checkpoint := &memoryCheckpoint{}
+	client.Events.Poll(context.Background(), checkpoint, collect(&ids), EventTypes("invoice.*", "charge.succeeded"))
+	assert.Equal(t, ids, []string{"evt_1", "evt_2", "evt_4"})
+	assert.Equal(t, checkpoint.checkpoint.Ids, []string{"evt_4"})
+
+	// A single type is filtered by the API.
+	client.Events.Poll(context.Background(), &memoryCheckpoint{}, collect(&ids), EventTypes("customer.created"))
+	assert.Equal(t, ids[3:], []string{"evt_3"})
+	assert.Equal(t, log.types, []string{"", "customer.created"})
+
+	// Skipped Events move the checkpoint on too.
+	checkpoint = &memoryCheckpoint{}
+	client.Events.Poll(context.Background(), checkpoint, collect(&ids), EventTypes("transfer.paid", "transfer.failed"))
+	assert.Equal(t, checkpoint.checkpoint.Created, int64(1400000003))
+	assert.Equal(t, checkpoint.saves, 1)
+}
+
+func TestEventsTail(t *testing.T) {
+	setup()
+	defer teardown()
+	log := &eventLog{fail: 1}
+	serveMux.Handle("/events", log)
+	log.add("evt_1", 1400000000, "charge.succeeded")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var ids []string
+	var pollErrs []error
+	err := client.Events.Tail(ctx, &memoryCheckpoint{}, func(ctx context.Context, event *Event) error {
+		ids = append(ids, event.Id)
+		if event.Id == "evt_1" {
+			log.add("evt_2", 1400000000, "charge.succeeded")
+		}
+		if event.Id == "evt_2" {
+			cancel()
+		}
+		return nil
+	}, PollInterval(time.Millisecond), OnPollError(func(err error) { pollErrs = append(pollErrs, err) }))
+
+	assert.Equal(t, err, context.Canceled)
+	assert.Equal(t, ids, []string{"evt_1", "evt_2"})
+	assert.Equal(t, len(pollErrs), 1)
+	var apiErr *APIError
+	assert.T(t, errors.As(pollErrs[0], &apiErr))
+}
+
+func TestEventsTailStopsOnError(t *testing.T) {
+	setup()
+	defer teardown()
+	log := &eventLog{}
+	serveMux.Handle("/events", log)
+	log.add("evt_1", 1400000000, "charge.succeeded")
+
+	err := client.Events.Tail(context.Background(), &memoryCheckpoint{}, func(ctx context.Context, event *Event) error {
+		return errors.New("database is down")
+	}, PollInterval(time.Millisecond))
+	assert.Equal(t, err.Error(), "database is down")
+}
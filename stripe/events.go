@@ -1,16 +1,22 @@
 package stripe
 
 import (
-	"net/url"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
 )
 
-// TODO: There is probably a better way to do this.
+// EventData holds the resource an Event is about. Object is kept as the raw
+// JSON the API sent, and only decoded when asked for through the accessors of
+// Event, such as Event.Charge or Event.DataObject.
 type EventData struct {
-	Object             map[string]interface{} `json:"object"`
+	Object             json.RawMessage        `json:"object"`
 	PreviousAttributes map[string]interface{} `json:"previous_attributes"`
 }
 
 type Event struct {
+	APIResource
 	Id              string     `json:"id"`
 	Object          string     `json:"object"`
 	Data            *EventData `json:"data"`
@@ -21,11 +27,267 @@ type Event struct {
 	Request         string     `json:"request"`
 }
 
+// MatchEventType reports whether an Event of type t matches pattern: either
+// the type itself, a pattern ending in ".*", such as "invoice.*", which
+// matches every type that starts with it, or "*", which matches every type.
+func MatchEventType(pattern, t string) bool {
+	if pattern == t || pattern == "*" {
+		return true
+	}
+	return strings.HasSuffix(pattern, ".*") && strings.HasPrefix(t, strings.TrimSuffix(pattern, "*"))
+}
+
+// eventObjects returns a pointer to a new resource of the type named by the
+// object field of the JSON, for each object Events can be about.
+var eventObjects = map[string]func() interface{}{
+	"account":         func() interface{} { return &Account{} },
+	"application_fee": func() interface{} { return &ApplicationFee{} },
+	"balance":         func() interface{} { return &Balance{} },
+	"card":            func() interface{} { return &Card{} },
+	"charge":          func() interface{} { return &Charge{} },
+	"coupon":          func() interface{} { return &Coupon{} },
+	"customer":        func() interface{} { return &Customer{} },
+	"discount":        func() interface{} { return &Discount{} },
+	"dispute":         func() interface{} { return &Dispute{} },
+	"invoice":         func() interface{} { return &Invoice{} },
+	"invoiceitem":     func() interface{} { return &InvoiceItem{} },
+	"plan":            func() interface{} { return &Plan{} },
+	"recipient":       func() interface{} { return &Recipient{} },
+	"subscription":    func() interface{} { return &Subscription{} },
+	"transfer":        func() interface{} { return &Transfer{} },
+}
+
+// NewEventObject returns a pointer to a new resource of the kind named object,
+// such as a *Charge for "charge", if it is one of those Events can be about.
+func NewEventObject(object string) (interface{}, bool) {
+	newObject, ok := eventObjects[object]
+	if !ok {
+		return nil, false
+	}
+	return newObject(), true
+}
+
+// DataObjectType returns the kind of resource the Event is about, as given by
+// the object field of its data: "charge", "invoice", "subscription"...
+func (e *Event) DataObjectType() string {
+	if e.Data == nil || len(e.Data.Object) == 0 {
+		return ""
+	}
+
+	var object struct {
+		Object string `json:"object"`
+	}
+	json.Unmarshal(e.Data.Object, &object)
+	return object.Object
+}
+
+// DataObject decodes the resource the Event is about into the matching type,
+// such as *Charge for a "charge" or *Subscription for a "subscription", so it
+// can be switched on:
+//
+//	object, err := event.DataObject()
+//	switch object := object.(type) {
+//	case *stripe.Charge:
+//	case *stripe.Invoice:
+//	}
+//
+// Resources of a kind this package has no type for are returned as their raw
+// json.RawMessage. It returns a DecodeError if the resource can't be decoded.
+func (e *Event) DataObject() (interface{}, error) {
+	newObject, ok := eventObjects[e.DataObjectType()]
+	if !ok {
+		if e.Data == nil {
+			return json.RawMessage(nil), nil
+		}
+		return e.Data.Object, nil
+	}
+
+	v := newObject()
+	if err := json.Unmarshal(e.Data.Object, v); err != nil {
+		return nil, newObjectDecodeError(e.Data.Object, v, err)
+	}
+	return v, nil
+}
+
+// decodeObject decodes the resource the Event is about into v, which must be
+// a pointer to the type of the given object. It returns an error if the Event
+// is about another kind of resource.
+func (e *Event) decodeObject(object string, v interface{}) error {
+	if t := e.DataObjectType(); t != object {
+		return fmt.Errorf("stripe: event %s is about a %q, not a %q", e.Id, t, object)
+	}
+	if err := json.Unmarshal(e.Data.Object, v); err != nil {
+		return newObjectDecodeError(e.Data.Object, v, err)
+	}
+	return nil
+}
+
+// Account returns the Account the Event is about, or an error if it is about
+// another kind of resource.
+func (e *Event) Account() (*Account, error) {
+	account := Account{}
+	if err := e.decodeObject("account", &account); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// ApplicationFee returns the ApplicationFee the Event is about, or an error if
+// it is about another kind of resource.
+func (e *Event) ApplicationFee() (*ApplicationFee, error) {
+	fee := ApplicationFee{}
+	if err := e.decodeObject("application_fee", &fee); err != nil {
+		return nil, err
+	}
+	return &fee, nil
+}
+
+// Balance returns the Balance the Event is about, or an error if it is about
+// another kind of resource.
+func (e *Event) Balance() (*Balance, error) {
+	balance := Balance{}
+	if err := e.decodeObject("balance", &balance); err != nil {
+		return nil, err
+	}
+	return &balance, nil
+}
+
+// Card returns the Card the Event is about, or an error if it is about another
+// kind of resource.
+func (e *Event) Card() (*Card, error) {
+	card := Card{}
+	if err := e.decodeObject("card", &card); err != nil {
+		return nil, err
+	}
+	return &card, nil
+}
+
+// Charge returns the Charge the Event is about, or an error if it is about
+// another kind of resource.
+func (e *Event) Charge() (*Charge, error) {
+	charge := Charge{}
+	if err := e.decodeObject("charge", &charge); err != nil {
+		return nil, err
+	}
+	return &charge, nil
+}
+
+// Coupon returns the Coupon the Event is about, or an error if it is about
+// another kind of resource.
+func (e *Event) Coupon() (*Coupon, error) {
+	coupon := Coupon{}
+	if err := e.decodeObject("coupon", &coupon); err != nil {
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+// Customer returns the Customer the Event is about, or an error if it is about
+// another kind of resource.
+func (e *Event) Customer() (*Customer, error) {
+	customer := Customer{}
+	if err := e.decodeObject("customer", &customer); err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// Discount returns the Discount the Event is about, or an error if it is about
+// another kind of resource.
+func (e *Event) Discount() (*Discount, error) {
+	discount := Discount{}
+	if err := e.decodeObject("discount", &discount); err != nil {
+		return nil, err
+	}
+	return &discount, nil
+}
+
+// Dispute returns the Dispute the Event is about, or an error if it is about
+// another kind of resource.
+func (e *Event) Dispute() (*Dispute, error) {
+	dispute := Dispute{}
+	if err := e.decodeObject("dispute", &dispute); err != nil {
+		return nil, err
+	}
+	return &dispute, nil
+}
+
+// Invoice returns the Invoice the Event is about, or an error if it is about
+// another kind of resource.
+func (e *Event) Invoice() (*Invoice, error) {
+	invoice := Invoice{}
+	if err := e.decodeObject("invoice", &invoice); err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// InvoiceItem returns the InvoiceItem the Event is about, or an error if it is
+// about another kind of resource.
+func (e *Event) InvoiceItem() (*InvoiceItem, error) {
+	item := InvoiceItem{}
+	if err := e.decodeObject("invoiceitem", &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// Plan returns the Plan the Event is about, or an error if it is about another
+// kind of resource.
+func (e *Event) Plan() (*Plan, error) {
+	plan := Plan{}
+	if err := e.decodeObject("plan", &plan); err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// Recipient returns the Recipient the Event is about, or an error if it is
+// about another kind of resource.
+func (e *Event) Recipient() (*Recipient, error) {
+	recipient := Recipient{}
+	if err := e.decodeObject("recipient", &recipient); err != nil {
+		return nil, err
+	}
+	return &recipient, nil
+}
+
+// Subscription returns the Subscription the Event is about, or an error if it
+// is about another kind of resource.
+func (e *Event) Subscription() (*Subscription, error) {
+	subscription := Subscription{}
+	if err := e.decodeObject("subscription", &subscription); err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// Transfer returns the Transfer the Event is about, or an error if it is about
+// another kind of resource.
+func (e *Event) Transfer() (*Transfer, error) {
+	transfer := Transfer{}
+	if err := e.decodeObject("transfer", &transfer); err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
 type EventListResponse struct {
 	ListResponse
 	Data []Event `json:"data"`
 }
 
+// EventIter is an Iter over Events.
+type EventIter struct {
+	*Iter
+}
+
+// Event returns the Event Next advanced to.
+func (it *EventIter) Event() *Event {
+	e, _ := it.Current().(*Event)
+	return e
+}
+
 type EventClient struct {
 	client Client
 }
@@ -33,27 +295,76 @@ type EventClient struct {
 // Retrieve loads a event.
 //
 // For more information: https://stripe.com/docs/api#retrieve_event
-func (c *EventClient) Retrieve(id string) (*Event, error) {
+func (c *EventClient) Retrieve(id string, opts ...RequestOption) (*Event, error) {
+	return c.RetrieveContext(context.Background(), id, opts...)
+}
+
+// RetrieveContext is like Retrieve, but uses ctx for the underlying request.
+func (c *EventClient) RetrieveContext(ctx context.Context, id string, opts ...RequestOption) (*Event, error) {
 	event := Event{}
-	err := c.client.get("/events/"+id, nil, &event)
-	return &event, err
+	err := c.client.get(ctx, "/events/"+id, nil, &event, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
 }
 
-// All lists the first 10 events. It calls AllWithFilters with a blank Filters
+// All lists the first 10 events. It calls AllWithParams with no params
 // so all defaults are used.
 //
 // For more information: https://stripe.com/docs/api#list_events
-func (c *EventClient) All() (*EventListResponse, error) {
-	return c.AllWithFilters(Filters{})
+func (c *EventClient) All(opts ...RequestOption) (*EventListResponse, error) {
+	return c.AllContext(context.Background(), opts...)
+}
+
+// AllContext is like All, but uses ctx for the underlying request.
+func (c *EventClient) AllContext(ctx context.Context, opts ...RequestOption) (*EventListResponse, error) {
+	return c.AllWithParamsContext(ctx, nil, opts...)
 }
 
-// AllWithFilters takes a Filters and applies all valid filters for the action.
+// AllWithParams lists the Events matching params.
 //
 // For more information: https://stripe.com/docs/api#list_events
-func (c *EventClient) AllWithFilters(filters Filters) (*EventListResponse, error) {
+func (c *EventClient) AllWithParams(params *EventListParams, opts ...RequestOption) (*EventListResponse, error) {
+	return c.AllWithParamsContext(context.Background(), params, opts...)
+}
+
+// AllWithParamsContext is like AllWithParams, but uses ctx for the
+// underlying request.
+func (c *EventClient) AllWithParamsContext(ctx context.Context, params *EventListParams, opts ...RequestOption) (*EventListResponse, error) {
 	response := EventListResponse{}
-	values := url.Values{}
-	addFiltersToValues([]string{"count", "offset", "type"}, filters, &values)
-	err := c.client.get("/events", values, &response)
-	return &response, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.get(ctx, "/events", values, &response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Iter returns an iterator over the Events matching params, fetching pages
+// as they are needed.
+//
+// For more information: https://stripe.com/docs/api#pagination
+func (c *EventClient) Iter(params *EventListParams, opts ...RequestOption) *EventIter {
+	return c.IterContext(context.Background(), params, opts...)
+}
+
+// IterContext is like Iter, but uses ctx for the underlying requests.
+func (c *EventClient) IterContext(ctx context.Context, params *EventListParams, opts ...RequestOption) *EventIter {
+	p := EventListParams{}
+	if params != nil {
+		p = *params
+	}
+
+	return &EventIter{newIter(ctx, p.ListParams, func(ctx context.Context, page ListParams) ([]interface{}, ListResponse, error) {
+		p.ListParams = page
+		list, err := c.AllWithParamsContext(ctx, &p, opts...)
+		if err != nil {
+			return nil, ListResponse{}, err
+		}
+		return pageOf(list.Data), list.ListResponse, nil
+	})}
 }
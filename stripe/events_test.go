@@ -1,7 +1,11 @@
 package stripe
 
 import (
+	"encoding/json"
+	"errors"
 	"github.com/bmizerany/assert"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -11,7 +15,11 @@ func TestEventsRetrieve(t *testing.T) {
 	handleWithJSON("/events/evt_123456789", "events/event.json")
 	event, _ := client.Events.Retrieve("evt_123456789")
 	assert.Equal(t, event.Id, "evt_123456789")
-	assert.Equal(t, event.Data.Object["plan"].(map[string]interface{})["name"], "Plan")
+	assert.Equal(t, event.DataObjectType(), "subscription")
+	subscription, err := event.Subscription()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, subscription.Id, "sub_123456789")
+	assert.Equal(t, subscription.Plan.Name, "Plan")
 	assert.Equal(t, event.Data.PreviousAttributes["plan"].(map[string]interface{})["name"], "Monthly Plan")
 }
 
@@ -24,11 +32,114 @@ func TestEventsAll(t *testing.T) {
 	assert.Equal(t, events.Data[0].Id, "evt_123456789")
 }
 
-func TestEventsAllWithFilters(t *testing.T) {
+func TestEventsAllWithParams(t *testing.T) {
 	setup()
 	defer teardown()
 	handleWithJSON("/events", "events/events.json")
-	events, _ := client.Events.AllWithFilters(Filters{})
+	events, _ := client.Events.AllWithParams(&EventListParams{})
 	assert.Equal(t, events.Count, 1)
 	assert.Equal(t, events.Data[0].Id, "evt_123456789")
 }
+
+// eventAbout returns an Event whose data object is the JSON in the fixture f.
+func eventAbout(f string) *Event {
+	return &Event{Id: "evt_123456789", Data: &EventData{Object: json.RawMessage(loadFixture(f))}}
+}
+
+func TestEventTypedObjects(t *testing.T) {
+	charge, err := eventAbout("charges/charge.json").Charge()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, charge.Id, "ch_123456789")
+	assert.Equal(t, charge.Amount, int64(10000))
+
+	invoice, err := eventAbout("invoices/invoice.json").Invoice()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, invoice.Object, "invoice")
+
+	customer, err := eventAbout("customers/customer.json").Customer()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, customer.Object, "customer")
+
+	item, err := eventAbout("invoice_items/invoice_item.json").InvoiceItem()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, item.Object, "invoiceitem")
+}
+
+func TestEventDataObject(t *testing.T) {
+	tests := map[string]interface{}{
+		"charges/charge.json":             &Charge{},
+		"invoices/invoice.json":           &Invoice{},
+		"subscriptions/subscription.json": &Subscription{},
+		"transfers/transfer.json":         &Transfer{},
+		"disputes/dispute.json":           &Dispute{},
+	}
+
+	for f, want := range tests {
+		object, err := eventAbout(f).DataObject()
+		assert.Equal(t, err, nil)
+		assert.Equal(t, reflect.TypeOf(object), reflect.TypeOf(want), f)
+	}
+}
+
+func TestEventDataObjectUnknownType(t *testing.T) {
+	event := eventAbout("sample.json")
+	event.Data.Object = json.RawMessage(`{"id": "bm_123", "object": "bitcoin_receiver"}`)
+	assert.Equal(t, event.DataObjectType(), "bitcoin_receiver")
+
+	object, err := event.DataObject()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, object, json.RawMessage(`{"id": "bm_123", "object": "bitcoin_receiver"}`))
+
+	object, err = (&Event{}).DataObject()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, object, json.RawMessage(nil))
+}
+
+func TestEventDataObjectWrongType(t *testing.T) {
+	event := eventAbout("charges/charge.json")
+	invoice, err := event.Invoice()
+	assert.Equal(t, invoice, (*Invoice)(nil))
+	assert.Equal(t, err.Error(), `stripe: event evt_123456789 is about a "charge", not a "invoice"`)
+}
+
+func TestEventDataObjectDecodeError(t *testing.T) {
+	event := &Event{Data: &EventData{Object: json.RawMessage(`{"object": "charge", "amount": "lots"}`)}}
+
+	charge, err := event.Charge()
+	assert.Equal(t, charge, (*Charge)(nil))
+	var decodeErr *DecodeError
+	assert.T(t, errors.As(err, &decodeErr))
+	assert.Equal(t, decodeErr.Field, "amount")
+	assert.T(t, strings.HasPrefix(err.Error(), `stripe: cannot decode Charge field "amount": `), err)
+
+	_, err = event.DataObject()
+	assert.T(t, errors.As(err, &decodeErr))
+}
+
+func TestMatchEventType(t *testing.T) {
+	for _, test := range []struct {
+		pattern, t string
+		match      bool
+	}{
+		{"charge.succeeded", "charge.succeeded", true},
+		{"charge.succeeded", "charge.failed", false},
+		{"invoice.*", "invoice.payment_succeeded", true},
+		{"customer.subscription.*", "customer.subscription.updated", true},
+		{"customer.*", "customer.subscription.updated", true},
+		{"customer.*", "customer", false},
+		{"invoice.*", "invoiceitem.created", false},
+		{"invoice*", "invoiceitem.created", false},
+		{"*", "charge.succeeded", true},
+	} {
+		assert.Equal(t, MatchEventType(test.pattern, test.t), test.match, test.pattern, test.t)
+	}
+}
+
+func TestNewEventObject(t *testing.T) {
+	object, ok := NewEventObject("charge")
+	assert.T(t, ok)
+	assert.Equal(t, object, &Charge{})
+
+	_, ok = NewEventObject("bitcoin_receiver")
+	assert.Equal(t, ok, false)
+}
@@ -0,0 +1,192 @@
+package stripe
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// The types in this file hold fields that the API returns as the ID of another
+// resource, or, when asked to with the Expand RequestOption, as that whole
+// resource. Id is set in both cases, the resource only when expanded:
+//
+//	charge, err := client.Charges.Retrieve("ch_123", stripe.Expand("customer"))
+//	charge.Customer.Id       // "cus_123"
+//	charge.Customer.Customer // *Customer, nil unless expanded
+//
+// For more information: https://stripe.com/docs/api#expand
+
+// CustomerField is a Customer that may be expanded.
+type CustomerField struct {
+	Id       string
+	Customer *Customer
+}
+
+// Expanded reports whether the whole Customer was returned.
+func (f *CustomerField) Expanded() bool { return f.Customer != nil }
+
+// UnmarshalJSON decodes either a Customer ID or a Customer.
+func (f *CustomerField) UnmarshalJSON(data []byte) error {
+	customer := Customer{}
+	expanded, err := unmarshalExpandable(data, &f.Id, &customer)
+	if expanded {
+		f.Id, f.Customer = customer.Id, &customer
+	}
+	return err
+}
+
+// MarshalJSON encodes the Customer if expanded, or its ID.
+func (f CustomerField) MarshalJSON() ([]byte, error) {
+	return marshalExpandable(f.Id, f.Customer != nil, f.Customer)
+}
+
+// ChargeField is a Charge that may be expanded.
+type ChargeField struct {
+	Id     string
+	Charge *Charge
+}
+
+// Expanded reports whether the whole Charge was returned.
+func (f *ChargeField) Expanded() bool { return f.Charge != nil }
+
+// UnmarshalJSON decodes either a Charge ID or a Charge.
+func (f *ChargeField) UnmarshalJSON(data []byte) error {
+	charge := Charge{}
+	expanded, err := unmarshalExpandable(data, &f.Id, &charge)
+	if expanded {
+		f.Id, f.Charge = charge.Id, &charge
+	}
+	return err
+}
+
+// MarshalJSON encodes the Charge if expanded, or its ID.
+func (f ChargeField) MarshalJSON() ([]byte, error) {
+	return marshalExpandable(f.Id, f.Charge != nil, f.Charge)
+}
+
+// InvoiceField is an Invoice that may be expanded.
+type InvoiceField struct {
+	Id      string
+	Invoice *Invoice
+}
+
+// Expanded reports whether the whole Invoice was returned.
+func (f *InvoiceField) Expanded() bool { return f.Invoice != nil }
+
+// UnmarshalJSON decodes either an Invoice ID or an Invoice.
+func (f *InvoiceField) UnmarshalJSON(data []byte) error {
+	invoice := Invoice{}
+	expanded, err := unmarshalExpandable(data, &f.Id, &invoice)
+	if expanded {
+		f.Id, f.Invoice = invoice.Id, &invoice
+	}
+	return err
+}
+
+// MarshalJSON encodes the Invoice if expanded, or its ID.
+func (f InvoiceField) MarshalJSON() ([]byte, error) {
+	return marshalExpandable(f.Id, f.Invoice != nil, f.Invoice)
+}
+
+// BalanceTransactionField is a BalanceTransaction that may be expanded.
+type BalanceTransactionField struct {
+	Id                 string
+	BalanceTransaction *BalanceTransaction
+}
+
+// Expanded reports whether the whole BalanceTransaction was returned.
+func (f *BalanceTransactionField) Expanded() bool { return f.BalanceTransaction != nil }
+
+// UnmarshalJSON decodes either a BalanceTransaction ID or a
+// BalanceTransaction.
+func (f *BalanceTransactionField) UnmarshalJSON(data []byte) error {
+	txn := BalanceTransaction{}
+	expanded, err := unmarshalExpandable(data, &f.Id, &txn)
+	if expanded {
+		f.Id, f.BalanceTransaction = txn.Id, &txn
+	}
+	return err
+}
+
+// MarshalJSON encodes the BalanceTransaction if expanded, or its ID.
+func (f BalanceTransactionField) MarshalJSON() ([]byte, error) {
+	return marshalExpandable(f.Id, f.BalanceTransaction != nil, f.BalanceTransaction)
+}
+
+// BalanceTransactionSource is the source of a BalanceTransaction, which may be
+// expanded. As the source can be one of several resources, Object holds the
+// kind of resource that was returned ("charge", "transfer", ...) and the
+// matching field is set. Sources of any other kind are kept in Raw.
+type BalanceTransactionSource struct {
+	Id             string
+	Object         string
+	Charge         *Charge
+	Transfer       *Transfer
+	ApplicationFee *ApplicationFee
+	Raw            json.RawMessage
+}
+
+// Expanded reports whether the whole source was returned.
+func (s *BalanceTransactionSource) Expanded() bool { return s.Raw != nil }
+
+// UnmarshalJSON decodes either the ID of the source or the source.
+func (s *BalanceTransactionSource) UnmarshalJSON(data []byte) error {
+	var source struct {
+		Id     string `json:"id"`
+		Object string `json:"object"`
+	}
+
+	expanded, err := unmarshalExpandable(data, &s.Id, &source)
+	if !expanded || err != nil {
+		return err
+	}
+
+	s.Id, s.Object = source.Id, source.Object
+	s.Raw = append(json.RawMessage(nil), data...)
+
+	switch s.Object {
+	case "charge":
+		s.Charge = &Charge{}
+		return json.Unmarshal(data, s.Charge)
+	case "transfer":
+		s.Transfer = &Transfer{}
+		return json.Unmarshal(data, s.Transfer)
+	case "application_fee":
+		s.ApplicationFee = &ApplicationFee{}
+		return json.Unmarshal(data, s.ApplicationFee)
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes the source if expanded, or its ID.
+func (s BalanceTransactionSource) MarshalJSON() ([]byte, error) {
+	return marshalExpandable(s.Id, s.Raw != nil, s.Raw)
+}
+
+// unmarshalExpandable decodes data, either an ID or an object, storing the ID
+// in id or the object in v. It reports whether data was an object.
+func unmarshalExpandable(data []byte, id *string, v interface{}) (bool, error) {
+	data = bytes.TrimSpace(data)
+
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		return false, nil
+	case len(data) > 0 && data[0] == '{':
+		return true, json.Unmarshal(data, v)
+	default:
+		return false, json.Unmarshal(data, id)
+	}
+}
+
+// marshalExpandable encodes v if expanded, or else id, or null if there is
+// neither.
+func marshalExpandable(id string, expanded bool, v interface{}) ([]byte, error) {
+	switch {
+	case expanded:
+		return json.Marshal(v)
+	case id != "":
+		return json.Marshal(id)
+	default:
+		return []byte("null"), nil
+	}
+}
@@ -0,0 +1,93 @@
+package stripe
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/bmizerany/assert"
+	"net/http"
+	"testing"
+)
+
+func TestExpand(t *testing.T) {
+	setup()
+	defer teardown()
+	serveMux.HandleFunc("/charges/ch_123456789", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Query()["expand[]"], []string{"customer", "invoice.customer"})
+		fmt.Fprint(w, loadFixture("charges/charge_expanded.json"))
+	})
+	serveMux.HandleFunc("/charges", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		assert.Equal(t, r.PostForm["expand[]"], []string{"customer"})
+		assert.Equal(t, r.PostForm.Get("amount"), "100")
+		fmt.Fprint(w, loadFixture("charges/charge_expanded.json"))
+	})
+
+	charge, err := client.Charges.Retrieve("ch_123456789", Expand("customer", "invoice.customer"))
+	assert.Equal(t, err, nil)
+	assert.T(t, charge.Customer.Expanded())
+	assert.Equal(t, charge.Customer.Id, "cus_123456789")
+	assert.Equal(t, charge.Customer.Customer.Email, "apt@stripe.com")
+	assert.T(t, !charge.Invoice.Expanded())
+	assert.Equal(t, charge.Invoice.Id, "in_123456789")
+
+	_, err = client.Charges.Create(&ChargeParams{Amount: Int(100)}, Expand("customer"))
+	assert.Equal(t, err, nil)
+}
+
+func TestExpandableFieldNotExpanded(t *testing.T) {
+	setup()
+	defer teardown()
+	handleWithJSON("/charges/ch_123456789", "charges/charge.json")
+
+	charge, _ := client.Charges.Retrieve("ch_123456789")
+	assert.T(t, !charge.Customer.Expanded())
+	assert.Equal(t, charge.Customer.Id, "cus_123456789")
+	assert.Equal(t, charge.Customer.Customer, (*Customer)(nil))
+	assert.Equal(t, charge.BalanceTransaction.Id, "txn_123456789")
+}
+
+func TestExpandableFieldNull(t *testing.T) {
+	charge := Charge{}
+	err := json.Unmarshal([]byte(`{"customer": null, "invoice": null}`), &charge)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, charge.Customer, CustomerField{})
+
+	data, _ := json.Marshal(charge.Customer)
+	assert.Equal(t, string(data), "null")
+}
+
+func TestExpandableFieldMarshal(t *testing.T) {
+	data, _ := json.Marshal(CustomerField{Id: "cus_123456789"})
+	assert.Equal(t, string(data), `"cus_123456789"`)
+
+	data, _ = json.Marshal(InvoiceField{Id: "in_123456789", Invoice: &Invoice{Id: "in_123456789"}})
+	invoice := InvoiceField{}
+	json.Unmarshal(data, &invoice)
+	assert.T(t, invoice.Expanded())
+	assert.Equal(t, invoice.Invoice.Id, "in_123456789")
+}
+
+func TestBalanceTransactionSource(t *testing.T) {
+	txn := BalanceTransaction{}
+	json.Unmarshal([]byte(`{"source": "ch_123456789"}`), &txn)
+	assert.T(t, !txn.Source.Expanded())
+	assert.Equal(t, txn.Source.Id, "ch_123456789")
+
+	txn = BalanceTransaction{}
+	json.Unmarshal([]byte(`{"source": {"id": "ch_123456789", "object": "charge", "amount": 100}}`), &txn)
+	assert.T(t, txn.Source.Expanded())
+	assert.Equal(t, txn.Source.Object, "charge")
+	assert.Equal(t, txn.Source.Charge.Amount, int64(100))
+
+	txn = BalanceTransaction{}
+	json.Unmarshal([]byte(`{"source": {"id": "tr_123456789", "object": "transfer", "amount": 200}}`), &txn)
+	assert.Equal(t, txn.Source.Transfer.Amount, int64(200))
+
+	txn = BalanceTransaction{}
+	json.Unmarshal([]byte(`{"source": {"id": "adj_123456789", "object": "adjustment"}}`), &txn)
+	assert.Equal(t, txn.Source.Id, "adj_123456789")
+	assert.Equal(t, string(txn.Source.Raw), `{"id": "adj_123456789", "object": "adjustment"}`)
+
+	data, _ := json.Marshal(txn.Source)
+	assert.Equal(t, string(data), `{"id":"adj_123456789","object":"adjustment"}`)
+}
@@ -0,0 +1,381 @@
+package stripe
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// encodeParams takes a pointer to params (usually *SomeTypeParams) and returns
+// the url.Values the Stripe API expects for it. Each field is added under the
+// name in its stripe_field tag, and fields without one are left out:
+//
+//   - Nested structs, and maps, are added as name[field].
+//   - Slices are added as name[0][field], or name[] for slices of values.
+//   - Embedded structs without a tag are added as if their fields were those
+//     of the struct they are embedded in.
+//   - time.Time is added as a unix timestamp.
+//   - Bools tagged with opposite:"true" are added negated.
+//   - Fields tagged with replaces:"true" are added in place of the struct they
+//     are in when they are set, under its name rather than theirs, and the
+//     other fields of the struct are left out.
+//   - Floats are added with as many decimal places as they need, and no more
+//     than those in their precision tag. Floats outside of the range given by
+//     their min and max tags are rejected with a ParamError.
+//
+// Values that are not set are left out: nil pointers, and the zero value of
+// fields that are not pointers. Pointers that are set, and the entries of maps
+// and slices, are always added, even when they hold a zero value.
+//
+// The reflection needed to encode a type is only done once: the first time a
+// type is encoded, an encoderFunc is compiled for it and cached.
+func encodeParams(params interface{}) (url.Values, error) {
+	values := url.Values{}
+	if params == nil {
+		return values, nil
+	}
+
+	v := reflect.ValueOf(params)
+	err := encoderFor(v.Type(), noOptions)(&values, "", v, false)
+	return values, err
+}
+
+// encoderFunc adds v to values under key. When set is true, v is added even if
+// it is the zero value of its type.
+type encoderFunc func(values *url.Values, key string, v reflect.Value, set bool) error
+
+// fieldOptions hold the options, from the tags of a field, that change how its
+// value is encoded.
+type fieldOptions struct {
+	opposite       bool
+	precision      int // -1 when there is no precision tag
+	min, max       float64
+	hasMin, hasMax bool
+}
+
+// noOptions are the fieldOptions of a field without tags.
+var noOptions = fieldOptions{precision: -1}
+
+// parseFieldOptions returns the fieldOptions in the tags of field.
+func parseFieldOptions(field reflect.StructField) (fieldOptions, error) {
+	opts := noOptions
+	opts.opposite, _ = strconv.ParseBool(field.Tag.Get("opposite"))
+
+	if tag, ok := field.Tag.Lookup("precision"); ok {
+		p, err := strconv.Atoi(tag)
+		if err != nil || p < 0 {
+			return opts, fmt.Errorf("stripe: invalid precision tag %q on %s", tag, field.Name)
+		}
+		opts.precision = p
+	}
+
+	for _, bound := range []struct {
+		tag string
+		to  *float64
+		set *bool
+	}{{"min", &opts.min, &opts.hasMin}, {"max", &opts.max, &opts.hasMax}} {
+		if tag, ok := field.Tag.Lookup(bound.tag); ok {
+			f, err := strconv.ParseFloat(tag, 64)
+			if err != nil {
+				return opts, fmt.Errorf("stripe: invalid %s tag %q on %s", bound.tag, tag, field.Name)
+			}
+			*bound.to, *bound.set = f, true
+		}
+	}
+
+	return opts, nil
+}
+
+// encoderKey identifies a compiled encoderFunc in encoderCache.
+type encoderKey struct {
+	t    reflect.Type
+	opts fieldOptions
+}
+
+var (
+	encoderCache sync.Map // map[encoderKey]encoderFunc
+
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+// encoderFor returns the encoderFunc for t, compiling it the first time.
+func encoderFor(t reflect.Type, opts fieldOptions) encoderFunc {
+	key := encoderKey{t, opts}
+	if f, ok := encoderCache.Load(key); ok {
+		return f.(encoderFunc)
+	}
+
+	// Store an encoderFunc that waits for the real one before compiling it, so
+	// that types which refer to themselves use it rather than recursing.
+	var (
+		wg sync.WaitGroup
+		f  encoderFunc
+	)
+	wg.Add(1)
+	indirect, loaded := encoderCache.LoadOrStore(key, encoderFunc(func(values *url.Values, key string, v reflect.Value, set bool) error {
+		wg.Wait()
+		return f(values, key, v, set)
+	}))
+	if loaded {
+		return indirect.(encoderFunc)
+	}
+
+	f = newEncoder(t, opts)
+	wg.Done()
+	encoderCache.Store(key, f)
+	return f
+}
+
+// newEncoder compiles the encoderFunc for t.
+func newEncoder(t reflect.Type, opts fieldOptions) encoderFunc {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return newPtrEncoder(t, opts)
+	case reflect.Interface:
+		return encodeInterface
+	case reflect.Struct:
+		if t == timeType {
+			return encodeTime
+		}
+		return newStructEncoder(t)
+	case reflect.Map:
+		return newMapEncoder(t)
+	case reflect.Slice, reflect.Array:
+		return newSliceEncoder(t)
+	case reflect.String:
+		return encodeString
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return encodeInt
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return encodeUint
+	case reflect.Float32, reflect.Float64:
+		return newFloatEncoder(t, opts)
+	case reflect.Bool:
+		if opts.opposite {
+			return encodeOppositeBool
+		}
+		return encodeBool
+	}
+
+	return func(values *url.Values, key string, v reflect.Value, set bool) error {
+		return fmt.Errorf("stripe: cannot encode %s of type %s", key, t)
+	}
+}
+
+func newPtrEncoder(t reflect.Type, opts fieldOptions) encoderFunc {
+	elem := encoderFor(t.Elem(), opts)
+	return func(values *url.Values, key string, v reflect.Value, set bool) error {
+		if v.IsNil() {
+			return nil
+		}
+		return elem(values, key, v.Elem(), true)
+	}
+}
+
+func encodeInterface(values *url.Values, key string, v reflect.Value, set bool) error {
+	if v.IsNil() {
+		return nil
+	}
+	return encoderFor(v.Elem().Type(), noOptions)(values, key, v.Elem(), true)
+}
+
+// structField is a field of a struct, as compiled by newStructEncoder.
+type structField struct {
+	index  int
+	name   string
+	encode encoderFunc
+}
+
+func newStructEncoder(t reflect.Type) encoderFunc {
+	var fields, replacing []structField
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Tag.Get("stripe_field")
+		if name == "-" || (name == "" && !field.Anonymous) {
+			continue
+		}
+
+		opts, err := parseFieldOptions(field)
+		if err != nil {
+			fields = append(fields, structField{i, name, encodeError(err)})
+			continue
+		}
+
+		f := structField{i, name, encoderFor(field.Type, opts)}
+		if replaces, _ := strconv.ParseBool(field.Tag.Get("replaces")); replaces {
+			replacing = append(replacing, f)
+			continue
+		}
+		fields = append(fields, f)
+	}
+
+	return func(values *url.Values, key string, v reflect.Value, set bool) error {
+		for _, field := range replacing {
+			if v.Field(field.index).IsZero() {
+				continue
+			}
+
+			// A struct with no key of its own is the params themselves, so
+			// the field keeps its name.
+			k := key
+			if k == "" {
+				k = field.name
+			}
+			return field.encode(values, k, v.Field(field.index), false)
+		}
+
+		for _, field := range fields {
+			k := key
+			if field.name != "" {
+				k = formKey(key, field.name)
+			}
+
+			if err := field.encode(values, k, v.Field(field.index), false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func newMapEncoder(t reflect.Type) encoderFunc {
+	elem := encoderFor(t.Elem(), noOptions)
+	return func(values *url.Values, key string, v reflect.Value, set bool) error {
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+
+		for _, k := range keys {
+			if err := elem(values, formKey(key, fmt.Sprint(k)), v.MapIndex(k), true); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func newSliceEncoder(t reflect.Type) encoderFunc {
+	elem := encoderFor(t.Elem(), noOptions)
+
+	indexed := t.Elem()
+	if indexed.Kind() == reflect.Ptr {
+		indexed = indexed.Elem()
+	}
+	nested := indexed.Kind() == reflect.Struct || indexed.Kind() == reflect.Map
+
+	return func(values *url.Values, key string, v reflect.Value, set bool) error {
+		for i := 0; i < v.Len(); i++ {
+			k := key + "[]"
+			if nested {
+				k = formKey(key, strconv.Itoa(i))
+			}
+
+			if err := elem(values, k, v.Index(i), true); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func encodeTime(values *url.Values, key string, v reflect.Value, set bool) error {
+	t := v.Interface().(time.Time)
+	if set || !t.IsZero() {
+		values.Add(key, strconv.FormatInt(t.Unix(), 10))
+	}
+	return nil
+}
+
+func encodeString(values *url.Values, key string, v reflect.Value, set bool) error {
+	if set || v.Len() > 0 {
+		values.Add(key, v.String())
+	}
+	return nil
+}
+
+func encodeInt(values *url.Values, key string, v reflect.Value, set bool) error {
+	if n := v.Int(); set || n != 0 {
+		values.Add(key, strconv.FormatInt(n, 10))
+	}
+	return nil
+}
+
+func encodeUint(values *url.Values, key string, v reflect.Value, set bool) error {
+	if n := v.Uint(); set || n != 0 {
+		values.Add(key, strconv.FormatUint(n, 10))
+	}
+	return nil
+}
+
+// newFloatEncoder returns an encoderFunc that adds floats in decimal, with as
+// many decimal places as needed to hold them exactly. It rejects floats that
+// need more decimal places than opts.precision, or are out of its range.
+func newFloatEncoder(t reflect.Type, opts fieldOptions) encoderFunc {
+	bits := t.Bits()
+
+	return func(values *url.Values, key string, v reflect.Value, set bool) error {
+		f := v.Float()
+		if !set && f == 0 {
+			return nil
+		}
+
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return &ParamError{key, fmt.Sprintf("%v is not a number", f)}
+		}
+		if opts.hasMin && f < opts.min {
+			return &ParamError{key, fmt.Sprintf("%v is less than %v", f, opts.min)}
+		}
+		if opts.hasMax && f > opts.max {
+			return &ParamError{key, fmt.Sprintf("%v is greater than %v", f, opts.max)}
+		}
+
+		s := strconv.FormatFloat(f, 'f', -1, bits)
+		if i := strings.IndexByte(s, '.'); opts.precision >= 0 && i >= 0 && len(s)-i-1 > opts.precision {
+			return &ParamError{key, fmt.Sprintf("%s has more than %d decimal places", s, opts.precision)}
+		}
+
+		values.Add(key, s)
+		return nil
+	}
+}
+
+func encodeBool(values *url.Values, key string, v reflect.Value, set bool) error {
+	if b := v.Bool(); set || b {
+		values.Add(key, strconv.FormatBool(b))
+	}
+	return nil
+}
+
+func encodeOppositeBool(values *url.Values, key string, v reflect.Value, set bool) error {
+	if b := v.Bool(); set || b {
+		values.Add(key, strconv.FormatBool(!b))
+	}
+	return nil
+}
+
+// encodeError returns an encoderFunc that always fails with err.
+func encodeError(err error) encoderFunc {
+	return func(values *url.Values, key string, v reflect.Value, set bool) error {
+		return err
+	}
+}
+
+// formKey nests name under key, as key[name], unless key is empty.
+func formKey(key, name string) string {
+	if key == "" {
+		return name
+	}
+	return key + "[" + name + "]"
+}
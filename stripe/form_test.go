@@ -0,0 +1,382 @@
+package stripe
+
+import (
+	"fmt"
+	"github.com/bmizerany/assert"
+	"math"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type Mock struct {
+	MockString string  `stripe_field:"string_value"`
+	MockBool   bool    `stripe_field:"bool_value"`
+	MockInt    int     `stripe_field:"int_value"`
+	MockInt64  int64   `stripe_field:"int64_value"`
+	MockFloat  float64 `stripe_field:"float64_value"`
+	Untagged   string
+}
+
+type PointerMock struct {
+	MockString *string  `stripe_field:"string_value"`
+	MockBool   *bool    `stripe_field:"bool_value"`
+	MockInt    *int     `stripe_field:"int_value"`
+	MockFloat  *float64 `stripe_field:"float64_value"`
+	Opposite   *bool    `stripe_field:"opposite_value" opposite:"true"`
+	Unset      *string  `stripe_field:"unset_value"`
+}
+
+type NestedMock struct {
+	Mock
+	Child    *Mock             `stripe_field:"child"`
+	Children []Mock            `stripe_field:"children"`
+	Tags     []string          `stripe_field:"tags"`
+	Labels   map[string]string `stripe_field:"labels"`
+	At       time.Time         `stripe_field:"at"`
+	Skipped  string            `stripe_field:"-"`
+}
+
+func TestEncodeParams(t *testing.T) {
+	mock := Mock{
+		MockString: "foo",
+		MockBool:   true,
+		MockInt:    10,
+		MockInt64:  1 << 40,
+		MockFloat:  25.50,
+		Untagged:   "left out",
+	}
+	values, err := encodeParams(&mock)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values, url.Values{
+		"string_value":  {"foo"},
+		"bool_value":    {"true"},
+		"int_value":     {"10"},
+		"int64_value":   {"1099511627776"},
+		"float64_value": {"25.5"},
+	})
+}
+
+func TestEncodeParamsZeroValues(t *testing.T) {
+	values, _ := encodeParams(&Mock{})
+	assert.Equal(t, values, url.Values{})
+}
+
+func TestEncodeParamsZeroPointers(t *testing.T) {
+	mock := PointerMock{
+		MockString: String(""),
+		MockBool:   Bool(false),
+		MockInt:    Int(0),
+		MockFloat:  Float64(0),
+		Opposite:   Bool(false),
+	}
+	values, _ := encodeParams(&mock)
+	assert.Equal(t, values, url.Values{
+		"string_value":   {""},
+		"bool_value":     {"false"},
+		"int_value":      {"0"},
+		"float64_value":  {"0"},
+		"opposite_value": {"true"},
+	})
+}
+
+func TestEncodeParamsNested(t *testing.T) {
+	mock := NestedMock{
+		Mock:     Mock{MockString: "embedded"},
+		Child:    &Mock{MockInt: 1},
+		Children: []Mock{{MockString: "a"}, {MockBool: true}},
+		Tags:     []string{"x", "y"},
+		Labels:   map[string]string{"b": "2", "a": ""},
+		At:       time.Unix(1400000000, 0),
+		Skipped:  "left out",
+	}
+	values, err := encodeParams(&mock)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values, url.Values{
+		"string_value":              {"embedded"},
+		"child[int_value]":          {"1"},
+		"children[0][string_value]": {"a"},
+		"children[1][bool_value]":   {"true"},
+		"tags[]":                    {"x", "y"},
+		"labels[a]":                 {""},
+		"labels[b]":                 {"2"},
+		"at":                        {"1400000000"},
+	})
+}
+
+type SourceMock struct {
+	Card   *CardParams `stripe_field:"source"`
+	Backup *CardParams `stripe_field:"backup_source"`
+}
+
+func TestEncodeParamsReplaces(t *testing.T) {
+	mock := SourceMock{
+		Card:   &CardParams{Token: String("tok_123456789"), Number: String("4242424242424242")},
+		Backup: &CardParams{Number: String("4000056655665556")},
+	}
+	values, err := encodeParams(&mock)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values, url.Values{
+		"source":                {"tok_123456789"},
+		"backup_source[number]": {"4000056655665556"},
+	})
+}
+
+type FloatMock struct {
+	Exact   float64  `stripe_field:"exact"`
+	Percent *float64 `stripe_field:"percent" precision:"2" min:"0" max:"100"`
+}
+
+func TestEncodeParamsFloats(t *testing.T) {
+	values, err := encodeParams(&FloatMock{Exact: 12.345, Percent: Float64(12.34)})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values, url.Values{
+		"exact":   {"12.345"},
+		"percent": {"12.34"},
+	})
+
+	values, _ = encodeParams(&FloatMock{Exact: 1e-7, Percent: Float64(100)})
+	assert.Equal(t, values.Get("exact"), "0.0000001")
+	assert.Equal(t, values.Get("percent"), "100")
+}
+
+func TestEncodeParamsFloatErrors(t *testing.T) {
+	tests := []struct {
+		params  FloatMock
+		message string
+	}{
+		{FloatMock{Percent: Float64(12.345)}, "stripe: invalid percent: 12.345 has more than 2 decimal places"},
+		{FloatMock{Percent: Float64(-1)}, "stripe: invalid percent: -1 is less than 0"},
+		{FloatMock{Percent: Float64(100.5)}, "stripe: invalid percent: 100.5 is greater than 100"},
+		{FloatMock{Exact: math.NaN()}, "stripe: invalid exact: NaN is not a number"},
+		{FloatMock{Exact: math.Inf(1)}, "stripe: invalid exact: +Inf is not a number"},
+	}
+
+	for _, test := range tests {
+		_, err := encodeParams(&test.params)
+		_, ok := err.(*ParamError)
+		assert.T(t, ok, err)
+		assert.Equal(t, err.Error(), test.message)
+	}
+}
+
+func TestEncodeParamsInvalidTag(t *testing.T) {
+	_, err := encodeParams(&struct {
+		Percent float64 `stripe_field:"percent" precision:"two"`
+	}{1})
+	assert.Equal(t, err.Error(), `stripe: invalid precision tag "two" on Percent`)
+}
+
+func TestEncodeParamsUnsupported(t *testing.T) {
+	_, err := encodeParams(&struct {
+		Callback func() `stripe_field:"callback"`
+	}{func() {}})
+	assert.Equal(t, err.Error(), "stripe: cannot encode callback of type func()")
+}
+
+func TestEncodeParamsNil(t *testing.T) {
+	values, err := encodeParams((*ChargeParams)(nil))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values, url.Values{})
+}
+
+type TreeMock struct {
+	Name     string      `stripe_field:"name"`
+	Children []*TreeMock `stripe_field:"children"`
+}
+
+func TestEncodeParamsRecursiveType(t *testing.T) {
+	tree := TreeMock{Name: "root", Children: []*TreeMock{{Name: "leaf"}}}
+	values, err := encodeParams(&tree)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values, url.Values{
+		"name":              {"root"},
+		"children[0][name]": {"leaf"},
+	})
+}
+
+func TestEncoderForIsCached(t *testing.T) {
+	typ := reflect.TypeOf(&ChargeParams{})
+	a := encoderFor(typ, noOptions)
+	b := encoderFor(typ, noOptions)
+	assert.Equal(t, reflect.ValueOf(a).Pointer(), reflect.ValueOf(b).Pointer())
+}
+
+// reflectEncodeParams is the encoder that encodeParams replaced, which walks
+// params with reflection on every call. It is kept as the baseline of the
+// benchmarks below.
+func reflectEncodeParams(params interface{}) (url.Values, error) {
+	values := url.Values{}
+	err := reflectAppendValue(&values, "", reflect.ValueOf(params), false, false)
+	return values, err
+}
+
+func reflectAppendValue(values *url.Values, key string, v reflect.Value, set, opposite bool) error {
+	if !v.IsValid() || !v.CanInterface() {
+		return nil
+	}
+
+	if card, ok := v.Interface().(*CardParams); ok && card != nil && card.Token != nil {
+		if key == "" {
+			key = "card"
+		}
+		values.Add(key, *card.Token)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return reflectAppendValue(values, key, v.Elem(), true, opposite)
+
+	case reflect.Struct:
+		if v.Type() == timeType {
+			t := v.Interface().(time.Time)
+			if set || !t.IsZero() {
+				values.Add(key, strconv.FormatInt(t.Unix(), 10))
+			}
+			return nil
+		}
+		return reflectAppendStruct(values, key, v)
+
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+
+		for _, k := range keys {
+			err := reflectAppendValue(values, formKey(key, fmt.Sprint(k)), v.MapIndex(k), true, false)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			elem := v.Index(i)
+			k := key + "[]"
+			if kind := reflect.Indirect(elem).Kind(); kind == reflect.Struct || kind == reflect.Map {
+				k = formKey(key, strconv.Itoa(i))
+			}
+
+			if err := reflectAppendValue(values, k, elem, true, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if !set && v.IsZero() {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		values.Add(key, v.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		values.Add(key, strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		values.Add(key, strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		values.Add(key, strconv.FormatFloat(v.Float(), 'f', 2, 32))
+	case reflect.Bool:
+		values.Add(key, strconv.FormatBool(v.Bool() != opposite))
+	default:
+		return fmt.Errorf("stripe: cannot encode %s of type %s", key, v.Type())
+	}
+
+	return nil
+}
+
+func reflectAppendStruct(values *url.Values, key string, v reflect.Value) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		name := field.Tag.Get("stripe_field")
+		opposite, _ := strconv.ParseBool(field.Tag.Get("opposite"))
+
+		var err error
+		switch {
+		case name == "-":
+			continue
+		case name != "":
+			err = reflectAppendValue(values, formKey(key, name), v.Field(i), false, opposite)
+		case field.Anonymous:
+			err = reflectAppendValue(values, key, v.Field(i), false, opposite)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// benchmarkEncodeParams reports the time and allocations spent encoding params
+// with the compiled encoderFuncs, and with reflectEncodeParams, the encoder
+// they replaced.
+func benchmarkEncodeParams(b *testing.B, params interface{}) {
+	b.Run("compiled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			encodeParams(params)
+		}
+	})
+
+	b.Run("reflect", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			reflectEncodeParams(params)
+		}
+	})
+}
+
+func BenchmarkEncodeChargeParams(b *testing.B) {
+	benchmarkEncodeParams(b, &ChargeParams{
+		Amount:      Int(1000),
+		Currency:    String("usd"),
+		Customer:    String("cus_123456789"),
+		Description: String("Charge for apt@stripe.com"),
+		CardParams: &CardParams{
+			Number:   String("4242424242424242"),
+			ExpMonth: Int(12),
+			ExpYear:  Int(2020),
+			CVC:      String("123"),
+		},
+		Metadata: Metadata{"order_id": "6735"},
+	})
+}
+
+func BenchmarkEncodeCustomerParams(b *testing.B) {
+	benchmarkEncodeParams(b, &CustomerParams{
+		AccountBalance: Int(0),
+		Description:    String("A pretty awesome customer"),
+		Email:          String("apt@stripe.com"),
+		Plan:           String("gold"),
+		Quantity:       Int(2),
+		Metadata:       Metadata{"twitter": "@andrewpthorp"},
+	})
+}
+
+func BenchmarkEncodeSubscriptionParams(b *testing.B) {
+	benchmarkEncodeParams(b, &SubscriptionParams{
+		Plan:                  String("gold"),
+		Coupon:                String("FREE"),
+		DisableProrate:        Bool(true),
+		Quantity:              Int(2),
+		ApplicationFeePercent: Float64(10),
+	})
+}
@@ -0,0 +1,45 @@
+package stripe
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Doer sends an HTTP request and returns its response.
+type Doer func(req *http.Request) (*http.Response, error)
+
+// Interceptor wraps the delivery of every request a Client makes. It is given
+// the outgoing request and next, the rest of the chain. Calling next sends the
+// request on and returns the response, or the error, for the Interceptor to
+// inspect before returning it in turn:
+//
+//	func logger(req *http.Request, next stripe.Doer) (*http.Response, error) {
+//		start := time.Now()
+//		res, err := next(req)
+//		log.Println(req.Method, req.URL.Path, time.Since(start), err)
+//		return res, err
+//	}
+//
+// An Interceptor may also short-circuit the chain by returning a response (or
+// error) without calling next, to serve a request from a cache or a fake. One
+// that returns neither fails the request.
+//
+// Interceptors run once per attempt, so a retried request goes through them
+// again.
+type Interceptor func(req *http.Request, next Doer) (*http.Response, error)
+
+// errNoResponse is returned for a request an Interceptor returned neither a
+// response nor an error for.
+var errNoResponse = errors.New("stripe: interceptor returned no response")
+
+// chain returns a Doer that runs req through each of the interceptors, in
+// order, and then through final.
+func chain(interceptors []Interceptor, final Doer) Doer {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], final
+		final = func(req *http.Request) (*http.Response, error) {
+			return interceptor(req, next)
+		}
+	}
+	return final
+}
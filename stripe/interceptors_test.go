@@ -0,0 +1,116 @@
+package stripe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/bmizerany/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestInterceptorsRunInOrder(t *testing.T) {
+	setup()
+	defer teardown()
+	handleWithJSON("/charges/ch_123456789", "charges/charge.json")
+
+	var calls []string
+	record := func(name string) Interceptor {
+		return func(req *http.Request, next Doer) (*http.Response, error) {
+			calls = append(calls, name+" request")
+			res, err := next(req)
+			calls = append(calls, fmt.Sprintf("%s response %d", name, res.StatusCode))
+			return res, err
+		}
+	}
+	client = NewClientWith(nil, server.URL, "sk_abc123", WithInterceptors(record("first"), record("second")))
+
+	charge, err := client.Charges.Retrieve("ch_123456789")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, charge.Id, "ch_123456789")
+	assert.Equal(t, calls, []string{
+		"first request",
+		"second request",
+		"second response 200",
+		"first response 200",
+	})
+}
+
+func TestInterceptorsSeeBuiltInHeaders(t *testing.T) {
+	setup()
+	defer teardown()
+	serveMux.HandleFunc("/charges", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.Header.Get("X-Trace-Id"), "trace_123")
+		assert.Equal(t, r.Header.Get("User-Agent"), "custom")
+		fmt.Fprint(w, loadFixture("charges/charge.json"))
+	})
+
+	headers := func(req *http.Request, next Doer) (*http.Response, error) {
+		assert.Equal(t, req.Header.Get("Stripe-Version"), apiVersion)
+		assert.Equal(t, req.Header.Get("User-Agent"), userAgent)
+		assert.Equal(t, req.Header.Get("Idempotency-Key"), "key")
+		req.Header.Set("X-Trace-Id", "trace_123")
+		req.Header.Set("User-Agent", "custom")
+		return next(req)
+	}
+	client = NewClientWith(nil, server.URL, "sk_abc123", WithInterceptors(headers))
+
+	_, err := client.Charges.Create(new(ChargeParams), IdempotencyKey("key"))
+	assert.Equal(t, err, nil)
+}
+
+func TestInterceptorShortCircuit(t *testing.T) {
+	setup()
+	defer teardown()
+	serveMux.HandleFunc("/charges/ch_123456789", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not reach the server")
+	})
+
+	fake := func(req *http.Request, next Doer) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(strings.NewReader(loadFixture("charges/charge.json"))),
+		}, nil
+	}
+	client = NewClientWith(nil, server.URL, "sk_abc123", WithInterceptors(fake))
+
+	charge, err := client.Charges.Retrieve("ch_123456789")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, charge.Id, "ch_123456789")
+
+	// Errors from an Interceptor are returned as they are.
+	failure := errors.New("not today")
+	client = NewClientWith(nil, server.URL, "sk_abc123", WithInterceptors(func(req *http.Request, next Doer) (*http.Response, error) {
+		return nil, failure
+	}))
+	_, err = client.Charges.Retrieve("ch_123456789")
+	assert.Equal(t, err, failure)
+
+	// So is a missing response, rather than a panic.
+	client = NewClientWith(nil, server.URL, "sk_abc123", WithRetryPolicy(testRetryPolicy), WithInterceptors(func(req *http.Request, next Doer) (*http.Response, error) {
+		return nil, nil
+	}))
+	_, err = client.Charges.Retrieve("ch_123456789")
+	assert.Equal(t, err, errNoResponse)
+}
+
+func TestInterceptorsSeeEveryAttempt(t *testing.T) {
+	setup()
+	defer teardown()
+	handleFlaky("/flaky", 1, http.StatusServiceUnavailable)
+
+	var statuses []int
+	record := func(req *http.Request, next Doer) (*http.Response, error) {
+		res, err := next(req)
+		statuses = append(statuses, res.StatusCode)
+		return res, err
+	}
+	client = NewClientWith(nil, server.URL, "sk_abc123", WithRetryPolicy(testRetryPolicy), WithInterceptors(record))
+
+	var response struct{ Foo string }
+	client.get(context.Background(), "/flaky", nil, &response)
+	assert.Equal(t, statuses, []int{503, 200})
+}
@@ -1,21 +1,20 @@
 package stripe
 
-import (
-	"net/url"
-)
+import "context"
 
 type InvoiceItem struct {
-	Id          string   `json:"id"`
-	Object      string   `json:"object"`
-	Livemode    bool     `json:"livemode"`
-	Amount      int64    `json:"amount"`
-	Currency    string   `json:"currency"`
-	Customer    string   `json:"customer"`
-	Date        int64    `json:"date"`
-	Proration   bool     `json:"proration"`
-	Description string   `json:"description"`
-	Invoice     string   `json:"invoice"`
-	Metadata    Metadata `json:"metadata"`
+	APIResource
+	Id          string        `json:"id"`
+	Object      string        `json:"object"`
+	Livemode    bool          `json:"livemode"`
+	Amount      int64         `json:"amount"`
+	Currency    string        `json:"currency"`
+	Customer    CustomerField `json:"customer"`
+	Date        int64         `json:"date"`
+	Proration   bool          `json:"proration"`
+	Description string        `json:"description"`
+	Invoice     InvoiceField  `json:"invoice"`
+	Metadata    Metadata      `json:"metadata"`
 }
 
 type InvoiceItemListResponse struct {
@@ -23,6 +22,17 @@ type InvoiceItemListResponse struct {
 	Data []InvoiceItem `json:"data"`
 }
 
+// InvoiceItemIter is an Iter over InvoiceItems.
+type InvoiceItemIter struct {
+	*Iter
+}
+
+// InvoiceItem returns the InvoiceItem Next advanced to.
+func (it *InvoiceItemIter) InvoiceItem() *InvoiceItem {
+	i, _ := it.Current().(*InvoiceItem)
+	return i
+}
+
 type InvoiceItemClient struct {
 	client Client
 }
@@ -30,71 +40,135 @@ type InvoiceItemClient struct {
 // Create creates an invoice item.
 //
 // For more information: https://stripe.com/docs/api#create_invoice_item
-func (c *InvoiceItemClient) Create(params *InvoiceItemParams) (*InvoiceItem, error) {
+func (c *InvoiceItemClient) Create(params *InvoiceItemParams, opts ...RequestOption) (*InvoiceItem, error) {
+	return c.CreateContext(context.Background(), params, opts...)
+}
+
+// CreateContext is like Create, but uses ctx for the underlying request.
+func (c *InvoiceItemClient) CreateContext(ctx context.Context, params *InvoiceItemParams, opts ...RequestOption) (*InvoiceItem, error) {
 	item := InvoiceItem{}
-	values := url.Values{}
-	parseInvoiceItemParams(params, &values)
-	err := c.client.post("/invoiceitems", values, &item)
-	return &item, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.post(ctx, "/invoiceitems", values, &item, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
 }
 
 // Retrieve loads an invoice item.
 //
 // For more information: https://stripe.com/docs/api#retrieve_invoice_item
-func (c *InvoiceItemClient) Retrieve(id string) (*InvoiceItem, error) {
+func (c *InvoiceItemClient) Retrieve(id string, opts ...RequestOption) (*InvoiceItem, error) {
+	return c.RetrieveContext(context.Background(), id, opts...)
+}
+
+// RetrieveContext is like Retrieve, but uses ctx for the underlying request.
+func (c *InvoiceItemClient) RetrieveContext(ctx context.Context, id string, opts ...RequestOption) (*InvoiceItem, error) {
 	item := InvoiceItem{}
-	err := c.client.get("/invoiceitems/"+id, nil, &item)
-	return &item, err
+	err := c.client.get(ctx, "/invoiceitems/"+id, nil, &item, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
 }
 
 // Update updates an invoice item.
 //
 // For more information: https://stripe.com/docs/api#update_invoice_item
-func (c *InvoiceItemClient) Update(id string, params *InvoiceItemParams) (*InvoiceItem, error) {
+func (c *InvoiceItemClient) Update(id string, params *InvoiceItemParams, opts ...RequestOption) (*InvoiceItem, error) {
+	return c.UpdateContext(context.Background(), id, params, opts...)
+}
+
+// UpdateContext is like Update, but uses ctx for the underlying request.
+func (c *InvoiceItemClient) UpdateContext(ctx context.Context, id string, params *InvoiceItemParams, opts ...RequestOption) (*InvoiceItem, error) {
 	item := InvoiceItem{}
-	values := url.Values{}
-	parseInvoiceItemParams(params, &values)
-	err := c.client.post("/invoiceitems/"+id, values, &item)
-	return &item, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.post(ctx, "/invoiceitems/"+id, values, &item, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
 }
 
 // Delete deletes an invoice item.
 //
 // For more information: https://stripe.com/docs/api#delete_invoice_item
-func (c *InvoiceItemClient) Delete(id string) (*DeleteResponse, error) {
+func (c *InvoiceItemClient) Delete(id string, opts ...RequestOption) (*DeleteResponse, error) {
+	return c.DeleteContext(context.Background(), id, opts...)
+}
+
+// DeleteContext is like Delete, but uses ctx for the underlying request.
+func (c *InvoiceItemClient) DeleteContext(ctx context.Context, id string, opts ...RequestOption) (*DeleteResponse, error) {
 	response := DeleteResponse{}
-	err := c.client.delete("/invoiceitems/"+id, nil, &response)
-	return &response, err
+	err := c.client.delete(ctx, "/invoiceitems/"+id, nil, &response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
 }
 
-// All lists the first 10 invoice items. It calls AllWithFilters with a blank
-// Filters so all defaults are used.
+// All lists the first 10 invoice items. It calls AllWithParams with no
+// params so all defaults are used.
 //
 // For more information: https://stripe.com/docs/api#list_invoice_items
-func (c *InvoiceItemClient) All() (*InvoiceItemListResponse, error) {
-	return c.AllWithFilters(Filters{})
+func (c *InvoiceItemClient) All(opts ...RequestOption) (*InvoiceItemListResponse, error) {
+	return c.AllContext(context.Background(), opts...)
+}
+
+// AllContext is like All, but uses ctx for the underlying request.
+func (c *InvoiceItemClient) AllContext(ctx context.Context, opts ...RequestOption) (*InvoiceItemListResponse, error) {
+	return c.AllWithParamsContext(ctx, nil, opts...)
 }
 
-// AllWithFilters takes a Filters and applies all valid filters for the action.
+// AllWithParams lists the InvoiceItems matching params.
 //
 // For more information: https://stripe.com/docs/api#list_invoice_items
-func (c *InvoiceItemClient) AllWithFilters(filters Filters) (*InvoiceItemListResponse, error) {
+func (c *InvoiceItemClient) AllWithParams(params *InvoiceItemListParams, opts ...RequestOption) (*InvoiceItemListResponse, error) {
+	return c.AllWithParamsContext(context.Background(), params, opts...)
+}
+
+// AllWithParamsContext is like AllWithParams, but uses ctx for the
+// underlying request.
+func (c *InvoiceItemClient) AllWithParamsContext(ctx context.Context, params *InvoiceItemListParams, opts ...RequestOption) (*InvoiceItemListResponse, error) {
 	response := InvoiceItemListResponse{}
-	values := url.Values{}
-	addFiltersToValues([]string{"count", "offset", "customer"}, filters, &values)
-	err := c.client.get("/invoiceitems", values, &response)
-	return &response, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.get(ctx, "/invoiceitems", values, &response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
 }
 
-// parseInvoiceItemParams takes a pointer to a InvoiceItemParams and a pointer
-// to a url.Values. It iterates over everything in the InvoiceItemParams struct
-// and Adds what is there to the url.Values.
-func parseInvoiceItemParams(params *InvoiceItemParams, values *url.Values) {
+// Iter returns an iterator over the InvoiceItems matching params, fetching
+// pages as they are needed.
+//
+// For more information: https://stripe.com/docs/api#pagination
+func (c *InvoiceItemClient) Iter(params *InvoiceItemListParams, opts ...RequestOption) *InvoiceItemIter {
+	return c.IterContext(context.Background(), params, opts...)
+}
 
-	// Use parseMetaData from metadata.go to setup the metadata param
-	if params.Metadata != nil {
-		parseMetadata(params.Metadata, values)
+// IterContext is like Iter, but uses ctx for the underlying requests.
+func (c *InvoiceItemClient) IterContext(ctx context.Context, params *InvoiceItemListParams, opts ...RequestOption) *InvoiceItemIter {
+	p := InvoiceItemListParams{}
+	if params != nil {
+		p = *params
 	}
 
-	addParamsToValues(params, values)
+	return &InvoiceItemIter{newIter(ctx, p.ListParams, func(ctx context.Context, page ListParams) ([]interface{}, ListResponse, error) {
+		p.ListParams = page
+		list, err := c.AllWithParamsContext(ctx, &p, opts...)
+		if err != nil {
+			return nil, ListResponse{}, err
+		}
+		return pageOf(list.Data), list.ListResponse, nil
+	})}
 }
@@ -2,7 +2,6 @@ package stripe
 
 import (
 	"github.com/bmizerany/assert"
-	"net/url"
 	"strconv"
 	"testing"
 )
@@ -49,32 +48,31 @@ func TestInvoiceItemsAll(t *testing.T) {
 	assert.Equal(t, items.Data[0].Id, "ii_123456789")
 }
 
-func TestInvoiceItemsAllWithFilters(t *testing.T) {
+func TestInvoiceItemsAllWithParams(t *testing.T) {
 	setup()
 	defer teardown()
 	handleWithJSON("/invoiceitems", "invoice_items/invoice_items.json")
-	items, _ := client.InvoiceItems.AllWithFilters(Filters{})
+	items, _ := client.InvoiceItems.AllWithParams(&InvoiceItemListParams{})
 	assert.Equal(t, items.Count, 1)
 	assert.Equal(t, items.Data[0].Id, "ii_123456789")
 }
 
-func TestParseInvoiceItemParams(t *testing.T) {
+func TestEncodeInvoiceItemParams(t *testing.T) {
 	params := InvoiceItemParams{
-		Customer:    "cus_123456789",
-		Amount:      1000,
-		Currency:    "USD",
-		Invoice:     "in_123456789",
-		Description: "Description",
+		Customer:    String("cus_123456789"),
+		Amount:      Int(1000),
+		Currency:    String("USD"),
+		Invoice:     String("in_123456789"),
+		Description: String("Description"),
 		Metadata: Metadata{
 			"foo": "bar",
 		},
 	}
-	values := url.Values{}
-	parseInvoiceItemParams(&params, &values)
-	assert.Equal(t, values.Get("customer"), params.Customer)
-	assert.Equal(t, values.Get("amount"), strconv.Itoa(params.Amount))
-	assert.Equal(t, values.Get("currency"), params.Currency)
-	assert.Equal(t, values.Get("invoice"), params.Invoice)
-	assert.Equal(t, values.Get("description"), params.Description)
+	values, _ := encodeParams(&params)
+	assert.Equal(t, values.Get("customer"), *params.Customer)
+	assert.Equal(t, values.Get("amount"), strconv.Itoa(*params.Amount))
+	assert.Equal(t, values.Get("currency"), *params.Currency)
+	assert.Equal(t, values.Get("invoice"), *params.Invoice)
+	assert.Equal(t, values.Get("description"), *params.Description)
 	assert.Equal(t, values.Get("metadata[foo]"), params.Metadata["foo"])
 }
@@ -1,6 +1,7 @@
 package stripe
 
 import (
+	"context"
 	"net/url"
 )
 
@@ -19,6 +20,7 @@ type InvoiceLineItem struct {
 }
 
 type Invoice struct {
+	APIResource
 	Id                 string                       `json:"id"`
 	Object             string                       `json:"object"`
 	Livemode           bool                         `json:"livemode"`
@@ -27,7 +29,7 @@ type Invoice struct {
 	Attempted          bool                         `json:"attempted"`
 	Closed             bool                         `json:"closed"`
 	Currency           string                       `json:"currency"`
-	Customer           string                       `json:"customer"`
+	Customer           CustomerField                `json:"customer"`
 	Date               int64                        `json:"date"`
 	Paid               bool                         `json:"paid"`
 	PeriodEnd          int64                        `json:"period_end"`
@@ -36,7 +38,7 @@ type Invoice struct {
 	Subtotal           int64                        `json:"subtotal"`
 	Total              int64                        `json:"total"`
 	ApplicationFee     int64                        `json:"application_fee"`
-	Charge             string                       `json:"charge"`
+	Charge             ChargeField                  `json:"charge"`
 	Discount           *Discount                    `json:"discount"`
 	EndingBalance      int64                        `json:"ending_balance"`
 	NextPaymentAttempt int64                        `json:"next_payment_attempt"`
@@ -48,11 +50,33 @@ type InvoiceLineItemListResponse struct {
 	Data []InvoiceLineItem `json:"data"`
 }
 
+// InvoiceLineItemIter is an Iter over InvoiceLineItems.
+type InvoiceLineItemIter struct {
+	*Iter
+}
+
+// InvoiceLineItem returns the InvoiceLineItem Next advanced to.
+func (it *InvoiceLineItemIter) InvoiceLineItem() *InvoiceLineItem {
+	i, _ := it.Current().(*InvoiceLineItem)
+	return i
+}
+
 type InvoiceListResponse struct {
 	ListResponse
 	Data []Invoice `json:"data"`
 }
 
+// InvoiceIter is an Iter over Invoices.
+type InvoiceIter struct {
+	*Iter
+}
+
+// Invoice returns the Invoice Next advanced to.
+func (it *InvoiceIter) Invoice() *Invoice {
+	i, _ := it.Current().(*Invoice)
+	return i
+}
+
 type InvoiceClient struct {
 	client Client
 }
@@ -60,96 +84,218 @@ type InvoiceClient struct {
 // Create creates an invoice.
 //
 // For more information: https://stripe.com/docs/api#create_invoice
-func (c *InvoiceClient) Create(params *InvoiceParams) (*Invoice, error) {
+func (c *InvoiceClient) Create(params *InvoiceParams, opts ...RequestOption) (*Invoice, error) {
+	return c.CreateContext(context.Background(), params, opts...)
+}
+
+// CreateContext is like Create, but uses ctx for the underlying request.
+func (c *InvoiceClient) CreateContext(ctx context.Context, params *InvoiceParams, opts ...RequestOption) (*Invoice, error) {
 	invoice := Invoice{}
-	values := url.Values{}
-	parseInvoiceParams(params, &values)
-	err := c.client.post("/invoices", values, &invoice)
-	return &invoice, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.post(ctx, "/invoices", values, &invoice, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
 }
 
 // Retrieve loads an invoice.
 //
 // For more information: https://stripe.com/docs/api#retrieve_invoice
-func (c *InvoiceClient) Retrieve(id string) (*Invoice, error) {
+func (c *InvoiceClient) Retrieve(id string, opts ...RequestOption) (*Invoice, error) {
+	return c.RetrieveContext(context.Background(), id, opts...)
+}
+
+// RetrieveContext is like Retrieve, but uses ctx for the underlying request.
+func (c *InvoiceClient) RetrieveContext(ctx context.Context, id string, opts ...RequestOption) (*Invoice, error) {
 	invoice := Invoice{}
-	err := c.client.get("/invoices/"+id, nil, &invoice)
-	return &invoice, err
+	err := c.client.get(ctx, "/invoices/"+id, nil, &invoice, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
 }
 
 // Update updates an invoice.
 //
 // For more information: https://stripe.com/docs/api#update_invoice
-func (c *InvoiceClient) Update(id string, params *InvoiceParams) (*Invoice, error) {
+func (c *InvoiceClient) Update(id string, params *InvoiceParams, opts ...RequestOption) (*Invoice, error) {
+	return c.UpdateContext(context.Background(), id, params, opts...)
+}
+
+// UpdateContext is like Update, but uses ctx for the underlying request.
+func (c *InvoiceClient) UpdateContext(ctx context.Context, id string, params *InvoiceParams, opts ...RequestOption) (*Invoice, error) {
 	invoice := Invoice{}
-	values := url.Values{}
-	parseInvoiceParams(params, &values)
-	err := c.client.post("/invoices/"+id, values, &invoice)
-	return &invoice, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.post(ctx, "/invoices/"+id, values, &invoice, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
 }
 
-// All lists the first 10 invoice. It calls AllWithFilters with a blank Filters
+// All lists the first 10 invoice. It calls AllWithParams with no params
 // so all defaults are used.
 //
 // For more information: https://stripe.com/docs/api#list_invoices
-func (c *InvoiceClient) All() (*InvoiceListResponse, error) {
-	return c.AllWithFilters(Filters{})
+func (c *InvoiceClient) All(opts ...RequestOption) (*InvoiceListResponse, error) {
+	return c.AllContext(context.Background(), opts...)
 }
 
-// AllWithFilters takes a Filters and applies all valid filters for the action.
+// AllContext is like All, but uses ctx for the underlying request.
+func (c *InvoiceClient) AllContext(ctx context.Context, opts ...RequestOption) (*InvoiceListResponse, error) {
+	return c.AllWithParamsContext(ctx, nil, opts...)
+}
+
+// AllWithParams lists the Invoices matching params.
 //
 // For more information: https://stripe.com/docs/api#list_cards
-func (c *InvoiceClient) AllWithFilters(filters Filters) (*InvoiceListResponse, error) {
+func (c *InvoiceClient) AllWithParams(params *InvoiceListParams, opts ...RequestOption) (*InvoiceListResponse, error) {
+	return c.AllWithParamsContext(context.Background(), params, opts...)
+}
+
+// AllWithParamsContext is like AllWithParams, but uses ctx for the
+// underlying request.
+func (c *InvoiceClient) AllWithParamsContext(ctx context.Context, params *InvoiceListParams, opts ...RequestOption) (*InvoiceListResponse, error) {
 	response := InvoiceListResponse{}
-	values := url.Values{}
-	addFiltersToValues([]string{"count", "offset", "customer"}, filters, &values)
-	err := c.client.get("/invoices", values, &response)
-	return &response, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.get(ctx, "/invoices", values, &response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Iter returns an iterator over the Invoices matching params, fetching pages
+// as they are needed.
+//
+// For more information: https://stripe.com/docs/api#pagination
+func (c *InvoiceClient) Iter(params *InvoiceListParams, opts ...RequestOption) *InvoiceIter {
+	return c.IterContext(context.Background(), params, opts...)
+}
+
+// IterContext is like Iter, but uses ctx for the underlying requests.
+func (c *InvoiceClient) IterContext(ctx context.Context, params *InvoiceListParams, opts ...RequestOption) *InvoiceIter {
+	p := InvoiceListParams{}
+	if params != nil {
+		p = *params
+	}
+
+	return &InvoiceIter{newIter(ctx, p.ListParams, func(ctx context.Context, page ListParams) ([]interface{}, ListResponse, error) {
+		p.ListParams = page
+		list, err := c.AllWithParamsContext(ctx, &p, opts...)
+		if err != nil {
+			return nil, ListResponse{}, err
+		}
+		return pageOf(list.Data), list.ListResponse, nil
+	})}
 }
 
 // Upcoming loads an upcoming invoice for a customer.
 //
 // For more information: https://stripe.com/docs/api#retrieve_customer_invoice
-func (c *InvoiceClient) RetrieveUpcoming(customerId string) (*Invoice, error) {
+func (c *InvoiceClient) RetrieveUpcoming(customerId string, opts ...RequestOption) (*Invoice, error) {
+	return c.RetrieveUpcomingContext(context.Background(), customerId, opts...)
+}
+
+// RetrieveUpcomingContext is like RetrieveUpcoming, but uses ctx for the
+// underlying request.
+func (c *InvoiceClient) RetrieveUpcomingContext(ctx context.Context, customerId string, opts ...RequestOption) (*Invoice, error) {
 	invoice := Invoice{}
 	params := url.Values{
 		"customer": {customerId},
 	}
-	err := c.client.get("/invoices/upcoming", params, &invoice)
-	return &invoice, err
+	err := c.client.get(ctx, "/invoices/upcoming", params, &invoice, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
 }
 
 // Pay pays an invoice.
 //
 // For more information: https://stripe.com/docs/api#pay_invoice
-func (c *InvoiceClient) Pay(id string) (*Invoice, error) {
+func (c *InvoiceClient) Pay(id string, opts ...RequestOption) (*Invoice, error) {
+	return c.PayContext(context.Background(), id, opts...)
+}
+
+// PayContext is like Pay, but uses ctx for the underlying request.
+func (c *InvoiceClient) PayContext(ctx context.Context, id string, opts ...RequestOption) (*Invoice, error) {
 	invoice := Invoice{}
-	err := c.client.post("/invoices/"+id+"/pay", nil, &invoice)
-	return &invoice, err
+	err := c.client.post(ctx, "/invoices/"+id+"/pay", nil, &invoice, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
 }
 
 // RetrieveLines loads the first 10 line items for an invoice. It calls
-// RetrieveLinesWithFilters with a blank Filters, so all defaults are used.
+// RetrieveLinesWithParams with no params, so all defaults are used.
 //
 // For more information: https://stripe.com/docs/api#invoice_lines
-func (c *InvoiceClient) RetrieveLines(invoiceId string) (*InvoiceLineItemListResponse, error) {
-	return c.RetrieveLinesWithFilters(invoiceId, Filters{})
+func (c *InvoiceClient) RetrieveLines(invoiceId string, opts ...RequestOption) (*InvoiceLineItemListResponse, error) {
+	return c.RetrieveLinesContext(context.Background(), invoiceId, opts...)
+}
+
+// RetrieveLinesContext is like RetrieveLines, but uses ctx for the underlying
+// request.
+func (c *InvoiceClient) RetrieveLinesContext(ctx context.Context, invoiceId string, opts ...RequestOption) (*InvoiceLineItemListResponse, error) {
+	return c.RetrieveLinesWithParamsContext(ctx, invoiceId, nil, opts...)
 }
 
-// RetrieveLinesWithFilters takes a Filters and applies all valid filters for the action.
+// RetrieveLinesWithParams lists the InvoiceLineItems of the Invoice matching
+// params.
 //
 // For more information: https://stripe.com/docs/api#invoice_lines
-func (c *InvoiceClient) RetrieveLinesWithFilters(invoiceId string, filters Filters) (*InvoiceLineItemListResponse, error) {
+func (c *InvoiceClient) RetrieveLinesWithParams(invoiceId string, params *InvoiceLineListParams, opts ...RequestOption) (*InvoiceLineItemListResponse, error) {
+	return c.RetrieveLinesWithParamsContext(context.Background(), invoiceId, params, opts...)
+}
+
+// RetrieveLinesWithParamsContext is like RetrieveLinesWithParams, but uses
+// ctx for the underlying request.
+func (c *InvoiceClient) RetrieveLinesWithParamsContext(ctx context.Context, invoiceId string, params *InvoiceLineListParams, opts ...RequestOption) (*InvoiceLineItemListResponse, error) {
 	response := InvoiceLineItemListResponse{}
-	values := url.Values{}
-	addFiltersToValues([]string{"count", "offset", "customer"}, filters, &values)
-	err := c.client.get("/invoices/"+invoiceId+"/lines", values, &response)
-	return &response, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.get(ctx, "/invoices/"+invoiceId+"/lines", values, &response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// LinesIter returns an iterator over the InvoiceLineItems of the Invoice
+// matching params, fetching pages as they are needed.
+//
+// For more information: https://stripe.com/docs/api#pagination
+func (c *InvoiceClient) LinesIter(invoiceId string, params *InvoiceLineListParams, opts ...RequestOption) *InvoiceLineItemIter {
+	return c.LinesIterContext(context.Background(), invoiceId, params, opts...)
 }
 
-// parseInvoiceParams takes a pointer to an InvoiceParams and a pointer to a
-// url.Values. It iterates over everything in the InvoiceParams struct and Adds
-// what is there to the url.Values.
-func parseInvoiceParams(params *InvoiceParams, values *url.Values) {
-	addParamsToValues(params, values)
+// LinesIterContext is like LinesIter, but uses ctx for the underlying requests.
+func (c *InvoiceClient) LinesIterContext(ctx context.Context, invoiceId string, params *InvoiceLineListParams, opts ...RequestOption) *InvoiceLineItemIter {
+	p := InvoiceLineListParams{}
+	if params != nil {
+		p = *params
+	}
+
+	return &InvoiceLineItemIter{newIter(ctx, p.ListParams, func(ctx context.Context, page ListParams) ([]interface{}, ListResponse, error) {
+		p.ListParams = page
+		list, err := c.RetrieveLinesWithParamsContext(ctx, invoiceId, &p, opts...)
+		if err != nil {
+			return nil, ListResponse{}, err
+		}
+		return pageOf(list.Data), list.ListResponse, nil
+	})}
 }
@@ -2,7 +2,6 @@ package stripe
 
 import (
 	"github.com/bmizerany/assert"
-	"net/url"
 	"strconv"
 	"testing"
 )
@@ -41,11 +40,11 @@ func TestInvoicesAll(t *testing.T) {
 	assert.Equal(t, invoices.Data[0].Id, "in_123456789")
 }
 
-func TestInvoicesAllWithFilters(t *testing.T) {
+func TestInvoicesAllWithParams(t *testing.T) {
 	setup()
 	defer teardown()
 	handleWithJSON("/invoices", "invoices/invoices.json")
-	invoices, _ := client.Invoices.AllWithFilters(Filters{})
+	invoices, _ := client.Invoices.AllWithParams(&InvoiceListParams{})
 	assert.Equal(t, invoices.Count, 1)
 	assert.Equal(t, invoices.Data[0].Id, "in_123456789")
 }
@@ -75,24 +74,23 @@ func TestInvoicesRetrieveLines(t *testing.T) {
 	assert.Equal(t, invoices.Data[0].Id, "ii_123456789")
 }
 
-func TestInvoicesRetrieveLinesWithFilters(t *testing.T) {
+func TestInvoicesRetrieveLinesWithParams(t *testing.T) {
 	setup()
 	defer teardown()
 	handleWithJSON("/invoices/in_123456789/lines", "invoices/lines.json")
-	invoices, _ := client.Invoices.RetrieveLinesWithFilters("in_123456789", Filters{})
+	invoices, _ := client.Invoices.RetrieveLinesWithParams("in_123456789", &InvoiceLineListParams{})
 	assert.Equal(t, invoices.Count, 1)
 	assert.Equal(t, invoices.Data[0].Id, "ii_123456789")
 }
 
-func TestParseInvoiceParams(t *testing.T) {
+func TestEncodeInvoiceParams(t *testing.T) {
 	params := InvoiceParams{
-		Customer:       "cus_123456789",
-		ApplicationFee: 2500,
-		Closed:         true,
+		Customer:       String("cus_123456789"),
+		ApplicationFee: Int(2500),
+		Closed:         Bool(true),
 	}
-	values := url.Values{}
-	parseInvoiceParams(&params, &values)
-	assert.Equal(t, values.Get("customer"), params.Customer)
-	assert.Equal(t, values.Get("application_fee"), strconv.Itoa(params.ApplicationFee))
-	assert.Equal(t, values.Get("closed"), strconv.FormatBool(params.Closed))
+	values, _ := encodeParams(&params)
+	assert.Equal(t, values.Get("customer"), *params.Customer)
+	assert.Equal(t, values.Get("application_fee"), strconv.Itoa(*params.ApplicationFee))
+	assert.Equal(t, values.Get("closed"), strconv.FormatBool(*params.Closed))
 }
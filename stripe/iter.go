@@ -0,0 +1,109 @@
+package stripe
+
+import (
+	"context"
+	"reflect"
+)
+
+// pageQuery fetches a single page of a list, with the given ListParams, and
+// returns pointers to its items along with the ListResponse.
+type pageQuery func(ctx context.Context, page ListParams) ([]interface{}, ListResponse, error)
+
+// Iter pages through a list transparently, using the StartingAfter cursor, or
+// EndingBefore if it was set in the ListParams to walk the list backwards. It
+// backs the typed iterators returned by each resource client:
+//
+//	i := client.Charges.Iter(&stripe.ChargeListParams{Customer: "cus_123"})
+//	for i.Next() {
+//		charge := i.Charge()
+//	}
+//	if err := i.Err(); err != nil {
+//		// The page that failed to load, and everything after it, were skipped.
+//	}
+//
+// Breaking out of the loop stops the iteration; no further pages are fetched.
+type Iter struct {
+	ctx     context.Context
+	query   pageQuery
+	params  ListParams
+	page    []interface{}
+	current interface{}
+	err     error
+	more    bool
+	seen    int
+}
+
+// newIter returns an Iter that fetches pages with query, starting from
+// params. The first page is only fetched on the first call to Next. The items
+// skipped by an Offset count as seen.
+func newIter(ctx context.Context, params ListParams, query pageQuery) *Iter {
+	return &Iter{ctx: ctx, query: query, params: params, more: true, seen: params.Offset}
+}
+
+// Next advances to the next item, fetching the next page if needed. It returns
+// false when there are no more items, or when a page failed to load, in which
+// case Err returns the error.
+func (it *Iter) Next() bool {
+	if len(it.page) == 0 && it.more && it.err == nil {
+		it.fetch()
+	}
+
+	if len(it.page) == 0 {
+		it.current = nil
+		return false
+	}
+
+	it.current, it.page = it.page[0], it.page[1:]
+	return true
+}
+
+// Current returns the item Next advanced to.
+func (it *Iter) Current() interface{} {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *Iter) Err() error {
+	return it.err
+}
+
+// fetch loads the next page, and moves the cursor past it.
+func (it *Iter) fetch() {
+	page, list, err := it.query(it.ctx, it.params)
+	if err != nil {
+		it.err = err
+		return
+	}
+
+	it.page = page
+	it.seen += len(page)
+
+	// Versions before 2014-03-28 report the total Count rather than HasMore.
+	it.more = len(page) > 0 && (list.HasMore || list.Count > it.seen)
+	if !it.more {
+		return
+	}
+
+	// The cursor already accounts for the Offset of the first page.
+	it.params.Offset = 0
+	if it.params.EndingBefore != "" {
+		it.params.EndingBefore = itemId(page[0])
+	} else {
+		it.params.StartingAfter = itemId(page[len(page)-1])
+	}
+}
+
+// itemId returns the Id field of the resource item points to.
+func itemId(item interface{}) string {
+	return reflect.Indirect(reflect.ValueOf(item)).FieldByName("Id").String()
+}
+
+// pageOf returns pointers to the items of data, a slice of resources.
+func pageOf(data interface{}) []interface{} {
+	v := reflect.ValueOf(data)
+	page := make([]interface{}, v.Len())
+	for i := range page {
+		page[i] = v.Index(i).Addr().Interface()
+	}
+	return page
+}
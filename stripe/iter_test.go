@@ -0,0 +1,197 @@
+package stripe
+
+import (
+	"context"
+	"fmt"
+	"github.com/bmizerany/assert"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// handlePaged serves ids, newest first, as a list of customers at path, paged
+// with limit, starting_after and ending_before. It returns a pointer to the
+// cursor of every request it received.
+func handlePaged(path string, ids []string) *[]string {
+	var cursors []string
+	serveMux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		cursors = append(cursors, q.Get("starting_after")+q.Get("ending_before"))
+
+		start, end := 0, len(ids)
+		for i, id := range ids {
+			if id == q.Get("starting_after") {
+				start = i + 1
+			}
+			if id == q.Get("ending_before") {
+				end = i
+			}
+		}
+
+		limit := 2
+		more := end-start > limit
+		if more && q.Get("ending_before") != "" {
+			start = end - limit
+		} else if more {
+			end = start + limit
+		}
+
+		data := []string{}
+		for _, id := range ids[start:end] {
+			data = append(data, fmt.Sprintf(`{"id": %q, "object": "customer"}`, id))
+		}
+		fmt.Fprintf(w, `{"object": "list", "has_more": %t, "data": [%s]}`, more, strings.Join(data, ","))
+	})
+	return &cursors
+}
+
+func TestIter(t *testing.T) {
+	setup()
+	defer teardown()
+	cursors := handlePaged("/customers", []string{"cus_5", "cus_4", "cus_3", "cus_2", "cus_1"})
+
+	var ids []string
+	params := &CustomerListParams{ListParams: ListParams{Limit: 2}}
+	i := client.Customers.Iter(params)
+	for i.Next() {
+		ids = append(ids, i.Customer().Id)
+	}
+	assert.Equal(t, i.Err(), nil)
+	assert.Equal(t, ids, []string{"cus_5", "cus_4", "cus_3", "cus_2", "cus_1"})
+	assert.Equal(t, *cursors, []string{"", "cus_4", "cus_2"})
+	assert.Equal(t, i.Customer(), (*Customer)(nil))
+
+	// The params given are left untouched.
+	assert.Equal(t, params.StartingAfter, "")
+}
+
+func TestIterEndingBefore(t *testing.T) {
+	setup()
+	defer teardown()
+	cursors := handlePaged("/customers", []string{"cus_5", "cus_4", "cus_3", "cus_2", "cus_1"})
+
+	var ids []string
+	i := client.Customers.Iter(&CustomerListParams{ListParams: ListParams{EndingBefore: "cus_1"}})
+	for i.Next() {
+		ids = append(ids, i.Customer().Id)
+	}
+	assert.Equal(t, i.Err(), nil)
+	assert.Equal(t, ids, []string{"cus_3", "cus_2", "cus_5", "cus_4"})
+	assert.Equal(t, *cursors, []string{"cus_1", "cus_3"})
+}
+
+func TestIterStopsEarly(t *testing.T) {
+	setup()
+	defer teardown()
+	cursors := handlePaged("/customers", []string{"cus_5", "cus_4", "cus_3", "cus_2", "cus_1"})
+
+	i := client.Customers.Iter(nil)
+	for i.Next() {
+		if i.Customer().Id == "cus_4" {
+			break
+		}
+	}
+	assert.Equal(t, len(*cursors), 1)
+}
+
+func TestIterError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	requests := 0
+	serveMux.HandleFunc("/charges", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests > 1 {
+			http.Error(w, loadFixture("errors/api_error.json"), http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, `{"object": "list", "has_more": true, "data": [{"id": "ch_1"}, {"id": "ch_2"}]}`)
+	})
+
+	var ids []string
+	i := client.Charges.Iter(nil)
+	for i.Next() {
+		ids = append(ids, i.Charge().Id)
+	}
+	assert.Equal(t, ids, []string{"ch_1", "ch_2"})
+	_, ok := i.Err().(*APIError)
+	assert.T(t, ok, i.Err())
+
+	// Once failed, the iterator stays done.
+	assert.T(t, !i.Next())
+	assert.Equal(t, requests, 2)
+}
+
+func TestIterContextCanceled(t *testing.T) {
+	setup()
+	defer teardown()
+	handlePaged("/events", []string{"evt_1"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	i := client.Events.IterContext(ctx, nil)
+	assert.T(t, !i.Next())
+	assert.Equal(t, i.Err(), context.Canceled)
+}
+
+func TestIterCount(t *testing.T) {
+	setup()
+	defer teardown()
+
+	// Versions before 2014-03-28 report a total count instead of has_more.
+	var cursors []string
+	serveMux.HandleFunc("/customers/cus_123456789/cards", func(w http.ResponseWriter, r *http.Request) {
+		cursors = append(cursors, r.URL.Query().Get("starting_after"))
+		if len(cursors) == 1 {
+			fmt.Fprint(w, `{"object": "list", "count": 3, "data": [{"id": "card_1"}, {"id": "card_2"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"object": "list", "count": 3, "data": [{"id": "card_3"}]}`)
+	})
+
+	var ids []string
+	i := client.Cards.Iter("cus_123456789", nil)
+	for i.Next() {
+		ids = append(ids, i.Card().Id)
+	}
+	assert.Equal(t, i.Err(), nil)
+	assert.Equal(t, ids, []string{"card_1", "card_2", "card_3"})
+	assert.Equal(t, cursors, []string{"", "card_2"})
+}
+
+func TestIterBalanceHistory(t *testing.T) {
+	setup()
+	defer teardown()
+	serveMux.HandleFunc("/balance/history", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Query().Get("type"), "charge")
+		fmt.Fprint(w, loadFixture("balances/balance_history.json"))
+	})
+
+	i := client.Balance.HistoryIter(&BalanceHistoryParams{Type: "charge"})
+	assert.T(t, i.Next())
+	assert.NotEqual(t, i.BalanceTransaction().Id, "")
+}
+
+func TestIterOffset(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var queries []string
+	serveMux.HandleFunc("/customers", func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		if len(queries) == 1 {
+			fmt.Fprint(w, `{"object": "list", "has_more": true, "data": [{"id": "cus_a"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"object": "list", "has_more": false, "data": [{"id": "cus_b"}]}`)
+	})
+
+	var ids []string
+	i := client.Customers.Iter(&CustomerListParams{ListParams: ListParams{Offset: 20, Limit: 1}})
+	for i.Next() {
+		ids = append(ids, i.Customer().Id)
+	}
+	assert.Equal(t, i.Err(), nil)
+	assert.Equal(t, ids, []string{"cus_a", "cus_b"})
+	assert.Equal(t, queries, []string{"limit=1&offset=20", "limit=1&starting_after=cus_a"})
+}
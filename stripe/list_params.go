@@ -0,0 +1,25 @@
+package stripe
+
+import "time"
+
+// ListParams hold the parameters shared by every endpoint that lists a
+// collection. It is embedded in the list parameters of each endpoint, such as
+// ChargeListParams.
+//
+// For more information: https://stripe.com/docs/api#pagination
+type ListParams struct {
+	Count         int    `stripe_field:"count"`
+	Offset        int    `stripe_field:"offset"`
+	Limit         int    `stripe_field:"limit"`
+	StartingAfter string `stripe_field:"starting_after"`
+	EndingBefore  string `stripe_field:"ending_before"`
+}
+
+// RangeQuery restricts a timestamp, such as created, to a range. Bounds that
+// are the zero time are left out.
+type RangeQuery struct {
+	GT  time.Time `stripe_field:"gt"`
+	GTE time.Time `stripe_field:"gte"`
+	LT  time.Time `stripe_field:"lt"`
+	LTE time.Time `stripe_field:"lte"`
+}
@@ -0,0 +1,33 @@
+package stripe
+
+import (
+	"github.com/bmizerany/assert"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestEncodeListParams(t *testing.T) {
+	params := &BalanceHistoryParams{
+		ListParams:  ListParams{Limit: 3, StartingAfter: "txn_123456789"},
+		Created:     &RangeQuery{GTE: time.Unix(1400000000, 0), LT: time.Unix(1500000000, 0)},
+		AvailableOn: &RangeQuery{GT: time.Date(2014, time.May, 1, 0, 0, 0, 0, time.UTC)},
+		Type:        "charge",
+	}
+	values, err := encodeParams(params)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values, url.Values{
+		"limit":            {"3"},
+		"starting_after":   {"txn_123456789"},
+		"created[gte]":     {"1400000000"},
+		"created[lt]":      {"1500000000"},
+		"available_on[gt]": {"1398902400"},
+		"type":             {"charge"},
+	})
+}
+
+func TestEncodeListParamsNil(t *testing.T) {
+	values, err := encodeParams((*ChargeListParams)(nil))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, values, url.Values{})
+}
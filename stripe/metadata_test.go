@@ -2,15 +2,15 @@ package stripe
 
 import (
 	"github.com/bmizerany/assert"
-	"net/url"
 	"testing"
 )
 
-func TestParseMetadata(t *testing.T) {
-	meta := Metadata{
-		"foo": "bar",
-	}
-	values := url.Values{}
-	parseMetadata(meta, &values)
+func TestEncodeMetadata(t *testing.T) {
+	values, _ := encodeParams(&ChargeParams{Metadata: Metadata{"foo": "bar"}})
 	assert.Equal(t, values.Get("metadata[foo]"), "bar")
 }
+
+func TestEncodeMetadataDelete(t *testing.T) {
+	values, _ := encodeParams(&ChargeParams{Metadata: Metadata{"foo": ""}})
+	assert.Equal(t, values["metadata[foo]"], []string{""})
+}
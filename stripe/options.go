@@ -0,0 +1,90 @@
+package stripe
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// RequestOption configures a single API call. RequestOptions are passed as the
+// trailing arguments of any resource client method:
+//
+//	client.Charges.Create(&params, stripe.IdempotencyKey("order-1234"))
+type RequestOption func(*requestOptions)
+
+// requestOptions holds everything the RequestOptions of a call have set.
+type requestOptions struct {
+	idempotencyKey string
+	stripeAccount  string
+	apiVersion     string
+	expand         []string
+}
+
+// newRequestOptions applies opts, in order, to a blank requestOptions.
+func newRequestOptions(opts []RequestOption) *requestOptions {
+	o := &requestOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// IdempotencyKey sends key as the Idempotency-Key header of the request. Stripe
+// guarantees that requests sharing a key are only acted on once, so a POST that
+// failed on the network can be sent again without, for instance, charging a
+// customer twice.
+//
+// For more information: https://stripe.com/docs/api#idempotent_requests
+func IdempotencyKey(key string) RequestOption {
+	return func(o *requestOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// StripeAccount makes the request on behalf of the connected account with the
+// given id, by sending it as the Stripe-Account header. It takes precedence
+// over the account of a Client returned by ForAccount.
+//
+// For more information: https://stripe.com/docs/connect/authentication
+func StripeAccount(id string) RequestOption {
+	return func(o *requestOptions) {
+		o.stripeAccount = id
+	}
+}
+
+// APIVersion sends the request with the given API version, instead of the
+// Client's, as the Stripe-Version header. It allows trying a new version out
+// one request at a time.
+//
+// For more information: https://stripe.com/docs/api#versioning
+func APIVersion(version string) RequestOption {
+	return func(o *requestOptions) {
+		o.apiVersion = version
+	}
+}
+
+// Expand asks the API to return the whole resource for each of the given
+// fields, instead of its ID. Nested fields are separated by dots, as in
+// "invoice.customer". Expanded resources are decoded into fields such as
+// CustomerField.
+//
+// For more information: https://stripe.com/docs/api#expand
+func Expand(fields ...string) RequestOption {
+	return func(o *requestOptions) {
+		o.expand = append(o.expand, fields...)
+	}
+}
+
+// newIdempotencyKey returns a random version 4 UUID, to be used as an
+// Idempotency-Key.
+func newIdempotencyKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	h := hex.EncodeToString(b)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:], nil
+}
@@ -0,0 +1,87 @@
+package stripe
+
+import (
+	"fmt"
+	"github.com/bmizerany/assert"
+	"net/http"
+	"regexp"
+	"testing"
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var keys []string
+	serveMux.HandleFunc("/charges", func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		fmt.Fprint(w, loadFixture("charges/charge.json"))
+	})
+	serveMux.HandleFunc("/transfers", func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		fmt.Fprint(w, loadFixture("transfers/transfer.json"))
+	})
+
+	client.Charges.Create(new(ChargeParams), IdempotencyKey("charge-key"))
+	client.Transfers.Create(new(TransferParams), IdempotencyKey("transfer-key"))
+	client.Charges.Create(new(ChargeParams))
+	assert.Equal(t, keys, []string{"charge-key", "transfer-key", ""})
+}
+
+func TestAutoIdempotencyKeys(t *testing.T) {
+	setup()
+	defer teardown()
+	client = NewClientWith(nil, server.URL, "sk_abc123", WithAutoIdempotencyKeys(), WithRetryPolicy(testRetryPolicy))
+
+	var keys []string
+	serveMux.HandleFunc("/charges", func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) == 1 {
+			http.Error(w, loadFixture("errors/invalid_request_error.json"), http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, loadFixture("charges/charge.json"))
+	})
+	serveMux.HandleFunc("/charges/ch_123456789", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.Header.Get("Idempotency-Key"), "")
+		fmt.Fprint(w, loadFixture("charges/charge.json"))
+	})
+
+	// The generated key is shared by the retry.
+	charge, err := client.Charges.Create(new(ChargeParams))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, charge.Id, "ch_123456789")
+	assert.Equal(t, len(keys), 2)
+	assert.NotEqual(t, keys[0], "")
+	assert.Equal(t, keys[0], keys[1])
+
+	// Every call gets a new key, unless one is given.
+	client.Charges.Create(new(ChargeParams))
+	client.Charges.Create(new(ChargeParams), IdempotencyKey("given"))
+	assert.NotEqual(t, keys[2], keys[0])
+	assert.Equal(t, keys[3], "given")
+
+	// GETs are idempotent already.
+	client.Charges.Retrieve("ch_123456789")
+}
+
+func TestNewIdempotencyKey(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	a, _ := newIdempotencyKey()
+	b, _ := newIdempotencyKey()
+	assert.T(t, uuid.MatchString(a), a)
+	assert.NotEqual(t, a, b)
+}
+
+func TestStripeAccount(t *testing.T) {
+	setup()
+	defer teardown()
+	serveMux.HandleFunc("/customers/cus_123456789", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.Header.Get("Stripe-Account"), "acct_123456789")
+		fmt.Fprint(w, loadFixture("customers/customer.json"))
+	})
+
+	customer, err := client.Customers.Retrieve("cus_123456789", StripeAccount("acct_123456789"))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, customer.Id, "cus_123456789")
+}
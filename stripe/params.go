@@ -1,143 +1,242 @@
 package stripe
 
+// ApplicationFeeListParams hold all of the parameters used for listing
+// ApplicationFees.
+type ApplicationFeeListParams struct {
+	ListParams
+	Created *RangeQuery `stripe_field:"created"`
+	Charge  string      `stripe_field:"charge"`
+}
+
+// BalanceHistoryParams hold all of the parameters used for listing the
+// BalanceTransactions in the balance history.
+type BalanceHistoryParams struct {
+	ListParams
+	Created     *RangeQuery `stripe_field:"created"`
+	AvailableOn *RangeQuery `stripe_field:"available_on"`
+	Currency    string      `stripe_field:"currency"`
+	Source      string      `stripe_field:"source"`
+	Transfer    string      `stripe_field:"transfer"`
+	Type        string      `stripe_field:"type"`
+}
+
 // BankAccountParams hold all of the parameters used for creating and updating
 // BankAccounts.
 type BankAccountParams struct {
-	Country       string `stripe_field:"bank_account[country]"`
-	RoutingNumber string `stripe_field:"bank_account[routing_number]"`
-	AccountNumber string `stripe_field:"bank_account[account_number]"`
+	Country       *string `stripe_field:"country"`
+	RoutingNumber *string `stripe_field:"routing_number"`
+	AccountNumber *string `stripe_field:"account_number"`
+}
+
+// CardListParams hold all of the parameters used for listing Cards.
+type CardListParams struct {
+	ListParams
 }
 
 // CardParams hold all of the parameters used for creating and updating Cards.
 type CardParams struct {
-	Number         string
-	ExpMonth       int
-	ExpYear        int
-	CVC            string
-	Name           string
-	AddressLine1   string
-	AddressLine2   string
-	AddressCity    string
-	AddressZip     string
-	AddressState   string
-	AddressCountry string
-	Token          string
+	Number         *string `stripe_field:"number"`
+	ExpMonth       *int    `stripe_field:"exp_month"`
+	ExpYear        *int    `stripe_field:"exp_year"`
+	CVC            *string `stripe_field:"cvc"`
+	Name           *string `stripe_field:"name"`
+	AddressLine1   *string `stripe_field:"address_line1"`
+	AddressLine2   *string `stripe_field:"address_line2"`
+	AddressCity    *string `stripe_field:"address_city"`
+	AddressZip     *string `stripe_field:"address_zip"`
+	AddressState   *string `stripe_field:"address_state"`
+	AddressCountry *string `stripe_field:"address_country"`
+	Token          *string `stripe_field:"card" replaces:"true"`
+}
+
+// ChargeListParams hold all of the parameters used for listing Charges.
+type ChargeListParams struct {
+	ListParams
+	Created  *RangeQuery `stripe_field:"created"`
+	Customer string      `stripe_field:"customer"`
 }
 
 // ChargeParams hold all of the parameters used for creating Charges.
 type ChargeParams struct {
-	Amount         int    `stripe_field:"amount"`
-	Currency       string `stripe_field:"currency"`
-	Customer       string `stripe_field:"customer"`
-	Description    string `stripe_field:"description"`
-	DisableCapture bool   `stripe_field:"capture" opposite:"true"`
-	ApplicationFee int    `stripe_field:"application_fee"`
-	*CardParams
-	Metadata
+	Amount         *int    `stripe_field:"amount"`
+	Currency       *string `stripe_field:"currency"`
+	Customer       *string `stripe_field:"customer"`
+	Description    *string `stripe_field:"description"`
+	DisableCapture *bool   `stripe_field:"capture" opposite:"true"`
+	ApplicationFee *int    `stripe_field:"application_fee"`
+	*CardParams    `stripe_field:"card"`
+	Metadata       `stripe_field:"metadata"`
+}
+
+// CouponListParams hold all of the parameters used for listing Coupons.
+type CouponListParams struct {
+	ListParams
 }
 
 // CouponParams hold all of the parameters used for creating Coupons.
 type CouponParams struct {
-	Id               string `stripe_field:"id"`
-	Duration         string `stripe_field:"duration"`
-	AmountOff        int    `stripe_field:"amount_off"`
-	Currency         string `stripe_field:"currency"`
-	DurationInMonths int    `stripe_field:"duration_in_months"`
-	MaxRedemptions   int    `stripe_field:"max_redemptions"`
-	PercentOff       int    `stripe_field:"percent_off"`
-	RedeemBy         int    `stripe_field:"redeem_by"`
+	Id               *string `stripe_field:"id"`
+	Duration         *string `stripe_field:"duration"`
+	AmountOff        *int    `stripe_field:"amount_off"`
+	Currency         *string `stripe_field:"currency"`
+	DurationInMonths *int    `stripe_field:"duration_in_months"`
+	MaxRedemptions   *int    `stripe_field:"max_redemptions"`
+	PercentOff       *int    `stripe_field:"percent_off"`
+	RedeemBy         *int    `stripe_field:"redeem_by"`
+}
+
+// CustomerListParams hold all of the parameters used for listing Customers.
+type CustomerListParams struct {
+	ListParams
+	Created *RangeQuery `stripe_field:"created"`
 }
 
 // CustomerParams hold all of the parameters used for creating and updating
 // Customers.
 type CustomerParams struct {
-	AccountBalance int    `stripe_field:"account_balance"`
-	Coupon         string `stripe_field:"coupon"`
-	Description    string `stripe_field:"description"`
-	Email          string `stripe_field:"email"`
-	Plan           string `stripe_field:"plan"`
-	Quantity       int    `stripe_field:"quantity"`
-	TrialEnd       int    `stripe_field:"trial_end"`
-	*CardParams
-	Metadata
+	AccountBalance *int    `stripe_field:"account_balance"`
+	Coupon         *string `stripe_field:"coupon"`
+	Description    *string `stripe_field:"description"`
+	Email          *string `stripe_field:"email"`
+	Plan           *string `stripe_field:"plan"`
+	Quantity       *int    `stripe_field:"quantity"`
+	TrialEnd       *int    `stripe_field:"trial_end"`
+	*CardParams    `stripe_field:"card"`
+	Metadata       `stripe_field:"metadata"`
+}
+
+// EventListParams hold all of the parameters used for listing Events.
+type EventListParams struct {
+	ListParams
+	Created *RangeQuery `stripe_field:"created"`
+	Type    string      `stripe_field:"type"`
+}
+
+// InvoiceListParams hold all of the parameters used for listing Invoices.
+type InvoiceListParams struct {
+	ListParams
+	Date     *RangeQuery `stripe_field:"date"`
+	Customer string      `stripe_field:"customer"`
+}
+
+// InvoiceLineListParams hold all of the parameters used for listing the
+// InvoiceLineItems of an Invoice.
+type InvoiceLineListParams struct {
+	ListParams
+	Customer string `stripe_field:"customer"`
 }
 
 // InvoiceParams hold all of the parameters used for creating and updating
 // Invoices.
 type InvoiceParams struct {
-	Customer       string `stripe_field:"customer"`
-	ApplicationFee int    `stripe_field:"application_fee"`
-	Closed         bool   `stripe_field:"closed"`
+	Customer       *string `stripe_field:"customer"`
+	ApplicationFee *int    `stripe_field:"application_fee"`
+	Closed         *bool   `stripe_field:"closed"`
+}
+
+// InvoiceItemListParams hold all of the parameters used for listing
+// InvoiceItems.
+type InvoiceItemListParams struct {
+	ListParams
+	Created  *RangeQuery `stripe_field:"created"`
+	Customer string      `stripe_field:"customer"`
 }
 
 // InvoiceItemParams hold all of the parameters used for creating and updating
 // InvoiceItems.
 type InvoiceItemParams struct {
-	Customer    string `stripe_field:"customer"`
-	Amount      int    `stripe_field:"amount"`
-	Currency    string `stripe_field:"currency"`
-	Invoice     string `stripe_field:"invoice"`
-	Description string `stripe_field:"description"`
-	Metadata
+	Customer    *string `stripe_field:"customer"`
+	Amount      *int    `stripe_field:"amount"`
+	Currency    *string `stripe_field:"currency"`
+	Invoice     *string `stripe_field:"invoice"`
+	Description *string `stripe_field:"description"`
+	Metadata    `stripe_field:"metadata"`
+}
+
+// PlanListParams hold all of the parameters used for listing Plans.
+type PlanListParams struct {
+	ListParams
 }
 
 // PlanParams hold all of the parameters used for creating and updating Plans.
 type PlanParams struct {
-	Id              string `stripe_field:"id"`
-	Amount          int    `stripe_field:"amount"`
-	Currency        string `stripe_field:"currency"`
-	Interval        string `stripe_field:"interval"`
-	IntervalCount   int    `stripe_field:"interval_count"`
-	Name            string `stripe_field:"name"`
-	TrialPeriodDays int    `stripe_field:"trial_period_days"`
-	Metadata
+	Id              *string `stripe_field:"id"`
+	Amount          *int    `stripe_field:"amount"`
+	Currency        *string `stripe_field:"currency"`
+	Interval        *string `stripe_field:"interval"`
+	IntervalCount   *int    `stripe_field:"interval_count"`
+	Name            *string `stripe_field:"name"`
+	TrialPeriodDays *int    `stripe_field:"trial_period_days"`
+	Metadata        `stripe_field:"metadata"`
+}
+
+// RecipientListParams hold all of the parameters used for listing
+// Recipients.
+type RecipientListParams struct {
+	ListParams
+	Verified *bool `stripe_field:"verified"`
 }
 
 // RecipientParams hold all of the parameters used for creating and updating
 // Recipients.
 type RecipientParams struct {
-	Name        string `stripe_field:"name"`
-	Type        string `stripe_field:"type"`
-	TaxId       string `stripe_field:"tax_id"`
-	Email       string `stripe_field:"email"`
-	Description string `stripe_field:"description"`
-	*BankAccountParams
-	Metadata
+	Name               *string `stripe_field:"name"`
+	Type               *string `stripe_field:"type"`
+	TaxId              *string `stripe_field:"tax_id"`
+	Email              *string `stripe_field:"email"`
+	Description        *string `stripe_field:"description"`
+	*BankAccountParams `stripe_field:"bank_account"`
+	Metadata           `stripe_field:"metadata"`
 }
 
 // RefundParams hold all of the parameters used for refunding Charges.
 type RefundParams struct {
-	Amount               int  `stripe_field:"amount"`
-	RefundApplicationFee bool `stripe_field:"refund_application_fee"`
+	Amount               *int  `stripe_field:"amount"`
+	RefundApplicationFee *bool `stripe_field:"refund_application_fee"`
+}
+
+// SubscriptionListParams hold all of the parameters used for listing
+// Subscriptions.
+type SubscriptionListParams struct {
+	ListParams
 }
 
 // SubscriptionParams hold all of the parameters used for creating, updating,
 // and canceling Subscriptions.
 type SubscriptionParams struct {
-	Plan                  string  `stripe_field:"plan"`
-	Coupon                string  `stripe_field:"coupon"`
-	DisableProrate        bool    `stripe_field:"prorate" opposite:"true"`
-	TrialEnd              int     `stripe_field:"trial_end"`
-	Quantity              int     `stripe_field:"quantity"`
-	ApplicationFeePercent float64 `stripe_field:"application_fee_percent"`
-	AtPeriodEnd           bool    `stripe_field:"at_period_end"`
-	*CardParams
+	Plan                  *string  `stripe_field:"plan"`
+	Coupon                *string  `stripe_field:"coupon"`
+	DisableProrate        *bool    `stripe_field:"prorate" opposite:"true"`
+	TrialEnd              *int     `stripe_field:"trial_end"`
+	Quantity              *int     `stripe_field:"quantity"`
+	ApplicationFeePercent *float64 `stripe_field:"application_fee_percent" precision:"2" min:"0" max:"100"`
+	AtPeriodEnd           *bool    `stripe_field:"at_period_end"`
+	*CardParams           `stripe_field:"card"`
 }
 
 // TokenParams hold all of the parameters used for creating Tokens.
 type TokenParams struct {
-	Customer string `stripe_field:"customer"`
-	*BankAccountParams
-	*CardParams
+	Customer           *string `stripe_field:"customer"`
+	*BankAccountParams `stripe_field:"bank_account"`
+	*CardParams        `stripe_field:"card"`
+}
+
+// TransferListParams hold all of the parameters used for listing Transfers.
+type TransferListParams struct {
+	ListParams
+	Created   *RangeQuery `stripe_field:"created"`
+	Date      *RangeQuery `stripe_field:"date"`
+	Recipient string      `stripe_field:"recipient"`
+	Status    string      `stripe_field:"status"`
 }
 
 // TransferParams hold all of the parameters used for creating and updating
 // Transfers.
 type TransferParams struct {
-	Amount              int    `stripe_field:"amount"`
-	Currency            string `stripe_field:"currency"`
-	Recipient           string `stripe_field:"recipient"`
-	Description         string `stripe_field:"description"`
-	StatementDescription string `stripe_field:"statement_description"`
-	Metadata
+	Amount               *int    `stripe_field:"amount"`
+	Currency             *string `stripe_field:"currency"`
+	Recipient            *string `stripe_field:"recipient"`
+	Description          *string `stripe_field:"description"`
+	StatementDescription *string `stripe_field:"statement_description"`
+	Metadata             `stripe_field:"metadata"`
 }
@@ -1,10 +1,9 @@
 package stripe
 
-import (
-	"net/url"
-)
+import "context"
 
 type Plan struct {
+	APIResource
 	Id              string   `json:"id"`
 	Object          string   `json:"object"`
 	Livemode        bool     `json:"livemode"`
@@ -22,6 +21,17 @@ type PlanListResponse struct {
 	Data []Plan `json:"data"`
 }
 
+// PlanIter is an Iter over Plans.
+type PlanIter struct {
+	*Iter
+}
+
+// Plan returns the Plan Next advanced to.
+func (it *PlanIter) Plan() *Plan {
+	p, _ := it.Current().(*Plan)
+	return p
+}
+
 type PlanClient struct {
 	client Client
 }
@@ -29,71 +39,135 @@ type PlanClient struct {
 // Create creates a plan.
 //
 // For more information: https://stripe.com/docs/api#create_plan
-func (c *PlanClient) Create(params *PlanParams) (*Plan, error) {
+func (c *PlanClient) Create(params *PlanParams, opts ...RequestOption) (*Plan, error) {
+	return c.CreateContext(context.Background(), params, opts...)
+}
+
+// CreateContext is like Create, but uses ctx for the underlying request.
+func (c *PlanClient) CreateContext(ctx context.Context, params *PlanParams, opts ...RequestOption) (*Plan, error) {
 	plan := Plan{}
-	values := url.Values{}
-	parsePlanParams(params, &values)
-	err := c.client.post("/plans", values, &plan)
-	return &plan, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.post(ctx, "/plans", values, &plan, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
 }
 
 // Retrieve loads a plan.
 //
 // For more information: https://stripe.com/docs/api#retrieve_plan
-func (c *PlanClient) Retrieve(id string) (*Plan, error) {
+func (c *PlanClient) Retrieve(id string, opts ...RequestOption) (*Plan, error) {
+	return c.RetrieveContext(context.Background(), id, opts...)
+}
+
+// RetrieveContext is like Retrieve, but uses ctx for the underlying request.
+func (c *PlanClient) RetrieveContext(ctx context.Context, id string, opts ...RequestOption) (*Plan, error) {
 	plan := Plan{}
-	err := c.client.get("/plans/"+id, nil, &plan)
-	return &plan, err
+	err := c.client.get(ctx, "/plans/"+id, nil, &plan, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
 }
 
 // Update updates a plan.
 //
 // For more information: https://stripe.com/docs/api#update_plan
-func (c *PlanClient) Update(id string, params *PlanParams) (*Plan, error) {
+func (c *PlanClient) Update(id string, params *PlanParams, opts ...RequestOption) (*Plan, error) {
+	return c.UpdateContext(context.Background(), id, params, opts...)
+}
+
+// UpdateContext is like Update, but uses ctx for the underlying request.
+func (c *PlanClient) UpdateContext(ctx context.Context, id string, params *PlanParams, opts ...RequestOption) (*Plan, error) {
 	plan := Plan{}
-	values := url.Values{}
-	parsePlanParams(params, &values)
-	err := c.client.post("/plans/"+id, values, &plan)
-	return &plan, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.post(ctx, "/plans/"+id, values, &plan, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
 }
 
 // Delete deletes a plan.
 //
 // For more information: https://stripe.com/docs/api#delete_plan
-func (c *PlanClient) Delete(id string) (*DeleteResponse, error) {
+func (c *PlanClient) Delete(id string, opts ...RequestOption) (*DeleteResponse, error) {
+	return c.DeleteContext(context.Background(), id, opts...)
+}
+
+// DeleteContext is like Delete, but uses ctx for the underlying request.
+func (c *PlanClient) DeleteContext(ctx context.Context, id string, opts ...RequestOption) (*DeleteResponse, error) {
 	response := DeleteResponse{}
-	err := c.client.delete("/plans/"+id, nil, &response)
-	return &response, err
+	err := c.client.delete(ctx, "/plans/"+id, nil, &response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
 }
 
-// All lists the first 10 plans. It calls AllWithFilters with a blank Filters so
+// All lists the first 10 plans. It calls AllWithParams with no params so
 // all defaults are used.
 //
 // For more information: https://stripe.com/docs/api#list_plans
-func (c *PlanClient) All() (*PlanListResponse, error) {
-	return c.AllWithFilters(Filters{})
+func (c *PlanClient) All(opts ...RequestOption) (*PlanListResponse, error) {
+	return c.AllContext(context.Background(), opts...)
+}
+
+// AllContext is like All, but uses ctx for the underlying request.
+func (c *PlanClient) AllContext(ctx context.Context, opts ...RequestOption) (*PlanListResponse, error) {
+	return c.AllWithParamsContext(ctx, nil, opts...)
 }
 
-// AllWithFilters takes a Filters and applies all valid filters for the action.
+// AllWithParams lists the Plans matching params.
 //
 // For more information: https://stripe.com/docs/api#list_plans
-func (c *PlanClient) AllWithFilters(filters Filters) (*PlanListResponse, error) {
+func (c *PlanClient) AllWithParams(params *PlanListParams, opts ...RequestOption) (*PlanListResponse, error) {
+	return c.AllWithParamsContext(context.Background(), params, opts...)
+}
+
+// AllWithParamsContext is like AllWithParams, but uses ctx for the
+// underlying request.
+func (c *PlanClient) AllWithParamsContext(ctx context.Context, params *PlanListParams, opts ...RequestOption) (*PlanListResponse, error) {
 	response := PlanListResponse{}
-	values := url.Values{}
-	addFiltersToValues([]string{"count", "offset"}, filters, &values)
-	err := c.client.get("/plans", values, &response)
-	return &response, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.get(ctx, "/plans", values, &response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
 }
 
-// parsePlanParams takes a pointer to a PlanParams and a pointer to a url.Values,
-// it iterates over everything in the PlanParams struct and Adds what is there
-// to the url.Values.
-func parsePlanParams(params *PlanParams, values *url.Values) {
+// Iter returns an iterator over the Plans matching params, fetching pages as
+// they are needed.
+//
+// For more information: https://stripe.com/docs/api#pagination
+func (c *PlanClient) Iter(params *PlanListParams, opts ...RequestOption) *PlanIter {
+	return c.IterContext(context.Background(), params, opts...)
+}
 
-	// Use parseMetaData from metadata.go to setup the metadata param
-	if params.Metadata != nil {
-		parseMetadata(params.Metadata, values)
+// IterContext is like Iter, but uses ctx for the underlying requests.
+func (c *PlanClient) IterContext(ctx context.Context, params *PlanListParams, opts ...RequestOption) *PlanIter {
+	p := PlanListParams{}
+	if params != nil {
+		p = *params
 	}
 
-	addParamsToValues(params, values)
+	return &PlanIter{newIter(ctx, p.ListParams, func(ctx context.Context, page ListParams) ([]interface{}, ListResponse, error) {
+		p.ListParams = page
+		list, err := c.AllWithParamsContext(ctx, &p, opts...)
+		if err != nil {
+			return nil, ListResponse{}, err
+		}
+		return pageOf(list.Data), list.ListResponse, nil
+	})}
 }
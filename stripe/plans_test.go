@@ -2,7 +2,6 @@ package stripe
 
 import (
 	"github.com/bmizerany/assert"
-	"net/url"
 	"strconv"
 	"testing"
 )
@@ -49,36 +48,35 @@ func TestPlansAll(t *testing.T) {
 	assert.Equal(t, plans.Data[0].Id, "plan_123456789")
 }
 
-func TestPlansAllWithFilters(t *testing.T) {
+func TestPlansAllWithParams(t *testing.T) {
 	setup()
 	defer teardown()
 	handleWithJSON("/plans", "plans/plans.json")
-	plans, _ := client.Plans.AllWithFilters(Filters{})
+	plans, _ := client.Plans.AllWithParams(&PlanListParams{})
 	assert.Equal(t, plans.Count, 1)
 	assert.Equal(t, plans.Data[0].Id, "plan_123456789")
 }
 
-func TestParsePlanParams(t *testing.T) {
+func TestEncodePlanParams(t *testing.T) {
 	params := PlanParams{
-		Id:              "plan_123456789",
-		Amount:          1000,
-		Currency:        "USD",
-		Interval:        "monthly",
-		IntervalCount:   2,
-		Name:            "Plan",
-		TrialPeriodDays: 1,
+		Id:              String("plan_123456789"),
+		Amount:          Int(1000),
+		Currency:        String("USD"),
+		Interval:        String("monthly"),
+		IntervalCount:   Int(2),
+		Name:            String("Plan"),
+		TrialPeriodDays: Int(1),
 		Metadata: Metadata{
 			"foo": "bar",
 		},
 	}
-	values := url.Values{}
-	parsePlanParams(&params, &values)
-	assert.Equal(t, values.Get("id"), params.Id)
-	assert.Equal(t, values.Get("amount"), strconv.Itoa(params.Amount))
-	assert.Equal(t, values.Get("currency"), params.Currency)
-	assert.Equal(t, values.Get("interval"), params.Interval)
-	assert.Equal(t, values.Get("interval_count"), strconv.Itoa(params.IntervalCount))
-	assert.Equal(t, values.Get("name"), params.Name)
-	assert.Equal(t, values.Get("trial_period_days"), strconv.Itoa(params.TrialPeriodDays))
+	values, _ := encodeParams(&params)
+	assert.Equal(t, values.Get("id"), *params.Id)
+	assert.Equal(t, values.Get("amount"), strconv.Itoa(*params.Amount))
+	assert.Equal(t, values.Get("currency"), *params.Currency)
+	assert.Equal(t, values.Get("interval"), *params.Interval)
+	assert.Equal(t, values.Get("interval_count"), strconv.Itoa(*params.IntervalCount))
+	assert.Equal(t, values.Get("name"), *params.Name)
+	assert.Equal(t, values.Get("trial_period_days"), strconv.Itoa(*params.TrialPeriodDays))
 	assert.Equal(t, values.Get("metadata[foo]"), params.Metadata["foo"])
 }
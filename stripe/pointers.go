@@ -0,0 +1,33 @@
+package stripe
+
+// The fields of the XxxParams structs are pointers, so that a field that is
+// not set (nil) can be told apart from one set to its zero value. Fields that
+// are nil are not sent, while those that are set always are, even when they
+// point to 0, false or "":
+//
+//	params := &stripe.CustomerParams{
+//		AccountBalance: stripe.Int(0),     // sends account_balance=0
+//		Description:    stripe.String(""), // clears the description
+//	}
+//
+// The helpers below return a pointer to the value they are given.
+
+// String returns a pointer to the string v.
+func String(v string) *string {
+	return &v
+}
+
+// Int returns a pointer to the int v.
+func Int(v int) *int {
+	return &v
+}
+
+// Bool returns a pointer to the bool v.
+func Bool(v bool) *bool {
+	return &v
+}
+
+// Float64 returns a pointer to the float64 v.
+func Float64(v float64) *float64 {
+	return &v
+}
@@ -1,10 +1,9 @@
 package stripe
 
-import (
-	"net/url"
-)
+import "context"
 
 type Recipient struct {
+	APIResource
 	Id            string       `json:"id"`
 	Object        string       `json:"object"`
 	Livemode      bool         `json:"livemode"`
@@ -22,6 +21,17 @@ type RecipientListResponse struct {
 	Data []Recipient `json:"data"`
 }
 
+// RecipientIter is an Iter over Recipients.
+type RecipientIter struct {
+	*Iter
+}
+
+// Recipient returns the Recipient Next advanced to.
+func (it *RecipientIter) Recipient() *Recipient {
+	r, _ := it.Current().(*Recipient)
+	return r
+}
+
 type RecipientClient struct {
 	client Client
 }
@@ -29,77 +39,135 @@ type RecipientClient struct {
 // Create creates a recipient.
 //
 // For more information: https://stripe.com/docs/api#create_recipient
-func (c *RecipientClient) Create(params *RecipientParams) (*Recipient, error) {
+func (c *RecipientClient) Create(params *RecipientParams, opts ...RequestOption) (*Recipient, error) {
+	return c.CreateContext(context.Background(), params, opts...)
+}
+
+// CreateContext is like Create, but uses ctx for the underlying request.
+func (c *RecipientClient) CreateContext(ctx context.Context, params *RecipientParams, opts ...RequestOption) (*Recipient, error) {
 	recipient := Recipient{}
-	values := url.Values{}
-	parseRecipientParams(params, &values)
-	err := c.client.post("/recipients", values, &recipient)
-	return &recipient, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.post(ctx, "/recipients", values, &recipient, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &recipient, nil
 }
 
 // Retrieve loads a recipient.
 //
 // For more information: https://stripe.com/docs/api#retrieve_recipient
-func (c *RecipientClient) Retrieve(id string) (*Recipient, error) {
+func (c *RecipientClient) Retrieve(id string, opts ...RequestOption) (*Recipient, error) {
+	return c.RetrieveContext(context.Background(), id, opts...)
+}
+
+// RetrieveContext is like Retrieve, but uses ctx for the underlying request.
+func (c *RecipientClient) RetrieveContext(ctx context.Context, id string, opts ...RequestOption) (*Recipient, error) {
 	recipient := Recipient{}
-	err := c.client.get("/recipients/"+id, nil, &recipient)
-	return &recipient, err
+	err := c.client.get(ctx, "/recipients/"+id, nil, &recipient, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &recipient, nil
 }
 
 // Update updates a recipient.
 //
 // For more information: https://stripe.com/docs/api#update_recipient
-func (c *RecipientClient) Update(id string, params *RecipientParams) (*Recipient, error) {
+func (c *RecipientClient) Update(id string, params *RecipientParams, opts ...RequestOption) (*Recipient, error) {
+	return c.UpdateContext(context.Background(), id, params, opts...)
+}
+
+// UpdateContext is like Update, but uses ctx for the underlying request.
+func (c *RecipientClient) UpdateContext(ctx context.Context, id string, params *RecipientParams, opts ...RequestOption) (*Recipient, error) {
 	recipient := Recipient{}
-	values := url.Values{}
-	parseRecipientParams(params, &values)
-	err := c.client.post("/recipients/"+id, values, &recipient)
-	return &recipient, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.post(ctx, "/recipients/"+id, values, &recipient, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &recipient, nil
 }
 
 // Delete deletes a recipient.
 //
 // For more information: https://stripe.com/docs/api/#delete_recipient
-func (c *RecipientClient) Delete(id string) (*DeleteResponse, error) {
+func (c *RecipientClient) Delete(id string, opts ...RequestOption) (*DeleteResponse, error) {
+	return c.DeleteContext(context.Background(), id, opts...)
+}
+
+// DeleteContext is like Delete, but uses ctx for the underlying request.
+func (c *RecipientClient) DeleteContext(ctx context.Context, id string, opts ...RequestOption) (*DeleteResponse, error) {
 	response := DeleteResponse{}
-	err := c.client.delete("/recipients/"+id, nil, &response)
-	return &response, err
+	err := c.client.delete(ctx, "/recipients/"+id, nil, &response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
 }
 
-// All lists the first 10 recipients. It calls AllWithFilters with a blank
-// Filters so all defaults are used.
+// All lists the first 10 recipients. It calls AllWithParams with no params
+// so all defaults are used.
 //
 // For more information: https://stripe.com/docs/api#list_recipients
-func (c *RecipientClient) All() (*RecipientListResponse, error) {
-	return c.AllWithFilters(Filters{})
+func (c *RecipientClient) All(opts ...RequestOption) (*RecipientListResponse, error) {
+	return c.AllContext(context.Background(), opts...)
 }
 
-// AllWithFilters takes a Filters and applies all valid filters for the action.
+// AllContext is like All, but uses ctx for the underlying request.
+func (c *RecipientClient) AllContext(ctx context.Context, opts ...RequestOption) (*RecipientListResponse, error) {
+	return c.AllWithParamsContext(ctx, nil, opts...)
+}
+
+// AllWithParams lists the Recipients matching params.
 //
 // For more information: https://stripe.com/docs/api#list_recipients
-func (c *RecipientClient) AllWithFilters(filters Filters) (*RecipientListResponse, error) {
-	response := RecipientListResponse{}
-	values := url.Values{}
-	addFiltersToValues([]string{"count", "offset", "verified"}, filters, &values)
-	err := c.client.get("/recipients", values, &response)
-	return &response, err
+func (c *RecipientClient) AllWithParams(params *RecipientListParams, opts ...RequestOption) (*RecipientListResponse, error) {
+	return c.AllWithParamsContext(context.Background(), params, opts...)
 }
 
-// parseRecipientParams takes a pointer to a RecipientParams and a pointer to a url.Values,
-// it iterates over everything in the RecipientParams struct and Adds what is there
-// to the url.Values.
-func parseRecipientParams(params *RecipientParams, values *url.Values) {
-
-	// Use parseBankAccountParams from bank_accounts.go to setup the bank_account
-	// param
-	if params.BankAccountParams != nil {
-		parseBankAccountParams(params.BankAccountParams, values)
+// AllWithParamsContext is like AllWithParams, but uses ctx for the
+// underlying request.
+func (c *RecipientClient) AllWithParamsContext(ctx context.Context, params *RecipientListParams, opts ...RequestOption) (*RecipientListResponse, error) {
+	response := RecipientListResponse{}
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
 	}
+	err = c.client.get(ctx, "/recipients", values, &response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Iter returns an iterator over the Recipients matching params, fetching
+// pages as they are needed.
+//
+// For more information: https://stripe.com/docs/api#pagination
+func (c *RecipientClient) Iter(params *RecipientListParams, opts ...RequestOption) *RecipientIter {
+	return c.IterContext(context.Background(), params, opts...)
+}
 
-	// Use parseMetaData from metadata.go to setup the metadata param
-	if params.Metadata != nil {
-		parseMetadata(params.Metadata, values)
+// IterContext is like Iter, but uses ctx for the underlying requests.
+func (c *RecipientClient) IterContext(ctx context.Context, params *RecipientListParams, opts ...RequestOption) *RecipientIter {
+	p := RecipientListParams{}
+	if params != nil {
+		p = *params
 	}
 
-	addParamsToValues(params, values)
+	return &RecipientIter{newIter(ctx, p.ListParams, func(ctx context.Context, page ListParams) ([]interface{}, ListResponse, error) {
+		p.ListParams = page
+		list, err := c.AllWithParamsContext(ctx, &p, opts...)
+		if err != nil {
+			return nil, ListResponse{}, err
+		}
+		return pageOf(list.Data), list.ListResponse, nil
+	})}
 }
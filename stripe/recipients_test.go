@@ -2,7 +2,6 @@ package stripe
 
 import (
 	"github.com/bmizerany/assert"
-	"net/url"
 	"testing"
 )
 
@@ -48,36 +47,35 @@ func TestRecipientsAll(t *testing.T) {
 	assert.Equal(t, recipients.Data[0].Id, "rp_123456789")
 }
 
-func TestRecipientsAllWithFilters(t *testing.T) {
+func TestRecipientsAllWithParams(t *testing.T) {
 	setup()
 	defer teardown()
 	handleWithJSON("/recipients", "recipients/recipients.json")
-	recipients, _ := client.Recipients.AllWithFilters(Filters{})
+	recipients, _ := client.Recipients.AllWithParams(&RecipientListParams{})
 	assert.Equal(t, recipients.Count, 1)
 	assert.Equal(t, recipients.Data[0].Id, "rp_123456789")
 }
 
-func TestParseRecipientParams(t *testing.T) {
+func TestEncodeRecipientParams(t *testing.T) {
 	params := RecipientParams{
-		Name:        "Andrew Thorp",
-		Type:        "individual",
-		TaxId:       "123456789",
-		Email:       "apt@stripe.com",
-		Description: "Description",
+		Name:        String("Andrew Thorp"),
+		Type:        String("individual"),
+		TaxId:       String("123456789"),
+		Email:       String("apt@stripe.com"),
+		Description: String("Description"),
 		BankAccountParams: &BankAccountParams{
-			AccountNumber: "123456789",
+			AccountNumber: String("123456789"),
 		},
 		Metadata: Metadata{
 			"foo": "bar",
 		},
 	}
-	values := url.Values{}
-	parseRecipientParams(&params, &values)
-	assert.Equal(t, values.Get("name"), params.Name)
-	assert.Equal(t, values.Get("type"), params.Type)
-	assert.Equal(t, values.Get("tax_id"), params.TaxId)
-	assert.Equal(t, values.Get("email"), params.Email)
-	assert.Equal(t, values.Get("description"), params.Description)
-	assert.Equal(t, values.Get("bank_account[account_number]"), params.BankAccountParams.AccountNumber)
+	values, _ := encodeParams(&params)
+	assert.Equal(t, values.Get("name"), *params.Name)
+	assert.Equal(t, values.Get("type"), *params.Type)
+	assert.Equal(t, values.Get("tax_id"), *params.TaxId)
+	assert.Equal(t, values.Get("email"), *params.Email)
+	assert.Equal(t, values.Get("description"), *params.Description)
+	assert.Equal(t, values.Get("bank_account[account_number]"), *params.BankAccountParams.AccountNumber)
 	assert.Equal(t, values.Get("metadata[foo]"), params.Metadata["foo"])
 }
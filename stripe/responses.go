@@ -1,31 +1,69 @@
 package stripe
 
+import "fmt"
+
+// APIResource is embedded in every resource returned by the Stripe API. It
+// records the API version that produced the response the resource was decoded
+// from, as reported by the Stripe-Version response header, or the version
+// requested when the API doesn't report one.
+//
+// Only the resource a method returns is set, not those nested inside of it.
+type APIResource struct {
+	APIVersion string `json:"-"`
+}
+
+// setAPIVersion records the API version of the response.
+func (r *APIResource) setAPIVersion(version string) {
+	r.APIVersion = version
+}
+
 // ListResponse is a part of what is returned from the Stripe API after a GET
 // for a collection.
 type ListResponse struct {
-	Object string `json:"object"`
-	Url    string `json:"url"`
-	Count  int    `json:"count"`
+	APIResource
+	Object  string `json:"object"`
+	Url     string `json:"url"`
+	Count   int    `json:"count"`
+	HasMore bool   `json:"has_more"`
 }
 
 // DeleteResponse is what is returned from the Stripe API after a DELETE.
 type DeleteResponse struct {
+	APIResource
 	Id      string `json:"id"`
 	Deleted bool   `json:"deleted"`
 }
 
-// ErrorResponse is what is returned from the Stripe API after an error.
+// ErrorResponse is what is returned from the Stripe API after an error. It is
+// wrapped by one of the typed errors in errors.go (CardError, etc), depending
+// on the kind of error, and can be reached from any of them with errors.As.
 type ErrorResponse struct {
 	Err struct {
-		Type    string `json:"type"`
-		Message string `json:"message"`
-		Code    string `json:"code,omitempty"`
-		Param   string `json:"param,omitempty"`
+		Type        string `json:"type"`
+		Message     string `json:"message"`
+		Code        string `json:"code,omitempty"`
+		Param       string `json:"param,omitempty"`
+		DeclineCode string `json:"decline_code,omitempty"`
 	} `json:"error"`
+	HTTPStatusCode int    `json:"-"`
+	RequestId      string `json:"-"`
+	Body           []byte `json:"-"`
 }
 
 // ErrorResponse must implement an Error() method to satisfy the error interface.
 func (e *ErrorResponse) Error() string {
-	// TODO: Do more than just return the Message?
-	return e.Err.Message
+	msg := e.Err.Message
+	if e.Err.Type != "" {
+		msg = e.Err.Type + ": " + msg
+	}
+
+	if e.HTTPStatusCode != 0 {
+		msg += fmt.Sprintf(" (status %d", e.HTTPStatusCode)
+		if e.RequestId != "" {
+			msg += ", request " + e.RequestId
+		}
+		msg += ")"
+	}
+
+	return Redact("stripe: " + msg)
 }
@@ -8,5 +8,12 @@ import (
 func TestErrorMessage(t *testing.T) {
 	err := ErrorResponse{}
 	err.Err.Message = "Error Message"
-	assert.Equal(t, err.Error(), err.Err.Message)
+	assert.Equal(t, err.Error(), "stripe: Error Message")
+
+	err.Err.Type = "card_error"
+	err.HTTPStatusCode = 402
+	assert.Equal(t, err.Error(), "stripe: card_error: Error Message (status 402)")
+
+	err.RequestId = "req_123456789"
+	assert.Equal(t, err.Error(), "stripe: card_error: Error Message (status 402, request req_123456789)")
 }
@@ -0,0 +1,146 @@
+package stripe
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how a Client retries requests that failed in a way that
+// is safe to repeat.
+//
+// Requests are retried when:
+//
+//   - the API answers 409 (a lock conflict on the object) or 429 (rate
+//     limited), as Stripe did not act on the request.
+//   - the request could not be delivered (connection reset, timeout, etc) or
+//     the API answers with a 5xx, but only if the request is idempotent: a GET
+//     or DELETE, or a request carrying an Idempotency-Key header.
+//
+// Between attempts the Client waits BaseBackoff, doubled after every attempt
+// and capped at MaxBackoff. Jitter randomises that delay: a Jitter of 0.5 waits
+// anywhere between 50% and 100% of it. If the API sends a Retry-After header,
+// its value is used as the delay instead, unless it is more than MaxBackoff, in
+// which case the request is not retried.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	Jitter      float64
+}
+
+// DefaultRetryPolicy is a sensible RetryPolicy for most integrations. It is
+// not enabled unless passed to WithRetryPolicy.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseBackoff: 500 * time.Millisecond,
+	MaxBackoff:  8 * time.Second,
+	Jitter:      0.5,
+}
+
+// retry takes the number of the attempt that just finished, the request that
+// was sent and its outcome (res or err). It reports whether the request should
+// be attempted again, and how long to wait before doing so.
+func (p RetryPolicy) retry(attempt int, req *http.Request, res *http.Response, err error) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts {
+		return 0, false
+	}
+
+	switch {
+	case err == context.Canceled || err == context.DeadlineExceeded:
+		return 0, false
+	case err != nil:
+		if !idempotent(req) {
+			return 0, false
+		}
+	case res.StatusCode == http.StatusConflict, res.StatusCode == http.StatusTooManyRequests:
+	case res.StatusCode >= 500:
+		if !idempotent(req) {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+
+	if res != nil {
+		if delay, ok := retryAfter(res.Header.Get("Retry-After")); ok {
+			// Retrying sooner than asked would fail again, so give up.
+			if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+				return 0, false
+			}
+			return delay, true
+		}
+	}
+
+	return p.backoff(attempt), true
+}
+
+// backoff returns how long to wait after the given attempt. Before jitter is
+// applied, the delay is BaseBackoff * 2^(attempt-1), capped at MaxBackoff.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.BaseBackoff
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || delay < p.MaxBackoff); i++ {
+		delay *= 2
+	}
+
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+
+	if p.Jitter > 0 {
+		jitter := time.Duration(p.Jitter * rand.Float64() * float64(delay))
+		delay -= jitter
+	}
+
+	return delay
+}
+
+// idempotent reports whether req can safely be sent more than once.
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case "GET", "DELETE":
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// retryAfter parses the value of a Retry-After header, which is either a
+// number of seconds or an HTTP date.
+func retryAfter(header string) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(header); err == nil {
+		delay := date.Sub(time.Now())
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+
+	return 0, false
+}
+
+// sleep waits for delay, or until ctx is done, in which case it returns
+// ctx.Err().
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
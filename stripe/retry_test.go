@@ -0,0 +1,208 @@
+package stripe
+
+import (
+	"context"
+	"fmt"
+	"github.com/bmizerany/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseBackoff: time.Millisecond,
+	MaxBackoff:  5 * time.Millisecond,
+}
+
+// setupWithRetries is like setup, but the client retries with testRetryPolicy.
+func setupWithRetries() {
+	setup()
+	client = NewClientWith(nil, server.URL, "sk_abc123", WithRetryPolicy(testRetryPolicy))
+}
+
+// handleFlaky responds with status for the first failures requests to path,
+// and with the sample fixture afterwards. It returns a pointer to the number
+// of requests received.
+func handleFlaky(path string, failures, status int) *int {
+	attempts := 0
+	serveMux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts <= failures {
+			http.Error(w, loadFixture("errors/invalid_request_error.json"), status)
+			return
+		}
+		fmt.Fprint(w, loadFixture("sample.json"))
+	})
+	return &attempts
+}
+
+func TestRetryServerErrorOnGet(t *testing.T) {
+	setupWithRetries()
+	defer teardown()
+	attempts := handleFlaky("/flaky", 2, http.StatusServiceUnavailable)
+
+	var response struct{ Foo string }
+	err := client.get(context.Background(), "/flaky", nil, &response)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, *attempts, 3)
+	assert.Equal(t, response.Foo, "bar")
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	setupWithRetries()
+	defer teardown()
+	attempts := handleFlaky("/flaky", 5, http.StatusServiceUnavailable)
+
+	err := client.get(context.Background(), "/flaky", nil, nil)
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, *attempts, 3)
+}
+
+func TestRetryServerErrorOnPost(t *testing.T) {
+	setupWithRetries()
+	defer teardown()
+	attempts := handleFlaky("/flaky", 1, http.StatusInternalServerError)
+
+	err := client.post(context.Background(), "/flaky", nil, nil)
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, *attempts, 1)
+}
+
+func TestRetryRateLimitedAndConflictOnPost(t *testing.T) {
+	setupWithRetries()
+	defer teardown()
+	limited := handleFlaky("/limited", 1, http.StatusTooManyRequests)
+	conflict := handleFlaky("/conflict", 1, http.StatusConflict)
+
+	var response struct{ Foo string }
+	err := client.post(context.Background(), "/limited", nil, &response)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, *limited, 2)
+
+	err = client.post(context.Background(), "/conflict", nil, &response)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, *conflict, 2)
+}
+
+func TestRetryClientErrorIsNotRetried(t *testing.T) {
+	setupWithRetries()
+	defer teardown()
+	attempts := handleFlaky("/invalid", 1, http.StatusBadRequest)
+
+	err := client.get(context.Background(), "/invalid", nil, nil)
+	assert.NotEqual(t, err, nil)
+	assert.Equal(t, *attempts, 1)
+}
+
+func TestRetryNetworkError(t *testing.T) {
+	setupWithRetries()
+	defer teardown()
+
+	attempts := 0
+	serveMux.HandleFunc("/reset", func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		fmt.Fprint(w, loadFixture("sample.json"))
+	})
+
+	var response struct{ Foo string }
+	err := client.get(context.Background(), "/reset", nil, &response)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, attempts, 2)
+}
+
+func TestRetryStopsWhenContextIsDone(t *testing.T) {
+	serveMux = http.NewServeMux()
+	server = httptest.NewServer(serveMux)
+	defer teardown()
+	client = NewClientWith(nil, server.URL, "sk_abc123", WithRetryPolicy(RetryPolicy{
+		MaxAttempts: 3,
+		BaseBackoff: time.Hour,
+	}))
+	handleFlaky("/flaky", 5, http.StatusServiceUnavailable)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := client.get(ctx, "/flaky", nil, nil)
+	assert.Equal(t, err, context.DeadlineExceeded)
+}
+
+func TestRetryPolicyRetry(t *testing.T) {
+	get, _ := http.NewRequest("GET", "http://www.stripe.com", nil)
+	post, _ := http.NewRequest("POST", "http://www.stripe.com", nil)
+	keyed, _ := http.NewRequest("POST", "http://www.stripe.com", nil)
+	keyed.Header.Set("Idempotency-Key", "abc")
+
+	status := func(code int, header ...string) *http.Response {
+		res := &http.Response{StatusCode: code, Header: http.Header{}}
+		if len(header) > 0 {
+			res.Header.Set("Retry-After", header[0])
+		}
+		return res
+	}
+
+	_, retry := testRetryPolicy.retry(1, post, status(503), nil)
+	assert.Equal(t, retry, false)
+	_, retry = testRetryPolicy.retry(1, keyed, status(503), nil)
+	assert.Equal(t, retry, true)
+	_, retry = testRetryPolicy.retry(1, post, nil, fmt.Errorf("connection reset"))
+	assert.Equal(t, retry, false)
+	_, retry = testRetryPolicy.retry(1, get, nil, fmt.Errorf("connection reset"))
+	assert.Equal(t, retry, true)
+	_, retry = testRetryPolicy.retry(1, get, nil, context.Canceled)
+	assert.Equal(t, retry, false)
+	_, retry = testRetryPolicy.retry(3, get, status(503), nil)
+	assert.Equal(t, retry, false)
+
+	delay, retry := DefaultRetryPolicy.retry(1, post, status(429, "7"), nil)
+	assert.Equal(t, retry, true)
+	assert.Equal(t, delay, 7*time.Second)
+
+	// A Retry-After longer than MaxBackoff is not waited for.
+	_, retry = DefaultRetryPolicy.retry(1, post, status(429, "3600"), nil)
+	assert.Equal(t, retry, false)
+	delay, retry = RetryPolicy{MaxAttempts: 3}.retry(1, post, status(429, "3600"), nil)
+	assert.Equal(t, retry, true)
+	assert.Equal(t, delay, time.Hour)
+
+	// Retries are disabled by default.
+	_, retry = RetryPolicy{}.retry(1, get, status(503), nil)
+	assert.Equal(t, retry, false)
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	assert.Equal(t, policy.backoff(1), time.Second)
+	assert.Equal(t, policy.backoff(2), 2*time.Second)
+	assert.Equal(t, policy.backoff(3), 4*time.Second)
+	assert.Equal(t, policy.backoff(4), 5*time.Second)
+	assert.Equal(t, policy.backoff(50), 5*time.Second)
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		delay := policy.backoff(2)
+		assert.T(t, delay > time.Second && delay <= 2*time.Second, delay)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	delay, ok := retryAfter("3")
+	assert.Equal(t, ok, true)
+	assert.Equal(t, delay, 3*time.Second)
+
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	delay, ok = retryAfter(date)
+	assert.Equal(t, ok, true)
+	assert.T(t, delay > 58*time.Second && delay <= time.Minute, delay)
+
+	_, ok = retryAfter("")
+	assert.Equal(t, ok, false)
+	_, ok = retryAfter("soon")
+	assert.Equal(t, ok, false)
+}
@@ -1,11 +1,9 @@
 package stripe
 
-import (
-	"net/url"
-	"strconv"
-)
+import "context"
 
 type Subscription struct {
+	APIResource
 	Id                    string  `json:"id"`
 	Object                string  `json:"object"`
 	CancelAtPeriodEnd     bool    `json:"cancel_at_period_end"`
@@ -24,8 +22,19 @@ type Subscription struct {
 }
 
 type SubscriptionListResponse struct {
-  ListResponse
-  Data []Subscription `json:"data"`
+	ListResponse
+	Data []Subscription `json:"data"`
+}
+
+// SubscriptionIter is an Iter over Subscriptions.
+type SubscriptionIter struct {
+	*Iter
+}
+
+// Subscription returns the Subscription Next advanced to.
+func (it *SubscriptionIter) Subscription() *Subscription {
+	s, _ := it.Current().(*Subscription)
+	return s
 }
 
 type SubscriptionClient struct {
@@ -35,82 +44,150 @@ type SubscriptionClient struct {
 // Create creates a subscription.
 //
 // For more information: https://stripe.com/docs/api#create_subscription
-func (c *SubscriptionClient) Create(customerId string, params *SubscriptionParams) (*Subscription, error) {
+func (c *SubscriptionClient) Create(customerId string, params *SubscriptionParams, opts ...RequestOption) (*Subscription, error) {
+	return c.CreateContext(context.Background(), customerId, params, opts...)
+}
+
+// CreateContext is like Create, but uses ctx for the underlying request.
+func (c *SubscriptionClient) CreateContext(ctx context.Context, customerId string, params *SubscriptionParams, opts ...RequestOption) (*Subscription, error) {
 	subscription := Subscription{}
-	values := url.Values{}
-	parseSubscriptionParams("create", params, &values)
-	err := c.client.post("/customers/"+customerId+"/subscriptions", values, &subscription)
-	return &subscription, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.post(ctx, "/customers/"+customerId+"/subscriptions", values, &subscription, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
 }
 
 // Retrieve loads a subscription.
 //
 // For more information: https://stripe.com/docs/api#retrieve_subscription
-func (c *SubscriptionClient) Retrieve(customerId, id string) (*Subscription, error) {
+func (c *SubscriptionClient) Retrieve(customerId, id string, opts ...RequestOption) (*Subscription, error) {
+	return c.RetrieveContext(context.Background(), customerId, id, opts...)
+}
+
+// RetrieveContext is like Retrieve, but uses ctx for the underlying request.
+func (c *SubscriptionClient) RetrieveContext(ctx context.Context, customerId, id string, opts ...RequestOption) (*Subscription, error) {
 	subscription := Subscription{}
-	err := c.client.get("/customers/"+customerId+"/subscriptions/"+id, nil, &subscription)
-	return &subscription, err
+	err := c.client.get(ctx, "/customers/"+customerId+"/subscriptions/"+id, nil, &subscription, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
 }
 
 // Update updates a customers subscription.
 //
 // For more information: https://stripe.com/docs/api#update_subscription.
-func (c *SubscriptionClient) Update(customerId, id string, params *SubscriptionParams) (*Subscription, error) {
+func (c *SubscriptionClient) Update(customerId, id string, params *SubscriptionParams, opts ...RequestOption) (*Subscription, error) {
+	return c.UpdateContext(context.Background(), customerId, id, params, opts...)
+}
+
+// UpdateContext is like Update, but uses ctx for the underlying request.
+func (c *SubscriptionClient) UpdateContext(ctx context.Context, customerId, id string, params *SubscriptionParams, opts ...RequestOption) (*Subscription, error) {
 	subscription := Subscription{}
-	values := url.Values{}
-	parseSubscriptionParams("update", params, &values)
-	err := c.client.post("/customers/"+customerId+"/subscriptions/"+id, values, &subscription)
-	return &subscription, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.post(ctx, "/customers/"+customerId+"/subscriptions/"+id, values, &subscription, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
 }
 
 // Delete cancels a customers subscription.
 //
 // For more information: https://stripe.com/docs/api#cancel_subscription.
-func (c *SubscriptionClient) Delete(customerId, id string, params *SubscriptionParams) (*Subscription, error) {
+func (c *SubscriptionClient) Delete(customerId, id string, params *SubscriptionParams, opts ...RequestOption) (*Subscription, error) {
+	return c.DeleteContext(context.Background(), customerId, id, params, opts...)
+}
+
+// DeleteContext is like Delete, but uses ctx for the underlying request.
+func (c *SubscriptionClient) DeleteContext(ctx context.Context, customerId, id string, params *SubscriptionParams, opts ...RequestOption) (*Subscription, error) {
 	subscription := Subscription{}
-	values := url.Values{}
-	parseSubscriptionParams("cancel", params, &values)
-	err := c.client.delete("/customers/"+customerId+"/subscriptions/"+id, values, &subscription)
-	return &subscription, err
+	cancel := subscriptionCancelParams{}
+	if params != nil {
+		cancel.AtPeriodEnd = params.AtPeriodEnd
+	}
+
+	values, err := encodeParams(&cancel)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.delete(ctx, "/customers/"+customerId+"/subscriptions/"+id, values, &subscription, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
 }
 
-// All lists the first 10 customers. It calls AllWithFilters with a blank Filters
-// so all defaults are used.
+// All lists the first 10 customers. It calls AllWithParams with no params so
+// all defaults are used.
 //
 // For more information: https://stripe.com/docs/api#list_customers
-func (c *SubscriptionClient) All(customerId string) (*SubscriptionListResponse, error) {
-	return c.AllWithFilters(customerId, Filters{})
+func (c *SubscriptionClient) All(customerId string, opts ...RequestOption) (*SubscriptionListResponse, error) {
+	return c.AllContext(context.Background(), customerId, opts...)
 }
 
-// AllWithFilters takes a Filters and applies all valid filters for the action.
+// AllContext is like All, but uses ctx for the underlying request.
+func (c *SubscriptionClient) AllContext(ctx context.Context, customerId string, opts ...RequestOption) (*SubscriptionListResponse, error) {
+	return c.AllWithParamsContext(ctx, customerId, nil, opts...)
+}
+
+// AllWithParams lists the Subscriptions of the Customer matching params.
 //
 // For more information: https://stripe.com/docs/api#list_customers
-func (c *SubscriptionClient) AllWithFilters(customerId string, filters Filters) (*SubscriptionListResponse, error) {
+func (c *SubscriptionClient) AllWithParams(customerId string, params *SubscriptionListParams, opts ...RequestOption) (*SubscriptionListResponse, error) {
+	return c.AllWithParamsContext(context.Background(), customerId, params, opts...)
+}
+
+// AllWithParamsContext is like AllWithParams, but uses ctx for the
+// underlying request.
+func (c *SubscriptionClient) AllWithParamsContext(ctx context.Context, customerId string, params *SubscriptionListParams, opts ...RequestOption) (*SubscriptionListResponse, error) {
 	response := SubscriptionListResponse{}
-	values := url.Values{}
-	addFiltersToValues([]string{"count", "offset"}, filters, &values)
-	err := c.client.get("/customers/"+customerId+"/subscriptions", values, &response)
-	return &response, err
-}
-
-// parseSubscriptionParams takes a string (method), a pointer to
-// SubscriptionParams and a pointer to a url.Values, it iterates over everything
-// in the SubscriptionParams struct and Adds what is there to the url.Values.
-// The first argument, which is the action we are performing ("update" or
-// "cancel") determines what values we look for.
-func parseSubscriptionParams(method string, params *SubscriptionParams, values *url.Values) {
-
-	if method == "cancel" {
-		if params.AtPeriodEnd {
-			values.Add("at_period_end", strconv.FormatBool(params.AtPeriodEnd))
-		}
-		return
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
 	}
+	err = c.client.get(ctx, "/customers/"+customerId+"/subscriptions", values, &response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// Iter returns an iterator over the Subscriptions of the Customer matching
+// params, fetching pages as they are needed.
+//
+// For more information: https://stripe.com/docs/api#pagination
+func (c *SubscriptionClient) Iter(customerId string, params *SubscriptionListParams, opts ...RequestOption) *SubscriptionIter {
+	return c.IterContext(context.Background(), customerId, params, opts...)
+}
 
-	// Use parseCardParams from cards.go to setup the card param
-	if params.CardParams != nil {
-		parseCardParams(params.CardParams, values, true)
+// IterContext is like Iter, but uses ctx for the underlying requests.
+func (c *SubscriptionClient) IterContext(ctx context.Context, customerId string, params *SubscriptionListParams, opts ...RequestOption) *SubscriptionIter {
+	p := SubscriptionListParams{}
+	if params != nil {
+		p = *params
 	}
 
-	addParamsToValues(params, values)
+	return &SubscriptionIter{newIter(ctx, p.ListParams, func(ctx context.Context, page ListParams) ([]interface{}, ListResponse, error) {
+		p.ListParams = page
+		list, err := c.AllWithParamsContext(ctx, customerId, &p, opts...)
+		if err != nil {
+			return nil, ListResponse{}, err
+		}
+		return pageOf(list.Data), list.ListResponse, nil
+	})}
+}
+
+// subscriptionCancelParams hold the only parameter of SubscriptionParams that
+// canceling a Subscription takes.
+type subscriptionCancelParams struct {
+	AtPeriodEnd *bool `stripe_field:"at_period_end"`
 }
@@ -2,18 +2,31 @@ package stripe
 
 import (
 	"github.com/bmizerany/assert"
-	"net/url"
+	"net/http"
 	"strconv"
 	"testing"
 )
 
+func TestSubscriptionCreateInvalidApplicationFeePercent(t *testing.T) {
+	setup()
+	defer teardown()
+	serveMux.HandleFunc("/customers/cus_123456789/subscriptions", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not be sent")
+	})
+
+	params := SubscriptionParams{ApplicationFeePercent: Float64(12.345)}
+	subscription, err := client.Subscriptions.Create("cus_123456789", &params)
+	assert.Equal(t, subscription, (*Subscription)(nil))
+	assert.Equal(t, err, &ParamError{"application_fee_percent", "12.345 has more than 2 decimal places"})
+}
+
 func TestSubscriptionCreate(t *testing.T) {
 	setup()
 	defer teardown()
 	handleWithJSON("/customers/cus_123456789/subscriptions", "subscriptions/subscription.json")
 	params := SubscriptionParams{}
 	subscription, _ := client.Subscriptions.Create("cus_123456789", &params)
-  assert.Equal(t, subscription.Customer, "cus_123456789")
+	assert.Equal(t, subscription.Customer, "cus_123456789")
 	assert.Equal(t, subscription.Id, "sub_123456789")
 }
 
@@ -37,7 +50,7 @@ func TestSubscriptionsDelete(t *testing.T) {
 	setup()
 	defer teardown()
 	handleWithJSON("/customers/cus_123456789/subscriptions/sub_123456789", "subscriptions/subscription.json")
-  params := SubscriptionParams{}
+	params := SubscriptionParams{}
 	subscription, _ := client.Subscriptions.Delete("cus_123456789", "sub_123456789", &params)
 	assert.Equal(t, subscription.Id, "sub_123456789")
 }
@@ -51,34 +64,33 @@ func TestSubscriptionsAll(t *testing.T) {
 	assert.Equal(t, subscriptions.Data[0].Id, "sub_123456789")
 }
 
-func TestSubscriptionsAllWithFilters(t *testing.T) {
+func TestSubscriptionsAllWithParams(t *testing.T) {
 	setup()
 	defer teardown()
 	handleWithJSON("/customers/cus_123456789/subscriptions", "subscriptions/subscriptions.json")
-	subscriptions, _ := client.Subscriptions.AllWithFilters("cus_123456789", Filters{})
+	subscriptions, _ := client.Subscriptions.AllWithParams("cus_123456789", &SubscriptionListParams{})
 	assert.Equal(t, subscriptions.Count, 1)
 	assert.Equal(t, subscriptions.Data[0].Id, "sub_123456789")
 }
 
-func TestParseSubscriptionParams(t *testing.T) {
+func TestEncodeSubscriptionParams(t *testing.T) {
 	params := SubscriptionParams{
-    Plan: "plan",
-    Coupon: "coupon",
-    DisableProrate: true,
-    Quantity: 100,
-    TrialEnd: 123456789,
-    ApplicationFeePercent: 0.75,
+		Plan:                  String("plan"),
+		Coupon:                String("coupon"),
+		DisableProrate:        Bool(true),
+		Quantity:              Int(100),
+		TrialEnd:              Int(123456789),
+		ApplicationFeePercent: Float64(0.75),
 		CardParams: &CardParams{
-			Number: "4242424242424242",
+			Number: String("4242424242424242"),
 		},
 	}
-	values := url.Values{}
-	parseSubscriptionParams("create", &params, &values)
-  assert.Equal(t, values.Get("plan"), params.Plan)
-	assert.Equal(t, values.Get("coupon"), params.Coupon)
-  assert.Equal(t, values.Get("prorate"), "false")
-	assert.Equal(t, values.Get("quantity"), strconv.Itoa(params.Quantity))
-	assert.Equal(t, values.Get("trial_end"), strconv.Itoa(params.TrialEnd))
-  assert.Equal(t, values.Get("application_fee_percent"), "0.75")
-	assert.Equal(t, values.Get("card[number]"), params.CardParams.Number)
+	values, _ := encodeParams(&params)
+	assert.Equal(t, values.Get("plan"), *params.Plan)
+	assert.Equal(t, values.Get("coupon"), *params.Coupon)
+	assert.Equal(t, values.Get("prorate"), "false")
+	assert.Equal(t, values.Get("quantity"), strconv.Itoa(*params.Quantity))
+	assert.Equal(t, values.Get("trial_end"), strconv.Itoa(*params.TrialEnd))
+	assert.Equal(t, values.Get("application_fee_percent"), "0.75")
+	assert.Equal(t, values.Get("card[number]"), *params.CardParams.Number)
 }
@@ -1,8 +1,9 @@
 package stripe
 
-import "net/url"
+import "context"
 
 type Token struct {
+	APIResource
 	Id          string       `json:"id"`
 	Object      string       `json:"object"`
 	Livemode    bool         `json:"livemode"`
@@ -24,33 +25,34 @@ type TokenClient struct {
 // For more information:
 // https://stripe.com/docs/api#create_card_token
 // https://stripe.com/docs/api#create_bank_account_token
-func (c *TokenClient) Create(params *TokenParams) (*Token, error) {
-	token := Token{}
-	values := url.Values{}
-	parseTokenParams(params, &values)
-	err := c.client.post("/tokens", values, &token)
-	return &token, err
+func (c *TokenClient) Create(params *TokenParams, opts ...RequestOption) (*Token, error) {
+	return c.CreateContext(context.Background(), params, opts...)
 }
 
-func (c *TokenClient) Retrieve(id string) (*Token, error) {
+// CreateContext is like Create, but uses ctx for the underlying request.
+func (c *TokenClient) CreateContext(ctx context.Context, params *TokenParams, opts ...RequestOption) (*Token, error) {
 	token := Token{}
-	err := c.client.get("/tokens/"+id, nil, &token)
-	return &token, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.post(ctx, "/tokens", values, &token, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
 }
 
-// parseTokenParams takes a pointer to a TokenParams and a pointer to a
-// url.Values. It iterates over everything in the TokenParams struct and Adds
-// what is there to the url.Values. If both CardParams and BankAccountParams
-// are present, it ignores the BankAccountParams, giving preference to the
-// CardParams.
-func parseTokenParams(params *TokenParams, values *url.Values) {
+func (c *TokenClient) Retrieve(id string, opts ...RequestOption) (*Token, error) {
+	return c.RetrieveContext(context.Background(), id, opts...)
+}
 
-	// Use parseCardParams/parseBankAccountParams (whichever is appropriate)
-	if params.CardParams != nil {
-		parseCardParams(params.CardParams, values, true)
-	} else if params.BankAccountParams != nil {
-		parseBankAccountParams(params.BankAccountParams, values)
+// RetrieveContext is like Retrieve, but uses ctx for the underlying request.
+func (c *TokenClient) RetrieveContext(ctx context.Context, id string, opts ...RequestOption) (*Token, error) {
+	token := Token{}
+	err := c.client.get(ctx, "/tokens/"+id, nil, &token, opts...)
+	if err != nil {
+		return nil, err
 	}
-
-	addParamsToValues(params, values)
+	return &token, nil
 }
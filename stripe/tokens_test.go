@@ -2,7 +2,6 @@ package stripe
 
 import (
 	"github.com/bmizerany/assert"
-	"net/url"
 	"testing"
 )
 
@@ -23,27 +22,23 @@ func TestTokensRetrieve(t *testing.T) {
 	assert.Equal(t, token.Id, "tok_123456789")
 }
 
-func TestParseTokenParams(t *testing.T) {
+func TestEncodeTokenParams(t *testing.T) {
 	params := TokenParams{
-		Customer: "cus_123456789",
+		Customer: String("cus_123456789"),
 		CardParams: &CardParams{
-			Number: "4242424242424242",
-		},
-		BankAccountParams: &BankAccountParams{
-			AccountNumber: "123456789",
+			Number: String("4242424242424242"),
 		},
 	}
-	values := url.Values{}
 
 	// Card Token
-	parseTokenParams(&params, &values)
-	assert.Equal(t, values.Get("customer"), params.Customer)
-	assert.Equal(t, values.Get("card[number]"), params.CardParams.Number)
-	assert.NotEqual(t, values.Get("bank_account[account_number]"), params.BankAccountParams.AccountNumber)
+	values, _ := encodeParams(&params)
+	assert.Equal(t, values.Get("customer"), *params.Customer)
+	assert.Equal(t, values.Get("card[number]"), *params.CardParams.Number)
+	assert.Equal(t, values.Get("bank_account[account_number]"), "")
 
 	// Bank Account Token
 	params.CardParams = nil
-	values = url.Values{}
-	parseTokenParams(&params, &values)
-	assert.Equal(t, values.Get("bank_account[account_number]"), params.BankAccountParams.AccountNumber)
+	params.BankAccountParams = &BankAccountParams{AccountNumber: String("123456789")}
+	values, _ = encodeParams(&params)
+	assert.Equal(t, values.Get("bank_account[account_number]"), *params.BankAccountParams.AccountNumber)
 }
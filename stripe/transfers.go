@@ -1,23 +1,22 @@
 package stripe
 
-import (
-	"net/url"
-)
+import "context"
 
 type Transfer struct {
-	Id                  string       `json:"id"`
-	Object              string       `json:"object"`
-	Livemode            bool         `json:"livemode"`
-	Amount              int64        `json:"amount"`
-	Currency            string       `json:"currency"`
-	Date                int64        `json:"date"`
-	Status              string       `json:"status"`
-	Account             *BankAccount `json:"account"`
-	BalanceTransaction  string       `json:"balance_transaction"`
-	Description         string       `json:"description"`
-	Recipient           string       `json:"recipient"`
+	APIResource
+	Id                   string       `json:"id"`
+	Object               string       `json:"object"`
+	Livemode             bool         `json:"livemode"`
+	Amount               int64        `json:"amount"`
+	Currency             string       `json:"currency"`
+	Date                 int64        `json:"date"`
+	Status               string       `json:"status"`
+	Account              *BankAccount `json:"account"`
+	BalanceTransaction   string       `json:"balance_transaction"`
+	Description          string       `json:"description"`
+	Recipient            string       `json:"recipient"`
 	StatementDescription string       `json:"statement_description"`
-	Metadata            Metadata     `json:"metadata"`
+	Metadata             Metadata     `json:"metadata"`
 }
 
 type TransferListResponse struct {
@@ -25,6 +24,17 @@ type TransferListResponse struct {
 	Data []Transfer `json:"data"`
 }
 
+// TransferIter is an Iter over Transfers.
+type TransferIter struct {
+	*Iter
+}
+
+// Transfer returns the Transfer Next advanced to.
+func (it *TransferIter) Transfer() *Transfer {
+	t, _ := it.Current().(*Transfer)
+	return t
+}
+
 type TransferClient struct {
 	client Client
 }
@@ -32,71 +42,135 @@ type TransferClient struct {
 // Create creates a transfer.
 //
 // For more information: https://stripe.com/docs/api#create_transfer
-func (c *TransferClient) Create(params *TransferParams) (*Transfer, error) {
+func (c *TransferClient) Create(params *TransferParams, opts ...RequestOption) (*Transfer, error) {
+	return c.CreateContext(context.Background(), params, opts...)
+}
+
+// CreateContext is like Create, but uses ctx for the underlying request.
+func (c *TransferClient) CreateContext(ctx context.Context, params *TransferParams, opts ...RequestOption) (*Transfer, error) {
 	transfer := Transfer{}
-	values := url.Values{}
-	parseTransferParams(params, &values)
-	err := c.client.post("/transfers", values, &transfer)
-	return &transfer, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.post(ctx, "/transfers", values, &transfer, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
 }
 
 // Retrieve loads a transfer.
 //
 // For more information: https://stripe.com/docs/api#retrieve_transfer
-func (c *TransferClient) Retrieve(id string) (*Transfer, error) {
+func (c *TransferClient) Retrieve(id string, opts ...RequestOption) (*Transfer, error) {
+	return c.RetrieveContext(context.Background(), id, opts...)
+}
+
+// RetrieveContext is like Retrieve, but uses ctx for the underlying request.
+func (c *TransferClient) RetrieveContext(ctx context.Context, id string, opts ...RequestOption) (*Transfer, error) {
 	transfer := Transfer{}
-	err := c.client.get("/transfers/"+id, nil, &transfer)
-	return &transfer, err
+	err := c.client.get(ctx, "/transfers/"+id, nil, &transfer, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
 }
 
 // Update updates a transfer.
 //
 // For more information: https://stripe.com/docs/api#update_transfer
-func (c *TransferClient) Update(id string, params *TransferParams) (*Transfer, error) {
+func (c *TransferClient) Update(id string, params *TransferParams, opts ...RequestOption) (*Transfer, error) {
+	return c.UpdateContext(context.Background(), id, params, opts...)
+}
+
+// UpdateContext is like Update, but uses ctx for the underlying request.
+func (c *TransferClient) UpdateContext(ctx context.Context, id string, params *TransferParams, opts ...RequestOption) (*Transfer, error) {
 	transfer := Transfer{}
-	values := url.Values{}
-	parseTransferParams(params, &values)
-	err := c.client.post("/transfers/"+id, values, &transfer)
-	return &transfer, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.post(ctx, "/transfers/"+id, values, &transfer, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
 }
 
 // Cancel cancels a transfer.
 //
 // For more information: https://stripe.com/docs/api/#cancel_transfer
-func (c *TransferClient) Cancel(id string) (*Transfer, error) {
+func (c *TransferClient) Cancel(id string, opts ...RequestOption) (*Transfer, error) {
+	return c.CancelContext(context.Background(), id, opts...)
+}
+
+// CancelContext is like Cancel, but uses ctx for the underlying request.
+func (c *TransferClient) CancelContext(ctx context.Context, id string, opts ...RequestOption) (*Transfer, error) {
 	transfer := Transfer{}
-	err := c.client.post("/transfers/"+id+"/cancel", nil, &transfer)
-	return &transfer, err
+	err := c.client.post(ctx, "/transfers/"+id+"/cancel", nil, &transfer, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &transfer, nil
 }
 
-// All lists the first 10 transfers. It calls AllWithFilters with a blank
-// Filters so all defaults are used.
+// All lists the first 10 transfers. It calls AllWithParams with no params so
+// all defaults are used.
 //
 // For more information: https://stripe.com/docs/api#list_transfers
-func (c *TransferClient) All() (*TransferListResponse, error) {
-	return c.AllWithFilters(Filters{})
+func (c *TransferClient) All(opts ...RequestOption) (*TransferListResponse, error) {
+	return c.AllContext(context.Background(), opts...)
+}
+
+// AllContext is like All, but uses ctx for the underlying request.
+func (c *TransferClient) AllContext(ctx context.Context, opts ...RequestOption) (*TransferListResponse, error) {
+	return c.AllWithParamsContext(ctx, nil, opts...)
 }
 
-// AllWithFilters takes a Filters and applies all valid filters for the action.
+// AllWithParams lists the Transfers matching params.
 //
 // For more information: https://stripe.com/docs/api#list_transfers
-func (c *TransferClient) AllWithFilters(filters Filters) (*TransferListResponse, error) {
+func (c *TransferClient) AllWithParams(params *TransferListParams, opts ...RequestOption) (*TransferListResponse, error) {
+	return c.AllWithParamsContext(context.Background(), params, opts...)
+}
+
+// AllWithParamsContext is like AllWithParams, but uses ctx for the
+// underlying request.
+func (c *TransferClient) AllWithParamsContext(ctx context.Context, params *TransferListParams, opts ...RequestOption) (*TransferListResponse, error) {
 	response := TransferListResponse{}
-	values := url.Values{}
-	addFiltersToValues([]string{"count", "offset", "recipient", "status"}, filters, &values)
-	err := c.client.get("/transfers", values, &response)
-	return &response, err
+	values, err := encodeParams(params)
+	if err != nil {
+		return nil, err
+	}
+	err = c.client.get(ctx, "/transfers", values, &response, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
 }
 
-// parseTransferParams takes a pointer to a TransferParams and a pointer to a url.Values,
-// it iterates over everything in the TransferParams struct and Adds what is there
-// to the url.Values.
-func parseTransferParams(params *TransferParams, values *url.Values) {
+// Iter returns an iterator over the Transfers matching params, fetching
+// pages as they are needed.
+//
+// For more information: https://stripe.com/docs/api#pagination
+func (c *TransferClient) Iter(params *TransferListParams, opts ...RequestOption) *TransferIter {
+	return c.IterContext(context.Background(), params, opts...)
+}
 
-	// Use parseMetaData from metadata.go to setup the metadata param
-	if params.Metadata != nil {
-		parseMetadata(params.Metadata, values)
+// IterContext is like Iter, but uses ctx for the underlying requests.
+func (c *TransferClient) IterContext(ctx context.Context, params *TransferListParams, opts ...RequestOption) *TransferIter {
+	p := TransferListParams{}
+	if params != nil {
+		p = *params
 	}
 
-	addParamsToValues(params, values)
+	return &TransferIter{newIter(ctx, p.ListParams, func(ctx context.Context, page ListParams) ([]interface{}, ListResponse, error) {
+		p.ListParams = page
+		list, err := c.AllWithParamsContext(ctx, &p, opts...)
+		if err != nil {
+			return nil, ListResponse{}, err
+		}
+		return pageOf(list.Data), list.ListResponse, nil
+	})}
 }
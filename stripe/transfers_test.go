@@ -2,7 +2,6 @@ package stripe
 
 import (
 	"github.com/bmizerany/assert"
-	"net/url"
 	"strconv"
 	"testing"
 )
@@ -49,32 +48,31 @@ func TestTransfersAll(t *testing.T) {
 	assert.Equal(t, transfers.Data[0].Id, "tr_123456789")
 }
 
-func TestTransfersAllWithFilters(t *testing.T) {
+func TestTransfersAllWithParams(t *testing.T) {
 	setup()
 	defer teardown()
 	handleWithJSON("/transfers", "transfers/transfers.json")
-	transfers, _ := client.Transfers.AllWithFilters(Filters{})
+	transfers, _ := client.Transfers.AllWithParams(&TransferListParams{})
 	assert.Equal(t, transfers.Count, 1)
 	assert.Equal(t, transfers.Data[0].Id, "tr_123456789")
 }
 
-func TestParseTransferParams(t *testing.T) {
+func TestEncodeTransferParams(t *testing.T) {
 	params := TransferParams{
-		Amount:              1000,
-		Currency:            "USD",
-		Recipient:           "rp_123456789",
-		Description:         "Description",
-		StatementDescription: "Statement Descripion",
+		Amount:               Int(1000),
+		Currency:             String("USD"),
+		Recipient:            String("rp_123456789"),
+		Description:          String("Description"),
+		StatementDescription: String("Statement Descripion"),
 		Metadata: Metadata{
 			"foo": "bar",
 		},
 	}
-	values := url.Values{}
-	parseTransferParams(&params, &values)
-	assert.Equal(t, values.Get("amount"), strconv.Itoa(params.Amount))
-	assert.Equal(t, values.Get("currency"), params.Currency)
-	assert.Equal(t, values.Get("recipient"), params.Recipient)
-	assert.Equal(t, values.Get("description"), params.Description)
-	assert.Equal(t, values.Get("statement_description"), params.StatementDescription)
+	values, _ := encodeParams(&params)
+	assert.Equal(t, values.Get("amount"), strconv.Itoa(*params.Amount))
+	assert.Equal(t, values.Get("currency"), *params.Currency)
+	assert.Equal(t, values.Get("recipient"), *params.Recipient)
+	assert.Equal(t, values.Get("description"), *params.Description)
+	assert.Equal(t, values.Get("statement_description"), *params.StatementDescription)
 	assert.Equal(t, values.Get("metadata[foo]"), params.Metadata["foo"])
 }
@@ -0,0 +1,223 @@
+package stripetest
+
+import (
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"sort"
+	"strings"
+	"time"
+)
+
+// insufficientFunds is the message of the error for a transfer of more than
+// the available balance.
+const insufficientFunds = "Insufficient funds in Stripe account. In test mode, you can add funds to your available balance (bypassing your pending balance) by creating a charge with 4000 0000 0000 0077 as the card number."
+
+// newBalanceTransaction records a BalanceTransaction of the given type for
+// the source, of amount less fee. Unless available, its funds are pending
+// for two days.
+func (s *Server) newBalanceTransaction(txnType, sourceId string, amount, fee int64, currency string, available bool) *stripe.BalanceTransaction {
+	txn := &stripe.BalanceTransaction{
+		Id:          s.newId("txn"),
+		Object:      "balance_transaction",
+		Source:      stripe.BalanceTransactionSource{Id: sourceId},
+		Amount:      amount,
+		Currency:    currency,
+		Net:         amount - fee,
+		Type:        txnType,
+		Created:     s.now.Unix(),
+		AvailableOn: s.now.Unix(),
+		Status:      "available",
+		Fee:         fee,
+		FeeDetails:  []stripe.FeeDetails{},
+	}
+	if !available {
+		txn.Status, txn.AvailableOn = "pending", s.now.Add(48*time.Hour).Unix()
+	}
+	s.put("txn", txn.Id, txn)
+	return txn
+}
+
+// balance returns the Balance, the net of the balance transactions by
+// currency and status.
+func (s *Server) balance() *stripe.Balance {
+	sums := map[string]map[string]int64{"available": {}, "pending": {}}
+	for _, o := range s.all("txn") {
+		txn := o.(*stripe.BalanceTransaction)
+		sums[txn.Status][txn.Currency] += txn.Net
+	}
+
+	funds := func(sum map[string]int64) []stripe.Fund {
+		if len(sum) == 0 {
+			return []stripe.Fund{{Amount: 0, Currency: "usd"}}
+		}
+		var funds []stripe.Fund
+		for currency, amount := range sum {
+			funds = append(funds, stripe.Fund{Amount: amount, Currency: currency})
+		}
+		sort.Slice(funds, func(i, j int) bool { return funds[i].Currency < funds[j].Currency })
+		return funds
+	}
+
+	return &stripe.Balance{Object: "balance", Available: funds(sums["available"]), Pending: funds(sums["pending"])}
+}
+
+// available returns the available balance in the currency.
+func (s *Server) available(currency string) int64 {
+	for _, fund := range s.balance().Available {
+		if fund.Currency == currency {
+			return fund.Amount
+		}
+	}
+	return 0
+}
+
+// settleBalance makes the pending funds that are due available.
+func (s *Server) settleBalance() {
+	settled := false
+	for _, o := range s.all("txn") {
+		if txn := o.(*stripe.BalanceTransaction); txn.Status == "pending" && txn.AvailableOn <= s.now.Unix() {
+			txn.Status, settled = "available", true
+		}
+	}
+	if settled {
+		s.emit("balance.available", s.balance(), nil)
+	}
+}
+
+// retrieveBalance answers GET /balance.
+func (s *Server) retrieveBalance(r *request) (interface{}, *apiError) {
+	return s.balance(), nil
+}
+
+// listBalanceTransactions answers GET /balance/history.
+func (s *Server) listBalanceTransactions(r *request) (interface{}, *apiError) {
+	txns := s.filter("txn", func(o interface{}) bool {
+		txn := o.(*stripe.BalanceTransaction)
+		switch {
+		case r.has("type") && r.str("type") != txn.Type,
+			r.has("currency") && r.str("currency") != txn.Currency,
+			r.has("source") && r.str("source") != txn.Source.Id:
+			return false
+		}
+		return r.inRange("created", txn.Created) && r.inRange("available_on", txn.AvailableOn)
+	})
+	return r.page("/balance/history", txns)
+}
+
+// retrieveBalanceTransaction answers GET /balance/history/{id}.
+func (s *Server) retrieveBalanceTransaction(r *request) (interface{}, *apiError) {
+	txn := s.get("txn", r.ids[0])
+	if txn == nil {
+		return nil, notFound("id", "No such balance transaction: %s", r.ids[0])
+	}
+	return txn, nil
+}
+
+// transfer returns the Transfer with the given id.
+func (s *Server) transfer(id string) (*stripe.Transfer, *apiError) {
+	transfer, _ := s.get("transfer", id).(*stripe.Transfer)
+	if transfer == nil {
+		return nil, notFound("id", "No such transfer: %s", id)
+	}
+	return transfer, nil
+}
+
+// payOutTransfers pays the pending transfers that are due.
+func (s *Server) payOutTransfers() {
+	for _, o := range s.all("transfer") {
+		if transfer := o.(*stripe.Transfer); transfer.Status == "pending" && transfer.Date <= s.now.Unix() {
+			before := snapshot(transfer)
+			transfer.Status = "paid"
+			s.emit("transfer.paid", transfer, changes(before, transfer))
+		}
+	}
+}
+
+// createTransfer answers POST /transfers. Only transfers to the bank account
+// of the account itself, the recipient "self", are faked. They are taken from
+// the available balance at once, and paid a day later.
+func (s *Server) createTransfer(r *request) (interface{}, *apiError) {
+	r.require("amount", "currency")
+	amount, currency := r.int("amount"), strings.ToLower(r.str("currency"))
+	if r.err != nil {
+		return nil, r.err
+	}
+	if amount <= 0 {
+		return nil, invalidRequest("amount", "Invalid integer: %d", amount)
+	}
+	if recipient := r.str("recipient"); recipient != "" && recipient != "self" {
+		return nil, invalidRequest("recipient", "No such recipient: %s", recipient)
+	}
+	if amount > s.available(currency) {
+		return nil, invalidRequest("amount", insufficientFunds)
+	}
+
+	transfer := &stripe.Transfer{
+		Id:                   s.newId("tr"),
+		Object:               "transfer",
+		Amount:               amount,
+		Currency:             currency,
+		Date:                 s.now.Add(24 * time.Hour).Unix(),
+		Status:               "pending",
+		Description:          r.str("description"),
+		StatementDescription: r.str("statement_description"),
+		Metadata:             r.metadata(nil),
+	}
+	transfer.BalanceTransaction = s.newBalanceTransaction("transfer", transfer.Id, -amount, 0, currency, true).Id
+	s.put("transfer", transfer.Id, transfer)
+	s.emit("transfer.created", transfer, nil)
+	return transfer, nil
+}
+
+// retrieveTransfer answers GET /transfers/{id}.
+func (s *Server) retrieveTransfer(r *request) (interface{}, *apiError) {
+	return s.transfer(r.ids[0])
+}
+
+// updateTransfer answers POST /transfers/{id}.
+func (s *Server) updateTransfer(r *request) (interface{}, *apiError) {
+	transfer, err := s.transfer(r.ids[0])
+	if err != nil {
+		return nil, err
+	}
+
+	before := snapshot(transfer)
+	if r.has("description") {
+		transfer.Description = r.str("description")
+	}
+	transfer.Metadata = r.metadata(transfer.Metadata)
+	s.emitUpdate("transfer.updated", before, transfer)
+	return transfer, nil
+}
+
+// cancelTransfer answers POST /transfers/{id}/cancel. The amount of a pending
+// transfer goes back to the available balance.
+func (s *Server) cancelTransfer(r *request) (interface{}, *apiError) {
+	transfer, err := s.transfer(r.ids[0])
+	if err != nil {
+		return nil, err
+	}
+	if transfer.Status != "pending" {
+		return nil, invalidRequest("", "Transfer %s cannot be canceled, because it is %s.", transfer.Id, transfer.Status)
+	}
+
+	before := snapshot(transfer)
+	transfer.Status = "canceled"
+	s.newBalanceTransaction("transfer_cancel", transfer.Id, transfer.Amount, 0, transfer.Currency, true)
+	s.emitUpdate("transfer.updated", before, transfer)
+	return transfer, nil
+}
+
+// listTransfers answers GET /transfers.
+func (s *Server) listTransfers(r *request) (interface{}, *apiError) {
+	transfers := s.filter("transfer", func(o interface{}) bool {
+		transfer := o.(*stripe.Transfer)
+		if r.has("status") && r.str("status") != transfer.Status {
+			return false
+		}
+		if recipient := r.str("recipient"); recipient != "" && recipient != "self" {
+			return false
+		}
+		return r.inRange("date", transfer.Date)
+	})
+	return r.page("/transfers", transfers)
+}
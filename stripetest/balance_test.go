@@ -0,0 +1,97 @@
+package stripetest
+
+import (
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"github.com/bmizerany/assert"
+	"testing"
+	"time"
+)
+
+func TestBalancePending(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	client.Charges.Create(&stripe.ChargeParams{Amount: stripe.Int(1000), Currency: stripe.String("usd"), CardParams: cardParams(CardVisa)})
+
+	balance, err := client.Balance.Retrieve()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, balance.Available, []stripe.Fund{{Amount: 0, Currency: "usd"}})
+	assert.Equal(t, balance.Pending, []stripe.Fund{{Amount: 941, Currency: "usd"}})
+
+	server.Advance(47 * time.Hour)
+	balance, _ = client.Balance.Retrieve()
+	assert.Equal(t, balance.Available[0].Amount, int64(0))
+
+	server.Advance(time.Hour)
+	balance, _ = client.Balance.Retrieve()
+	assert.Equal(t, balance.Available[0].Amount, int64(941))
+	assert.Equal(t, balance.Pending[0].Amount, int64(0))
+
+	events, _ := client.Events.AllWithParams(&stripe.EventListParams{Type: "balance.available"})
+	assert.Equal(t, events.Count, 1)
+}
+
+func TestBalanceAvailableCard(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	client.Charges.Create(&stripe.ChargeParams{Amount: stripe.Int(1000), Currency: stripe.String("usd"), CardParams: cardParams(CardAvailableBalance)})
+	balance, _ := client.Balance.Retrieve()
+	assert.Equal(t, balance.Available[0].Amount, int64(941))
+}
+
+func TestBalanceHistory(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	charge, _ := client.Charges.Create(&stripe.ChargeParams{Amount: stripe.Int(1000), Currency: stripe.String("usd"), CardParams: cardParams(CardVisa)})
+	client.Charges.Refund(charge.Id, nil)
+
+	history, err := client.Balance.History()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, history.Count, 2)
+	assert.Equal(t, history.Data[0].Type, "refund")
+	assert.Equal(t, history.Data[1].Type, "charge")
+}
+
+func TestBalanceTransfers(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	params := &stripe.TransferParams{Amount: stripe.Int(500), Currency: stripe.String("usd"), Recipient: stripe.String("self")}
+	_, err := client.Transfers.Create(params)
+	assert.Equal(t, apiErr(err).Err.Message, insufficientFunds)
+
+	client.Charges.Create(&stripe.ChargeParams{Amount: stripe.Int(1000), Currency: stripe.String("usd"), CardParams: cardParams(CardAvailableBalance)})
+	transfer, err := client.Transfers.Create(params)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, transfer.Status, "pending")
+
+	balance, _ := client.Balance.Retrieve()
+	assert.Equal(t, balance.Available[0].Amount, int64(441))
+
+	server.Advance(24 * time.Hour)
+	transfer, _ = client.Transfers.Retrieve(transfer.Id)
+	assert.Equal(t, transfer.Status, "paid")
+
+	_, err = client.Transfers.Cancel(transfer.Id)
+	assert.Equal(t, apiErr(err).Err.Type, "invalid_request_error")
+}
+
+func TestBalanceTransferCancel(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	client.Charges.Create(&stripe.ChargeParams{Amount: stripe.Int(1000), Currency: stripe.String("usd"), CardParams: cardParams(CardAvailableBalance)})
+	transfer, _ := client.Transfers.Create(&stripe.TransferParams{Amount: stripe.Int(500), Currency: stripe.String("usd")})
+
+	transfer, err := client.Transfers.Cancel(transfer.Id)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, transfer.Status, "canceled")
+
+	balance, _ := client.Balance.Retrieve()
+	assert.Equal(t, balance.Available[0].Amount, int64(941))
+
+	transfers, _ := client.Transfers.AllWithParams(&stripe.TransferListParams{Status: "canceled"})
+	assert.Equal(t, transfers.Count, 1)
+}
@@ -0,0 +1,425 @@
+package stripetest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// The card numbers that behave as they do in Stripe's test mode. Any other
+// number that passes the Luhn check is accepted, as CardVisa is.
+//
+// For more information: https://stripe.com/docs/testing
+const (
+	CardVisa       = "4242424242424242"
+	CardMasterCard = "5555555555554444"
+	CardAmex       = "378282246310005"
+	CardDiscover   = "6011111111111117"
+
+	// CardAddressChecksFail is accepted, but fails the address_line1 and
+	// address_zip checks.
+	CardAddressChecksFail = "4000000000000010"
+
+	// CardCVCCheckFails is accepted, but fails the cvc check.
+	CardCVCCheckFails = "4000000000000101"
+
+	// CardAvailableBalance is charged with funds that are available at once,
+	// rather than pending.
+	CardAvailableBalance = "4000000000000077"
+
+	// CardChargeFails can be added to a customer, but charging it is declined.
+	CardChargeFails = "4000000000000341"
+
+	// CardDeclined, CardIncorrectCVC, CardExpired and CardProcessingError are
+	// declined, with the codes card_declined, incorrect_cvc, expired_card and
+	// processing_error, both when charged and when added to a customer.
+	CardDeclined        = "4000000000000002"
+	CardIncorrectCVC    = "4000000000000127"
+	CardExpired         = "4000000000000069"
+	CardProcessingError = "4000000000000119"
+
+	// CardIncorrectNumber fails the Luhn check.
+	CardIncorrectNumber = "4242424242424241"
+)
+
+// testCard is how a test card number behaves.
+type testCard struct {
+	declineCode       string // when charged
+	attaches          bool   // to a customer, despite the declineCode
+	available         bool   // funds charged are available at once
+	cvcFails          bool
+	addressChecksFail bool
+}
+
+var testCards = map[string]testCard{
+	CardAddressChecksFail: {addressChecksFail: true},
+	CardCVCCheckFails:     {cvcFails: true},
+	CardAvailableBalance:  {available: true},
+	CardChargeFails:       {declineCode: "card_declined", attaches: true},
+	CardDeclined:          {declineCode: "card_declined"},
+	CardIncorrectCVC:      {declineCode: "incorrect_cvc"},
+	CardExpired:           {declineCode: "expired_card"},
+	CardProcessingError:   {declineCode: "processing_error"},
+}
+
+// cardMessages are the messages of the card_errors, by code.
+var cardMessages = map[string]string{
+	"card_declined":        "Your card was declined.",
+	"incorrect_cvc":        "Your card's security code is incorrect.",
+	"expired_card":         "Your card has expired.",
+	"processing_error":     "An error occurred while processing your card. Try again in a little bit.",
+	"incorrect_number":     "Your card number is incorrect.",
+	"invalid_number":       "This card number looks invalid.",
+	"invalid_expiry_month": "Your card's expiration month is invalid.",
+	"invalid_expiry_year":  "Your card's expiration year is invalid.",
+	"invalid_cvc":          "Your card's security code is invalid.",
+	"missing":              "Cannot charge a customer that has no active card.",
+}
+
+// cardError returns the card_error with the given code.
+func cardError(code, param string) *apiError {
+	e := &apiError{Status: http.StatusPaymentRequired, Type: "card_error", Message: cardMessages[code], Code: code, Param: param}
+	if code == "card_declined" {
+		e.DeclineCode = "generic_decline"
+	}
+	return e
+}
+
+// brand returns the brand of the card number, as Card.Type.
+func brand(number string) string {
+	switch {
+	case strings.HasPrefix(number, "4"):
+		return "Visa"
+	case number >= "51" && number < "56":
+		return "MasterCard"
+	case strings.HasPrefix(number, "34"), strings.HasPrefix(number, "37"):
+		return "American Express"
+	case strings.HasPrefix(number, "6011"), strings.HasPrefix(number, "65"):
+		return "Discover"
+	case strings.HasPrefix(number, "35"):
+		return "JCB"
+	case strings.HasPrefix(number, "30"), strings.HasPrefix(number, "36"), strings.HasPrefix(number, "38"):
+		return "Diners Club"
+	}
+	return "Unknown"
+}
+
+// luhn reports whether the digits of number pass the Luhn check.
+func luhn(number string) bool {
+	sum := 0
+	for i := range number {
+		d := int(number[len(number)-1-i] - '0')
+		if i%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// onlyDigits reports whether s is made of digits only.
+func onlyDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// newCard returns the Card given by the params under prefix, such as card[],
+// or at the top level if prefix is "". Its number is kept in s.cardNumbers.
+func (s *Server) newCard(r *request, prefix string) (*stripe.Card, *apiError) {
+	field := func(name string) string {
+		if prefix == "" {
+			return name
+		}
+		return prefix + "[" + name + "]"
+	}
+
+	number := strings.Replace(r.str(field("number")), " ", "", -1)
+	switch {
+	case !r.has(field("number")):
+		return nil, invalidRequest(field("number"), "Missing required param: %s.", field("number"))
+	case !onlyDigits(number) || len(number) < 12 || len(number) > 19:
+		return nil, cardError("invalid_number", "number")
+	case !luhn(number):
+		return nil, cardError("incorrect_number", "number")
+	}
+
+	month, year := r.int(field("exp_month")), r.int(field("exp_year"))
+	if r.err != nil {
+		return nil, r.err
+	}
+	now := s.now.UTC()
+	switch {
+	case month < 1 || month > 12:
+		return nil, cardError("invalid_expiry_month", "exp_month")
+	case year < int64(now.Year()):
+		return nil, cardError("invalid_expiry_year", "exp_year")
+	case year == int64(now.Year()) && month < int64(now.Month()):
+		return nil, cardError("invalid_expiry_month", "exp_month")
+	}
+
+	cvc := r.str(field("cvc"))
+	if r.has(field("cvc")) && (!onlyDigits(cvc) || len(cvc) < 3 || len(cvc) > 4) {
+		return nil, cardError("invalid_cvc", "cvc")
+	}
+
+	behaviour := testCards[number]
+	check := func(given bool, fails bool) string {
+		switch {
+		case !given:
+			return ""
+		case fails:
+			return "fail"
+		}
+		return "pass"
+	}
+
+	fingerprint := sha256.Sum256([]byte(number))
+	card := &stripe.Card{
+		Id:                s.newId("card"),
+		Object:            "card",
+		ExpMonth:          month,
+		ExpYear:           year,
+		Fingerprint:       hex.EncodeToString(fingerprint[:8]),
+		Last4:             number[len(number)-4:],
+		Type:              brand(number),
+		AddressCity:       r.str(field("address_city")),
+		AddressCountry:    r.str(field("address_country")),
+		AddressLine1:      r.str(field("address_line1")),
+		AddressLine1Check: check(r.str(field("address_line1")) != "", behaviour.addressChecksFail),
+		AddressLine2:      r.str(field("address_line2")),
+		AddressState:      r.str(field("address_state")),
+		AddressZip:        r.str(field("address_zip")),
+		AddressZipCheck:   check(r.str(field("address_zip")) != "", behaviour.addressChecksFail),
+		Country:           "US",
+		CVCCheck:          check(cvc != "", behaviour.cvcFails),
+		Name:              r.str(field("name")),
+	}
+	s.cardNumbers[card.Id] = number
+	return card, nil
+}
+
+// cardParam returns the Card given by the card param, either the id of a
+// Token or the details of a card under card[], or nil if there is none.
+func (s *Server) cardParam(r *request) (*stripe.Card, *apiError) {
+	if id := r.str("card"); id != "" {
+		token, _ := s.get("token", id).(*stripe.Token)
+		switch {
+		case token == nil:
+			return nil, invalidRequest("card", "No such token: %s", id)
+		case token.Used:
+			return nil, invalidRequest("card", "You cannot use a Stripe token more than once: %s.", id)
+		case token.Card == nil:
+			return nil, invalidRequest("card", "Invalid token id: %s", id)
+		}
+		token.Used = true
+		card := *token.Card
+		return &card, nil
+	}
+
+	if !r.has("card[number]") {
+		return nil, nil
+	}
+	return s.newCard(r, "card")
+}
+
+// attach adds card to the customer, unless its number is declined when added
+// to a customer. The card becomes the default card of the customer if it has
+// none.
+func (s *Server) attach(customer *stripe.Customer, card *stripe.Card) *apiError {
+	if behaviour := testCards[s.cardNumbers[card.Id]]; behaviour.declineCode != "" && !behaviour.attaches {
+		return cardError(behaviour.declineCode, "")
+	}
+
+	card.Customer = customer.Id
+	s.put("card", card.Id, card)
+	if customer.DefaultCard == "" {
+		customer.DefaultCard = card.Id
+	}
+	return nil
+}
+
+// cardsOf returns the Cards of the customer, oldest first.
+func (s *Server) cardsOf(customerId string) []interface{} {
+	return s.filter("card", func(o interface{}) bool {
+		return o.(*stripe.Card).Customer == customerId
+	})
+}
+
+// customerCard returns the Card with the given id of the customer in the
+// path.
+func (s *Server) customerCard(r *request) (*stripe.Customer, *stripe.Card, *apiError) {
+	customer, err := s.customer(r.ids[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	card, _ := s.get("card", r.ids[1]).(*stripe.Card)
+	if card == nil || card.Customer != customer.Id {
+		return nil, nil, notFound("id", "Customer %s does not have card with ID %s", customer.Id, r.ids[1])
+	}
+	return customer, card, nil
+}
+
+// createToken answers POST /tokens.
+func (s *Server) createToken(r *request) (interface{}, *apiError) {
+	token := &stripe.Token{Object: "token", Created: s.now.Unix()}
+
+	switch {
+	case r.has("card[number]"):
+		card, err := s.newCard(r, "card")
+		if err != nil {
+			return nil, err
+		}
+		token.Type, token.Card = "card", card
+
+	case r.has("bank_account[account_number]"):
+		r.require("bank_account[country]", "bank_account[routing_number]")
+		if r.err != nil {
+			return nil, r.err
+		}
+		number := r.str("bank_account[account_number]")
+		if len(number) < 4 {
+			return nil, invalidRequest("bank_account[account_number]", "Invalid account number")
+		}
+		fingerprint := sha256.Sum256([]byte(r.str("bank_account[routing_number]") + number))
+		token.Type = "bank_account"
+		token.BankAccount = &stripe.BankAccount{
+			Id:          s.newId("ba"),
+			Object:      "bank_account",
+			BankName:    "STRIPE TEST BANK",
+			Last4:       number[len(number)-4:],
+			Country:     r.str("bank_account[country]"),
+			Currency:    "usd",
+			Fingerprint: hex.EncodeToString(fingerprint[:8]),
+		}
+
+	default:
+		return nil, invalidRequest("card", "You must supply either a card or a bank account to create a token.")
+	}
+
+	token.Id = s.newId("tok")
+	s.put("token", token.Id, token)
+	return token, nil
+}
+
+// retrieveToken answers GET /tokens/{id}.
+func (s *Server) retrieveToken(r *request) (interface{}, *apiError) {
+	token := s.get("token", r.ids[0])
+	if token == nil {
+		return nil, notFound("id", "No such token: %s", r.ids[0])
+	}
+	return token, nil
+}
+
+// createCard answers POST /customers/{id}/cards.
+func (s *Server) createCard(r *request) (interface{}, *apiError) {
+	customer, err := s.customer(r.ids[0])
+	if err != nil {
+		return nil, err
+	}
+
+	card, err := s.cardParam(r)
+	if err != nil {
+		return nil, err
+	}
+	if card == nil {
+		return nil, invalidRequest("card", "Missing required param: card.")
+	}
+
+	before := snapshot(s.render(customer))
+	if err := s.attach(customer, card); err != nil {
+		return nil, err
+	}
+	s.emit("customer.card.created", card, nil)
+	s.emitUpdate("customer.updated", before, s.render(customer))
+	return card, nil
+}
+
+// listCards answers GET /customers/{id}/cards.
+func (s *Server) listCards(r *request) (interface{}, *apiError) {
+	customer, err := s.customer(r.ids[0])
+	if err != nil {
+		return nil, err
+	}
+	return r.page("/customers/"+customer.Id+"/cards", s.cardsOf(customer.Id))
+}
+
+// retrieveCard answers GET /customers/{id}/cards/{id}.
+func (s *Server) retrieveCard(r *request) (interface{}, *apiError) {
+	_, card, err := s.customerCard(r)
+	if err != nil {
+		return nil, err
+	}
+	return card, nil
+}
+
+// updateCard answers POST /customers/{id}/cards/{id}.
+func (s *Server) updateCard(r *request) (interface{}, *apiError) {
+	_, card, err := s.customerCard(r)
+	if err != nil {
+		return nil, err
+	}
+
+	before := snapshot(card)
+	for name, field := range map[string]*string{
+		"name":            &card.Name,
+		"address_line1":   &card.AddressLine1,
+		"address_line2":   &card.AddressLine2,
+		"address_city":    &card.AddressCity,
+		"address_state":   &card.AddressState,
+		"address_zip":     &card.AddressZip,
+		"address_country": &card.AddressCountry,
+	} {
+		if r.has(name) {
+			*field = r.str(name)
+		}
+	}
+	if r.has("exp_month") {
+		card.ExpMonth = r.int("exp_month")
+	}
+	if r.has("exp_year") {
+		card.ExpYear = r.int("exp_year")
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	s.emitUpdate("customer.card.updated", before, card)
+	return card, nil
+}
+
+// deleteCard answers DELETE /customers/{id}/cards/{id}. If the card was the
+// default card of the customer, the oldest of the others becomes it.
+func (s *Server) deleteCard(r *request) (interface{}, *apiError) {
+	customer, card, err := s.customerCard(r)
+	if err != nil {
+		return nil, err
+	}
+
+	before := snapshot(s.render(customer))
+	s.remove("card", card.Id)
+	if customer.DefaultCard == card.Id {
+		customer.DefaultCard = ""
+		if cards := s.cardsOf(customer.Id); len(cards) > 0 {
+			customer.DefaultCard = cards[0].(*stripe.Card).Id
+		}
+	}
+
+	s.emit("customer.card.deleted", card, nil)
+	s.emitUpdate("customer.updated", before, s.render(customer))
+	return deleted(card.Id), nil
+}
+
+// expired reports whether the card has expired at t.
+func expired(card *stripe.Card, t time.Time) bool {
+	t = t.UTC()
+	return card.ExpYear < int64(t.Year()) || (card.ExpYear == int64(t.Year()) && card.ExpMonth < int64(t.Month()))
+}
@@ -0,0 +1,85 @@
+package stripetest
+
+import (
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"github.com/bmizerany/assert"
+	"testing"
+)
+
+func TestCardsDeclined(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	for number, code := range map[string]string{
+		CardDeclined:        "card_declined",
+		CardIncorrectCVC:    "incorrect_cvc",
+		CardExpired:         "expired_card",
+		CardProcessingError: "processing_error",
+		CardChargeFails:     "card_declined",
+		CardIncorrectNumber: "incorrect_number",
+	} {
+		_, err := client.Charges.Create(&stripe.ChargeParams{Amount: stripe.Int(100), Currency: stripe.String("usd"), CardParams: cardParams(number)})
+		assert.Equal(t, apiErr(err).Err.Type, "card_error", number)
+		assert.Equal(t, apiErr(err).Err.Code, code, number)
+	}
+}
+
+func TestCardsChargeFails(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	customer, err := client.Customers.Create(&stripe.CustomerParams{CardParams: cardParams(CardChargeFails)})
+	assert.Equal(t, err, nil)
+
+	_, err = client.Charges.Create(&stripe.ChargeParams{Amount: stripe.Int(100), Currency: stripe.String("usd"), Customer: stripe.String(customer.Id)})
+	assert.Equal(t, apiErr(err).Err.Code, "card_declined")
+	assert.Equal(t, apiErr(err).Err.DeclineCode, "generic_decline")
+
+	charges, _ := client.Charges.All()
+	assert.Equal(t, charges.Count, 1)
+	assert.Equal(t, charges.Data[0].Paid, false)
+	assert.Equal(t, charges.Data[0].FailureCode, "card_declined")
+}
+
+func TestCardsChecks(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	params := cardParams(CardCVCCheckFails)
+	params.AddressLine1 = stripe.String("1 Main St")
+	token, err := client.Tokens.Create(&stripe.TokenParams{CardParams: params})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, token.Card.CVCCheck, "fail")
+	assert.Equal(t, token.Card.AddressLine1Check, "pass")
+}
+
+func TestCardsToken(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	token, _ := client.Tokens.Create(&stripe.TokenParams{CardParams: cardParams(CardVisa)})
+	customer, err := client.Customers.Create(&stripe.CustomerParams{CardParams: &stripe.CardParams{Token: stripe.String(token.Id)}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, customer.Cards.Data[0].Last4, "4242")
+
+	_, err = client.Customers.Create(&stripe.CustomerParams{CardParams: &stripe.CardParams{Token: stripe.String(token.Id)}})
+	assert.Equal(t, apiErr(err).Err.Message, "You cannot use a Stripe token more than once: "+token.Id+".")
+}
+
+func TestCardsCustomer(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	customer, _ := client.Customers.Create(&stripe.CustomerParams{CardParams: cardParams(CardVisa)})
+	card, err := client.Cards.Create(customer.Id, &stripe.CardParams{Number: stripe.String(CardAmex), ExpMonth: stripe.Int(1), ExpYear: stripe.Int(2099)})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, card.Type, "American Express")
+
+	cards, _ := client.Cards.All(customer.Id)
+	assert.Equal(t, cards.Count, 2)
+
+	_, err = client.Cards.Delete(customer.Id, customer.DefaultCard)
+	assert.Equal(t, err, nil)
+	customer, _ = client.Customers.Retrieve(customer.Id)
+	assert.Equal(t, customer.DefaultCard, card.Id)
+}
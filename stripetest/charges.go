@@ -0,0 +1,222 @@
+package stripetest
+
+import (
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"strings"
+)
+
+// charge charges charge, with its amount, currency, customer, invoice and
+// description already set, to card. The rest of charge is filled in before its
+// Event is recorded. A charge that is declined is kept, as unpaid, and returned
+// along with the card_error. Only captured charges move the balance.
+func (s *Server) charge(charge *stripe.Charge, card *stripe.Card, capture bool) (*stripe.Charge, *apiError) {
+	if card == nil {
+		return nil, cardError("missing", "card")
+	}
+
+	copied := *card
+	charge.Id = s.newId("ch")
+	charge.Object = "charge"
+	charge.Card = &copied
+	charge.Created = s.now.Unix()
+	charge.Refunds = []stripe.Refund{}
+
+	code := testCards[s.cardNumbers[card.Id]].declineCode
+	if expired(card, s.now) {
+		code = "expired_card"
+	}
+	if code != "" {
+		charge.FailureCode, charge.FailureMessage = code, cardMessages[code]
+		s.put("charge", charge.Id, charge)
+		s.emit("charge.failed", charge, nil)
+		return charge, cardError(code, "")
+	}
+
+	charge.Paid = true
+	if capture {
+		s.capture(charge, charge.Amount)
+	}
+	s.put("charge", charge.Id, charge)
+	s.emit("charge.succeeded", charge, nil)
+	return charge, nil
+}
+
+// capture captures the amount of the charge, and adds it to the balance,
+// less Stripe's fees of 2.9% + 30 cents. The funds are pending for two days.
+// What isn't captured is refunded.
+func (s *Server) capture(charge *stripe.Charge, amount int64) {
+	fee := (amount*29+500)/1000 + 30
+	available := testCards[s.cardNumbers[charge.Card.Id]].available
+	txn := s.newBalanceTransaction("charge", charge.Id, amount, fee, charge.Currency, available)
+	txn.FeeDetails = []stripe.FeeDetails{{Amount: fee, Currency: charge.Currency, Type: "stripe_fee", Description: "Stripe processing fees"}}
+
+	charge.Captured = true
+	charge.BalanceTransaction = stripe.BalanceTransactionField{Id: txn.Id}
+	charge.AmountRefunded = charge.Amount - amount
+}
+
+// chargeOr404 returns the Charge with the given id.
+func (s *Server) chargeOr404(id string) (*stripe.Charge, *apiError) {
+	charge, _ := s.get("charge", id).(*stripe.Charge)
+	if charge == nil {
+		return nil, notFound("id", "No such charge: %s", id)
+	}
+	return charge, nil
+}
+
+// createCharge answers POST /charges. It charges either a card, or a card of
+// a customer, its default card unless given.
+func (s *Server) createCharge(r *request) (interface{}, *apiError) {
+	r.require("amount", "currency")
+	amount, currency := r.int("amount"), strings.ToLower(r.str("currency"))
+	capture := !r.has("capture") || r.bool("capture")
+	if r.err != nil {
+		return nil, r.err
+	}
+	if amount < 50 {
+		return nil, invalidRequest("amount", "Amount must be at least 50 cents")
+	}
+
+	var card *stripe.Card
+	customerId := r.str("customer")
+	if customerId != "" {
+		customer, _ := s.get("customer", customerId).(*stripe.Customer)
+		if customer == nil {
+			return nil, invalidRequest("customer", "No such customer: %s", customerId)
+		}
+		cardId := customer.DefaultCard
+		if r.has("card") {
+			cardId = r.str("card")
+		}
+		card, _ = s.get("card", cardId).(*stripe.Card)
+		if cardId != "" && (card == nil || card.Customer != customer.Id) {
+			return nil, invalidRequest("card", "Customer %s does not have card with ID %s", customer.Id, cardId)
+		}
+	} else {
+		var err *apiError
+		if card, err = s.cardParam(r); err != nil {
+			return nil, err
+		}
+		if card == nil {
+			return nil, invalidRequest("card", "You must supply either a card or a customer id.")
+		}
+	}
+
+	charge, err := s.charge(&stripe.Charge{
+		Amount:      amount,
+		Currency:    currency,
+		Customer:    stripe.CustomerField{Id: customerId},
+		Description: r.str("description"),
+		Metadata:    r.metadata(nil),
+	}, card, capture)
+	if err != nil {
+		return nil, err
+	}
+	return charge, nil
+}
+
+// retrieveCharge answers GET /charges/{id}.
+func (s *Server) retrieveCharge(r *request) (interface{}, *apiError) {
+	return s.chargeOr404(r.ids[0])
+}
+
+// updateCharge answers POST /charges/{id}.
+func (s *Server) updateCharge(r *request) (interface{}, *apiError) {
+	charge, err := s.chargeOr404(r.ids[0])
+	if err != nil {
+		return nil, err
+	}
+
+	before := snapshot(charge)
+	if r.has("description") {
+		charge.Description = r.str("description")
+	}
+	charge.Metadata = r.metadata(charge.Metadata)
+	s.emitUpdate("charge.updated", before, charge)
+	return charge, nil
+}
+
+// captureCharge answers POST /charges/{id}/capture.
+func (s *Server) captureCharge(r *request) (interface{}, *apiError) {
+	charge, err := s.chargeOr404(r.ids[0])
+	if err != nil {
+		return nil, err
+	}
+
+	amount := charge.Amount
+	if r.has("amount") {
+		amount = r.int("amount")
+	}
+	switch {
+	case r.err != nil:
+		return nil, r.err
+	case !charge.Paid:
+		return nil, invalidRequest("", "Charge %s has failed and can't be captured.", charge.Id)
+	case charge.Refunded:
+		return nil, invalidRequest("", "Charge %s has already been refunded.", charge.Id)
+	case charge.Captured:
+		return nil, invalidRequest("", "Charge %s has already been captured.", charge.Id)
+	case amount < 50 || amount > charge.Amount:
+		return nil, invalidRequest("amount", "Amount must be between 50 cents and the amount of the charge")
+	}
+
+	before := snapshot(charge)
+	s.capture(charge, amount)
+	s.emit("charge.captured", charge, changes(before, charge))
+	return charge, nil
+}
+
+// refundCharge answers POST /charges/{id}/refund. Refunds are taken from the
+// balance at once. Refunding a charge that isn't captured releases it.
+func (s *Server) refundCharge(r *request) (interface{}, *apiError) {
+	charge, err := s.chargeOr404(r.ids[0])
+	if err != nil {
+		return nil, err
+	}
+
+	left := charge.Amount - charge.AmountRefunded
+	amount := left
+	if r.has("amount") {
+		amount = r.int("amount")
+	}
+	switch {
+	case r.err != nil:
+		return nil, r.err
+	case !charge.Paid:
+		return nil, invalidRequest("", "Charge %s has failed and can't be refunded.", charge.Id)
+	case charge.Refunded:
+		return nil, invalidRequest("", "Charge %s has already been refunded.", charge.Id)
+	case amount <= 0 || amount > left:
+		return nil, invalidRequest("amount", "Refund amount ($%.2f) is greater than unrefunded amount on charge ($%.2f)", float64(amount)/100, float64(left)/100)
+	}
+
+	before := snapshot(charge)
+	if !charge.Captured {
+		charge.AmountRefunded, charge.Refunded = charge.Amount, true
+		s.emit("charge.refunded", charge, changes(before, charge))
+		return charge, nil
+	}
+
+	txn := s.newBalanceTransaction("refund", charge.Id, -amount, 0, charge.Currency, true)
+	charge.Refunds = append(charge.Refunds, stripe.Refund{
+		Object:             "refund",
+		Amount:             amount,
+		Created:            s.now.Unix(),
+		Currency:           charge.Currency,
+		BalanceTransaction: txn.Id,
+	})
+	charge.AmountRefunded += amount
+	charge.Refunded = charge.AmountRefunded == charge.Amount
+	s.emit("charge.refunded", charge, changes(before, charge))
+	return charge, nil
+}
+
+// listCharges answers GET /charges.
+func (s *Server) listCharges(r *request) (interface{}, *apiError) {
+	customer := r.str("customer")
+	charges := s.filter("charge", func(o interface{}) bool {
+		charge := o.(*stripe.Charge)
+		return (customer == "" || charge.Customer.Id == customer) && r.inRange("created", charge.Created)
+	})
+	return r.page("/charges", charges)
+}
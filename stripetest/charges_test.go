@@ -0,0 +1,130 @@
+package stripetest
+
+import (
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"github.com/bmizerany/assert"
+	"testing"
+)
+
+func TestChargesCreate(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	charge, err := client.Charges.Create(&stripe.ChargeParams{
+		Amount:      stripe.Int(1000),
+		Currency:    stripe.String("usd"),
+		Description: stripe.String("Order 1"),
+		CardParams:  cardParams(CardVisa),
+	})
+	assert.Equal(t, err, nil)
+	assert.T(t, charge.Paid)
+	assert.T(t, charge.Captured)
+	assert.Equal(t, charge.Description, "Order 1")
+
+	txn, err := client.Balance.RetrieveTransaction(charge.BalanceTransaction.Id)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, txn.Amount, int64(1000))
+	assert.Equal(t, txn.Fee, int64(59))
+	assert.Equal(t, txn.Net, int64(941))
+	assert.Equal(t, txn.Source.Id, charge.Id)
+}
+
+func TestChargesCreateEvent(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	for _, number := range []string{CardVisa, CardDeclined} {
+		client.Charges.Create(&stripe.ChargeParams{
+			Amount:      stripe.Int(1000),
+			Currency:    stripe.String("usd"),
+			Description: stripe.String("Order 42"),
+			CardParams:  cardParams(number),
+			Metadata:    stripe.Metadata{"order_id": "42"},
+		})
+	}
+
+	events, _ := client.Events.All()
+	assert.Equal(t, events.Count, 2)
+	for _, event := range events.Data {
+		charge, err := event.Charge()
+		assert.Equal(t, err, nil)
+		assert.Equal(t, charge.Description, "Order 42", event.Type)
+		assert.Equal(t, charge.Metadata["order_id"], "42", event.Type)
+	}
+}
+
+func TestChargesCreateInvalid(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	_, err := client.Charges.Create(&stripe.ChargeParams{Amount: stripe.Int(10), Currency: stripe.String("usd"), CardParams: cardParams(CardVisa)})
+	assert.Equal(t, apiErr(err).Err.Param, "amount")
+
+	_, err = client.Charges.Create(&stripe.ChargeParams{Amount: stripe.Int(100), Currency: stripe.String("usd")})
+	assert.Equal(t, apiErr(err).Err.Message, "You must supply either a card or a customer id.")
+
+	customer, _ := client.Customers.Create(&stripe.CustomerParams{})
+	_, err = client.Charges.Create(&stripe.ChargeParams{Amount: stripe.Int(100), Currency: stripe.String("usd"), Customer: stripe.String(customer.Id)})
+	assert.Equal(t, apiErr(err).Err.Code, "missing")
+}
+
+func TestChargesCapture(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	charge, _ := client.Charges.Create(&stripe.ChargeParams{
+		Amount:         stripe.Int(1000),
+		Currency:       stripe.String("usd"),
+		DisableCapture: stripe.Bool(true),
+		CardParams:     cardParams(CardVisa),
+	})
+	assert.Equal(t, charge.Captured, false)
+	assert.Equal(t, charge.BalanceTransaction.Id, "")
+
+	charge, err := client.Charges.Capture(charge.Id, &stripe.ChargeParams{Amount: stripe.Int(800)})
+	assert.Equal(t, err, nil)
+	assert.T(t, charge.Captured)
+	assert.Equal(t, charge.AmountRefunded, int64(200))
+
+	_, err = client.Charges.Capture(charge.Id, nil)
+	assert.Equal(t, apiErr(err).Err.Message, "Charge "+charge.Id+" has already been captured.")
+}
+
+func TestChargesRefund(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	charge, _ := client.Charges.Create(&stripe.ChargeParams{Amount: stripe.Int(1000), Currency: stripe.String("usd"), CardParams: cardParams(CardVisa)})
+
+	charge, err := client.Charges.Refund(charge.Id, &stripe.RefundParams{Amount: stripe.Int(400)})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, charge.AmountRefunded, int64(400))
+	assert.Equal(t, charge.Refunded, false)
+	assert.Equal(t, len(charge.Refunds), 1)
+
+	_, err = client.Charges.Refund(charge.Id, &stripe.RefundParams{Amount: stripe.Int(700)})
+	assert.Equal(t, apiErr(err).Err.Param, "amount")
+
+	charge, err = client.Charges.Refund(charge.Id, nil)
+	assert.Equal(t, err, nil)
+	assert.T(t, charge.Refunded)
+	assert.Equal(t, charge.Refunds[1].Amount, int64(600))
+
+	balance, _ := client.Balance.Retrieve()
+	assert.Equal(t, balance.Available[0].Amount, int64(-1000))
+	assert.Equal(t, balance.Pending[0].Amount, int64(941))
+}
+
+func TestChargesList(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	customer, _ := client.Customers.Create(&stripe.CustomerParams{CardParams: cardParams(CardVisa)})
+	client.Charges.Create(&stripe.ChargeParams{Amount: stripe.Int(100), Currency: stripe.String("usd"), Customer: stripe.String(customer.Id)})
+	client.Charges.Create(&stripe.ChargeParams{Amount: stripe.Int(100), Currency: stripe.String("usd"), CardParams: cardParams(CardVisa)})
+
+	charges, err := client.Charges.AllWithParams(&stripe.ChargeListParams{Customer: customer.Id})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, charges.Count, 1)
+	assert.Equal(t, charges.Data[0].Customer.Id, customer.Id)
+}
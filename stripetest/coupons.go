@@ -0,0 +1,90 @@
+package stripetest
+
+import (
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"strings"
+)
+
+// coupon returns the Coupon with the given id.
+func (s *Server) coupon(id string) (*stripe.Coupon, *apiError) {
+	coupon, _ := s.get("coupon", id).(*stripe.Coupon)
+	if coupon == nil {
+		return nil, notFound("id", "No such coupon: %s", id)
+	}
+	coupon.Valid = s.valid(coupon)
+	return coupon, nil
+}
+
+// valid reports whether the coupon can still be redeemed.
+func (s *Server) valid(coupon *stripe.Coupon) bool {
+	return (coupon.RedeemBy == 0 || s.now.Unix() < coupon.RedeemBy) &&
+		(coupon.MaxRedemptions == 0 || coupon.TimesRedeemed < coupon.MaxRedemptions)
+}
+
+// createCoupon answers POST /coupons.
+func (s *Server) createCoupon(r *request) (interface{}, *apiError) {
+	r.require("duration")
+	coupon := &stripe.Coupon{
+		Id:               r.str("id"),
+		Object:           "coupon",
+		Duration:         r.str("duration"),
+		AmountOff:        r.int("amount_off"),
+		Currency:         strings.ToLower(r.str("currency")),
+		DurationInMonths: r.int("duration_in_months"),
+		MaxRedemptions:   r.int("max_redemptions"),
+		PercentOff:       r.int("percent_off"),
+		RedeemBy:         r.int("redeem_by"),
+		Valid:            true,
+	}
+	if coupon.Id == "" {
+		coupon.Id = s.newId("coupon")
+	}
+
+	switch {
+	case r.err != nil:
+		return nil, r.err
+	case s.get("coupon", coupon.Id) != nil:
+		return nil, invalidRequest("id", "Coupon already exists.")
+	case coupon.Duration != "once" && coupon.Duration != "repeating" && coupon.Duration != "forever":
+		return nil, invalidRequest("duration", "Invalid duration: must be one of once, repeating or forever")
+	case coupon.Duration == "repeating" && coupon.DurationInMonths < 1:
+		return nil, invalidRequest("duration_in_months", "Missing required param: duration_in_months.")
+	case (coupon.PercentOff == 0) == (coupon.AmountOff == 0):
+		return nil, invalidRequest("percent_off", "Coupons must have either a percent_off or an amount_off.")
+	case coupon.PercentOff < 0 || coupon.PercentOff > 100:
+		return nil, invalidRequest("percent_off", "Invalid percent_off: must be between 1 and 100")
+	case coupon.AmountOff != 0 && coupon.Currency == "":
+		return nil, invalidRequest("currency", "Missing required param: currency.")
+	}
+
+	s.put("coupon", coupon.Id, coupon)
+	s.emit("coupon.created", coupon, nil)
+	return coupon, nil
+}
+
+// retrieveCoupon answers GET /coupons/{id}.
+func (s *Server) retrieveCoupon(r *request) (interface{}, *apiError) {
+	return s.coupon(r.ids[0])
+}
+
+// deleteCoupon answers DELETE /coupons/{id}. The discounts given with the
+// coupon are kept.
+func (s *Server) deleteCoupon(r *request) (interface{}, *apiError) {
+	coupon, err := s.coupon(r.ids[0])
+	if err != nil {
+		return nil, err
+	}
+
+	s.remove("coupon", coupon.Id)
+	s.emit("coupon.deleted", coupon, nil)
+	return deleted(coupon.Id), nil
+}
+
+// listCoupons answers GET /coupons.
+func (s *Server) listCoupons(r *request) (interface{}, *apiError) {
+	coupons := s.all("coupon")
+	for _, coupon := range coupons {
+		coupon.(*stripe.Coupon).Valid = s.valid(coupon.(*stripe.Coupon))
+	}
+	return r.page("/coupons", coupons)
+}
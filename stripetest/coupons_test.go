@@ -0,0 +1,38 @@
+package stripetest
+
+import (
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"github.com/bmizerany/assert"
+	"testing"
+)
+
+func TestCouponsMaxRedemptions(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	coupon, err := client.Coupons.Create(&stripe.CouponParams{Duration: stripe.String("repeating"), DurationInMonths: stripe.Int(3), PercentOff: stripe.Int(25), MaxRedemptions: stripe.Int(1)})
+	assert.Equal(t, err, nil)
+	assert.T(t, coupon.Valid)
+
+	customer, err := client.Customers.Create(&stripe.CustomerParams{Coupon: stripe.String(coupon.Id)})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, customer.Discount.End, server.Now().AddDate(0, 3, 0).Unix())
+
+	coupon, _ = client.Coupons.Retrieve(coupon.Id)
+	assert.Equal(t, coupon.TimesRedeemed, int64(1))
+	assert.Equal(t, coupon.Valid, false)
+
+	_, err = client.Customers.Create(&stripe.CustomerParams{Coupon: stripe.String(coupon.Id)})
+	assert.Equal(t, apiErr(err).Err.Param, "coupon")
+}
+
+func TestCouponsInvalid(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	_, err := client.Coupons.Create(&stripe.CouponParams{Duration: stripe.String("once")})
+	assert.Equal(t, apiErr(err).Err.Param, "percent_off")
+
+	_, err = client.Coupons.Create(&stripe.CouponParams{Duration: stripe.String("once"), AmountOff: stripe.Int(100)})
+	assert.Equal(t, apiErr(err).Err.Param, "currency")
+}
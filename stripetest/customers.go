@@ -0,0 +1,215 @@
+package stripetest
+
+import (
+	"github.com/andrewpthorp/stripe-go/stripe"
+)
+
+// customer returns the Customer with the given id.
+func (s *Server) customer(id string) (*stripe.Customer, *apiError) {
+	customer, _ := s.get("customer", id).(*stripe.Customer)
+	if customer == nil {
+		return nil, notFound("id", "No such customer: %s", id)
+	}
+	return customer, nil
+}
+
+// render returns a copy of the Customer, with its cards and subscriptions.
+func (s *Server) render(customer *stripe.Customer) *stripe.Customer {
+	c := *customer
+
+	cards := s.cardsOf(customer.Id)
+	c.Cards = &stripe.CardListResponse{ListResponse: stripe.ListResponse{Object: "list", Url: "/v1/customers/" + customer.Id + "/cards", Count: len(cards)}}
+	for _, card := range cards {
+		c.Cards.Data = append(c.Cards.Data, *card.(*stripe.Card))
+	}
+
+	c.Subscriptions = []stripe.Subscription{}
+	for _, sub := range s.subscriptionsOf(customer.Id) {
+		c.Subscriptions = append(c.Subscriptions, *sub.(*stripe.Subscription))
+	}
+	return &c
+}
+
+// applyCoupon gives the customer a Discount with the coupon of the given id.
+func (s *Server) applyCoupon(customer *stripe.Customer, id string) *apiError {
+	coupon, _ := s.get("coupon", id).(*stripe.Coupon)
+	if coupon == nil {
+		return invalidRequest("coupon", "No such coupon: %s", id)
+	}
+	if !s.valid(coupon) {
+		return invalidRequest("coupon", "Coupon expired: %s", id)
+	}
+
+	coupon.TimesRedeemed++
+	copied := *coupon
+	discount := &stripe.Discount{Object: "discount", Coupon: &copied, Customer: customer.Id, Start: s.now.Unix()}
+	if coupon.Duration == "repeating" {
+		discount.End = s.now.AddDate(0, int(coupon.DurationInMonths), 0).Unix()
+	}
+	customer.Discount = discount
+	return nil
+}
+
+// createCustomer answers POST /customers. With a plan, the customer is only
+// created if the subscription to it is.
+func (s *Server) createCustomer(r *request) (interface{}, *apiError) {
+	customer := &stripe.Customer{
+		Id:             s.newId("cus"),
+		Object:         "customer",
+		Created:        s.now.Unix(),
+		AccountBalance: r.int("account_balance"),
+		Email:          r.str("email"),
+		Metadata:       r.metadata(nil),
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	card, err := s.cardParam(r)
+	if err != nil {
+		return nil, err
+	}
+	if card != nil {
+		if err := s.attach(customer, card); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.has("coupon") {
+		if err := s.applyCoupon(customer, r.str("coupon")); err != nil {
+			return nil, err
+		}
+	}
+
+	// The customer is created before it subscribes, which may fail.
+	s.put("customer", customer.Id, customer)
+	events := []*stripe.Event{s.emit("customer.created", s.render(customer), nil)}
+	if customer.Discount != nil {
+		events = append(events, s.emit("customer.discount.created", customer.Discount, nil))
+	}
+
+	if r.has("plan") {
+		if _, err := s.subscribe(customer, r); err != nil {
+			s.remove("customer", customer.Id)
+			if card != nil {
+				s.remove("card", card.Id)
+			}
+			for _, event := range events {
+				s.remove("event", event.Id)
+			}
+			return nil, err
+		}
+	}
+
+	return s.render(customer), nil
+}
+
+// retrieveCustomer answers GET /customers/{id}.
+func (s *Server) retrieveCustomer(r *request) (interface{}, *apiError) {
+	customer, err := s.customer(r.ids[0])
+	if err != nil {
+		return nil, err
+	}
+	return s.render(customer), nil
+}
+
+// updateCustomer answers POST /customers/{id}. A card replaces the default
+// card of the customer.
+func (s *Server) updateCustomer(r *request) (interface{}, *apiError) {
+	customer, err := s.customer(r.ids[0])
+	if err != nil {
+		return nil, err
+	}
+	before := snapshot(s.render(customer))
+
+	if r.has("account_balance") {
+		customer.AccountBalance = r.int("account_balance")
+	}
+	if r.has("email") {
+		customer.Email = r.str("email")
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	card, err := s.cardParam(r)
+	if err != nil {
+		return nil, err
+	}
+	if card != nil {
+		old := customer.DefaultCard
+		customer.DefaultCard = ""
+		if err := s.attach(customer, card); err != nil {
+			customer.DefaultCard = old
+			return nil, err
+		}
+		if old != "" {
+			s.remove("card", old)
+		}
+	}
+
+	if r.has("coupon") {
+		if err := s.applyCoupon(customer, r.str("coupon")); err != nil {
+			return nil, err
+		}
+		s.emit("customer.discount.created", customer.Discount, nil)
+	}
+	customer.Metadata = r.metadata(customer.Metadata)
+
+	rendered := s.render(customer)
+	s.emitUpdate("customer.updated", before, rendered)
+	return rendered, nil
+}
+
+// deleteCustomer answers DELETE /customers/{id}. Its subscriptions are
+// canceled, and its cards deleted.
+func (s *Server) deleteCustomer(r *request) (interface{}, *apiError) {
+	customer, err := s.customer(r.ids[0])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, sub := range s.subscriptionsOf(customer.Id) {
+		s.cancel(sub.(*stripe.Subscription))
+	}
+	rendered := s.render(customer)
+	for _, card := range s.cardsOf(customer.Id) {
+		s.remove("card", card.(*stripe.Card).Id)
+	}
+	s.remove("customer", customer.Id)
+
+	s.emit("customer.deleted", rendered, nil)
+	return deleted(customer.Id), nil
+}
+
+// listCustomers answers GET /customers.
+func (s *Server) listCustomers(r *request) (interface{}, *apiError) {
+	customers := s.filter("customer", func(o interface{}) bool {
+		return r.inRange("created", o.(*stripe.Customer).Created)
+	})
+
+	list, err := r.page("/customers", customers)
+	if err != nil {
+		return nil, err
+	}
+	for i, customer := range list.Data {
+		list.Data[i] = s.render(customer.(*stripe.Customer))
+	}
+	return list, nil
+}
+
+// deleteDiscount answers DELETE /customers/{id}/discount.
+func (s *Server) deleteDiscount(r *request) (interface{}, *apiError) {
+	customer, err := s.customer(r.ids[0])
+	if err != nil {
+		return nil, err
+	}
+	if customer.Discount == nil {
+		return nil, notFound("", "No active discount for customer: %s", customer.Id)
+	}
+
+	discount := customer.Discount
+	customer.Discount = nil
+	s.emit("customer.discount.deleted", discount, nil)
+	return deleted(customer.Id), nil
+}
@@ -0,0 +1,98 @@
+package stripetest
+
+import (
+	"errors"
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"github.com/bmizerany/assert"
+	"testing"
+)
+
+func TestCustomersCreate(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	created, err := client.Customers.Create(&stripe.CustomerParams{
+		Email:      stripe.String("jane@example.com"),
+		CardParams: cardParams(CardVisa),
+		Metadata:   stripe.Metadata{"plan": "gold"},
+	})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, created.Email, "jane@example.com")
+	assert.Equal(t, created.Metadata["plan"], "gold")
+	assert.Equal(t, created.Cards.Count, 1)
+	assert.Equal(t, created.DefaultCard, created.Cards.Data[0].Id)
+	assert.Equal(t, created.Cards.Data[0].Last4, "4242")
+
+	customer, err := client.Customers.Retrieve(created.Id)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, customer.Email, "jane@example.com")
+
+	customers, _ := client.Customers.All()
+	assert.Equal(t, customers.Count, 1)
+	assert.Equal(t, customers.Data[0].Id, created.Id)
+}
+
+func TestCustomersCreateDeclined(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	_, err := client.Customers.Create(&stripe.CustomerParams{CardParams: cardParams(CardDeclined)})
+	var cardErr *stripe.CardError
+	assert.T(t, errors.As(err, &cardErr))
+	assert.Equal(t, apiErr(err).Err.Code, "card_declined")
+
+	customers, _ := client.Customers.All()
+	assert.Equal(t, customers.Count, 0)
+}
+
+func TestCustomersUpdate(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	created, _ := client.Customers.Create(&stripe.CustomerParams{CardParams: cardParams(CardVisa)})
+	customer, err := client.Customers.Update(created.Id, &stripe.CustomerParams{
+		Email:      stripe.String("new@example.com"),
+		CardParams: cardParams(CardMasterCard),
+	})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, customer.Email, "new@example.com")
+	assert.Equal(t, customer.Cards.Count, 1)
+	assert.Equal(t, customer.Cards.Data[0].Type, "MasterCard")
+	assert.Equal(t, customer.DefaultCard, customer.Cards.Data[0].Id)
+
+	events, _ := client.Events.AllWithParams(&stripe.EventListParams{Type: "customer.updated"})
+	assert.Equal(t, events.Count, 1)
+	assert.Equal(t, events.Data[0].Data.PreviousAttributes["email"], "")
+}
+
+func TestCustomersDelete(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	client.Plans.Create(&stripe.PlanParams{Id: stripe.String("gold"), Amount: stripe.Int(2000), Currency: stripe.String("usd"), Interval: stripe.String("month"), Name: stripe.String("Gold")})
+	created, _ := client.Customers.Create(&stripe.CustomerParams{Plan: stripe.String("gold"), CardParams: cardParams(CardVisa)})
+
+	res, err := client.Customers.Delete(created.Id)
+	assert.Equal(t, err, nil)
+	assert.T(t, res.Deleted)
+
+	_, err = client.Customers.Retrieve(created.Id)
+	assert.T(t, errors.Is(err, stripe.ErrNotFound))
+	events, _ := client.Events.AllWithParams(&stripe.EventListParams{Type: "customer.subscription.deleted"})
+	assert.Equal(t, events.Count, 1)
+}
+
+func TestCustomersDiscount(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	client.Coupons.Create(&stripe.CouponParams{Id: stripe.String("half"), Duration: stripe.String("forever"), PercentOff: stripe.Int(50)})
+	customer, err := client.Customers.Create(&stripe.CustomerParams{Coupon: stripe.String("half")})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, customer.Discount.Coupon.Id, "half")
+
+	_, err = client.Discounts.Delete(customer.Id)
+	assert.Equal(t, err, nil)
+	customer, _ = client.Customers.Retrieve(customer.Id)
+	assert.Equal(t, customer.Discount, (*stripe.Discount)(nil))
+}
@@ -0,0 +1,76 @@
+package stripetest
+
+import (
+	"encoding/json"
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"reflect"
+)
+
+// emit records an Event of the given type about object, as it is now.
+// previous holds the attributes an update changed, as they were before it.
+func (s *Server) emit(eventType string, object interface{}, previous map[string]interface{}) *stripe.Event {
+	data, _ := json.Marshal(object)
+	event := &stripe.Event{
+		Id:      s.newId("evt"),
+		Object:  "event",
+		Data:    &stripe.EventData{Object: data, PreviousAttributes: previous},
+		Created: s.now.Unix(),
+		Type:    eventType,
+	}
+	s.put("event", event.Id, event)
+	return event
+}
+
+// snapshot returns object encoded as JSON attributes, to compare it, with
+// changes, after it is updated.
+func snapshot(object interface{}) map[string]interface{} {
+	var attributes map[string]interface{}
+	data, _ := json.Marshal(object)
+	json.Unmarshal(data, &attributes)
+	return attributes
+}
+
+// changes returns the attributes of before, a snapshot, that differ in after,
+// or nil if none do.
+func changes(before map[string]interface{}, after interface{}) map[string]interface{} {
+	var previous map[string]interface{}
+	for name, value := range snapshot(after) {
+		if old := before[name]; !reflect.DeepEqual(old, value) {
+			if previous == nil {
+				previous = map[string]interface{}{}
+			}
+			previous[name] = old
+		}
+	}
+	return previous
+}
+
+// emitUpdate records an Event of the given type about object, if it changed
+// since the snapshot before.
+func (s *Server) emitUpdate(eventType string, before map[string]interface{}, object interface{}) {
+	if previous := changes(before, object); previous != nil {
+		s.emit(eventType, object, previous)
+	}
+}
+
+// listEvents answers GET /events.
+func (s *Server) listEvents(r *request) (interface{}, *apiError) {
+	t := r.str("type")
+	events := s.filter("event", func(o interface{}) bool {
+		event := o.(*stripe.Event)
+		if t != "" && !stripe.MatchEventType(t, event.Type) {
+			return false
+		}
+		return r.inRange("created", event.Created)
+	})
+	return r.page("/events", events)
+}
+
+// retrieveEvent answers GET /events/{id}.
+func (s *Server) retrieveEvent(r *request) (interface{}, *apiError) {
+	event := s.get("event", r.ids[0])
+	if event == nil {
+		return nil, notFound("id", "No such event: %s", r.ids[0])
+	}
+	return event, nil
+}
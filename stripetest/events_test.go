@@ -0,0 +1,83 @@
+package stripetest
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"github.com/bmizerany/assert"
+	"testing"
+	"time"
+)
+
+// memoryCheckpoint is a stripe.CheckpointStore that keeps the checkpoint in
+// memory.
+type memoryCheckpoint struct{ checkpoint stripe.EventCheckpoint }
+
+func (m *memoryCheckpoint) Load() (stripe.EventCheckpoint, error) { return m.checkpoint, nil }
+
+func (m *memoryCheckpoint) Save(c stripe.EventCheckpoint) error {
+	m.checkpoint = c
+	return nil
+}
+
+func TestEventsList(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	customer, _ := client.Customers.Create(&stripe.CustomerParams{Email: stripe.String("jane@example.com")})
+	client.Charges.Create(&stripe.ChargeParams{Amount: stripe.Int(100), Currency: stripe.String("usd"), Customer: stripe.String(customer.Id)})
+	client.Customers.Update(customer.Id, &stripe.CustomerParams{CardParams: cardParams(CardVisa)})
+	client.Charges.Create(&stripe.ChargeParams{Amount: stripe.Int(100), Currency: stripe.String("usd"), Customer: stripe.String(customer.Id)})
+
+	events, err := client.Events.All()
+	assert.Equal(t, err, nil)
+	var types []string
+	for _, event := range events.Data {
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, types, []string{"charge.succeeded", "customer.updated", "customer.created"})
+
+	events, _ = client.Events.AllWithParams(&stripe.EventListParams{Type: "customer.*"})
+	assert.Equal(t, events.Count, 2)
+
+	event, err := client.Events.Retrieve(events.Data[1].Id)
+	assert.Equal(t, err, nil)
+	var object stripe.Customer
+	json.Unmarshal(event.Data.Object, &object)
+	assert.Equal(t, object.Email, "jane@example.com")
+}
+
+func TestEventsCreated(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	client.Customers.Create(&stripe.CustomerParams{})
+	server.Advance(time.Minute)
+	since := server.Now()
+	client.Customers.Create(&stripe.CustomerParams{})
+
+	events, _ := client.Events.AllWithParams(&stripe.EventListParams{Created: &stripe.RangeQuery{GTE: since}})
+	assert.Equal(t, events.Count, 1)
+}
+
+func TestEventsTail(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	client.Customers.Create(&stripe.CustomerParams{})
+	checkpoint := &memoryCheckpoint{}
+	var types []string
+	collect := func(ctx context.Context, event *stripe.Event) error {
+		types = append(types, event.Type)
+		return nil
+	}
+
+	err := client.Events.Poll(context.Background(), checkpoint, collect)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, types, []string{"customer.created"})
+
+	createPlan(client, "gold", 2000, 0)
+	err = client.Events.Poll(context.Background(), checkpoint, collect)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, types, []string{"customer.created", "plan.created"})
+}
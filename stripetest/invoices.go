@@ -0,0 +1,464 @@
+package stripetest
+
+import (
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"strings"
+	"time"
+)
+
+// invoice returns the Invoice with the given id.
+func (s *Server) invoice(id string) (*stripe.Invoice, *apiError) {
+	invoice, _ := s.get("invoice", id).(*stripe.Invoice)
+	if invoice == nil {
+		return nil, notFound("id", "No such invoice: %s", id)
+	}
+	return invoice, nil
+}
+
+// pendingItems returns the InvoiceItems of the customer that are not on an
+// invoice yet.
+func (s *Server) pendingItems(customerId string) []interface{} {
+	return s.filter("ii", func(o interface{}) bool {
+		item := o.(*stripe.InvoiceItem)
+		return item.Customer.Id == customerId && item.Invoice.Id == ""
+	})
+}
+
+// discount returns the Discount of the customer, unless it has ended.
+func (s *Server) discount(customer *stripe.Customer) *stripe.Discount {
+	if d := customer.Discount; d != nil && (d.End == 0 || s.now.Unix() < d.End) {
+		return d
+	}
+	return nil
+}
+
+// newInvoice returns an Invoice, not yet stored, for the pending invoice items
+// of the customer and, if sub is not nil, for the period of the subscription
+// from start to end. It also returns the items.
+func (s *Server) newInvoice(customer *stripe.Customer, sub *stripe.Subscription, start, end int64) (*stripe.Invoice, []interface{}) {
+	invoice := &stripe.Invoice{
+		Id:              s.newId("in"),
+		Object:          "invoice",
+		Currency:        "usd",
+		Customer:        stripe.CustomerField{Id: customer.Id},
+		Date:            s.now.Unix(),
+		PeriodStart:     start,
+		PeriodEnd:       end,
+		StartingBalance: customer.AccountBalance,
+		Discount:        s.discount(customer),
+	}
+	invoice.Lines = &stripe.InvoiceLineItemListResponse{ListResponse: stripe.ListResponse{Object: "list", Url: "/v1/invoices/" + invoice.Id + "/lines"}}
+
+	items := s.pendingItems(customer.Id)
+	for _, o := range items {
+		item := o.(*stripe.InvoiceItem)
+		invoice.Currency = item.Currency
+		invoice.Lines.Data = append(invoice.Lines.Data, itemLine(item))
+	}
+
+	if sub != nil {
+		amount := sub.Plan.Amount * sub.Quantity
+		if sub.Status == "trialing" {
+			amount = 0
+		}
+		plan := *sub.Plan
+		invoice.Currency = plan.Currency
+		invoice.Lines.Data = append(invoice.Lines.Data, stripe.InvoiceLineItem{
+			Id:       sub.Id,
+			Object:   "line_item",
+			Amount:   amount,
+			Currency: plan.Currency,
+			Period:   map[string]int64{"start": start, "end": end},
+			Type:     "subscription",
+			Plan:     &plan,
+			Quantity: sub.Quantity,
+		})
+	}
+
+	tally(invoice)
+	return invoice, items
+}
+
+// itemLine returns the line of an invoice for the item.
+func itemLine(item *stripe.InvoiceItem) stripe.InvoiceLineItem {
+	return stripe.InvoiceLineItem{
+		Id:          item.Id,
+		Object:      "line_item",
+		Amount:      item.Amount,
+		Currency:    item.Currency,
+		Period:      map[string]int64{"start": item.Date, "end": item.Date},
+		Proration:   item.Proration,
+		Type:        "invoiceitem",
+		Description: item.Description,
+	}
+}
+
+// tally sets the totals of the invoice from its lines, its discount and the
+// starting balance of the customer. A credit larger than the total is carried
+// over in the ending balance.
+func tally(invoice *stripe.Invoice) {
+	invoice.Lines.Count = len(invoice.Lines.Data)
+	invoice.Subtotal = 0
+	for _, line := range invoice.Lines.Data {
+		invoice.Subtotal += line.Amount
+	}
+
+	invoice.Total = invoice.Subtotal
+	if d := invoice.Discount; d != nil {
+		off := d.Coupon.AmountOff
+		if d.Coupon.PercentOff != 0 {
+			off = invoice.Subtotal * d.Coupon.PercentOff / 100
+		}
+		if off > invoice.Subtotal {
+			off = invoice.Subtotal
+		}
+		invoice.Total -= off
+	}
+
+	invoice.AmountDue, invoice.EndingBalance = invoice.Total+invoice.StartingBalance, 0
+	if invoice.AmountDue < 0 {
+		invoice.AmountDue, invoice.EndingBalance = 0, invoice.AmountDue
+	}
+}
+
+// finalize stores the invoice of the customer, with its items, and takes its
+// starting balance and one-off discount from the customer.
+func (s *Server) finalize(customer *stripe.Customer, invoice *stripe.Invoice, items []interface{}) {
+	for _, item := range items {
+		item.(*stripe.InvoiceItem).Invoice = stripe.InvoiceField{Id: invoice.Id}
+	}
+	customer.AccountBalance = invoice.EndingBalance
+	if invoice.Discount != nil && invoice.Discount.Coupon.Duration == "once" {
+		customer.Discount = nil
+	}
+
+	s.put("invoice", invoice.Id, invoice)
+	s.emit("invoice.created", invoice, nil)
+}
+
+// attempt charges the default card of the customer for the amount due on the
+// invoice. Nothing is charged if nothing is due.
+func (s *Server) attempt(invoice *stripe.Invoice) *apiError {
+	invoice.Attempted = true
+	invoice.AttemptCount++
+	if invoice.AmountDue == 0 {
+		invoice.Paid, invoice.NextPaymentAttempt = true, 0
+		return nil
+	}
+
+	var card *stripe.Card
+	if customer, _ := s.get("customer", invoice.Customer.Id).(*stripe.Customer); customer != nil {
+		card, _ = s.get("card", customer.DefaultCard).(*stripe.Card)
+	}
+	charge, err := s.charge(&stripe.Charge{
+		Amount:   invoice.AmountDue,
+		Currency: invoice.Currency,
+		Customer: stripe.CustomerField{Id: invoice.Customer.Id},
+		Invoice:  stripe.InvoiceField{Id: invoice.Id},
+	}, card, true)
+	if charge != nil {
+		invoice.Charge = stripe.ChargeField{Id: charge.Id}
+	}
+	if err != nil {
+		return err
+	}
+	invoice.Paid, invoice.NextPaymentAttempt = true, 0
+	return nil
+}
+
+// subscriptionOf returns the Subscription the invoice is for, or nil.
+func (s *Server) subscriptionOf(invoice *stripe.Invoice) *stripe.Subscription {
+	for _, line := range invoice.Lines.Data {
+		if line.Type == "subscription" {
+			sub, _ := s.get("sub", line.Id).(*stripe.Subscription)
+			return sub
+		}
+	}
+	return nil
+}
+
+// pay attempts to pay the stored invoice. A subscription whose invoice isn't
+// paid is past due until it is, and canceled after the fourth attempt fails.
+// Attempts are retried every three days.
+func (s *Server) pay(invoice *stripe.Invoice) *apiError {
+	err := s.attempt(invoice)
+	sub := s.subscriptionOf(invoice)
+	var before map[string]interface{}
+	if sub != nil {
+		before = snapshot(sub)
+	}
+
+	switch {
+	case err == nil:
+		s.emit("invoice.payment_succeeded", invoice, nil)
+		if sub != nil && sub.Status == "past_due" {
+			sub.Status = "active"
+		}
+	case invoice.AttemptCount >= 4:
+		invoice.NextPaymentAttempt = 0
+		s.emit("invoice.payment_failed", invoice, nil)
+		if sub != nil && isLive(sub) {
+			s.cancel(sub)
+			return err
+		}
+	default:
+		invoice.NextPaymentAttempt = s.now.Add(3 * 24 * time.Hour).Unix()
+		s.emit("invoice.payment_failed", invoice, nil)
+		if sub != nil && sub.Status == "active" {
+			sub.Status = "past_due"
+		}
+	}
+
+	if sub != nil {
+		s.emitUpdate("customer.subscription.updated", before, sub)
+	}
+	return err
+}
+
+// retryInvoices attempts to pay the invoices whose next payment attempt is
+// due.
+func (s *Server) retryInvoices() {
+	for _, o := range s.all("invoice") {
+		invoice := o.(*stripe.Invoice)
+		if !invoice.Paid && !invoice.Closed && invoice.NextPaymentAttempt != 0 && invoice.NextPaymentAttempt <= s.now.Unix() {
+			s.pay(invoice)
+		}
+	}
+}
+
+// createInvoice answers POST /invoices, with an invoice for the pending items
+// of the customer. Payment is attempted an hour later.
+func (s *Server) createInvoice(r *request) (interface{}, *apiError) {
+	r.require("customer")
+	if r.err != nil {
+		return nil, r.err
+	}
+	customer, _ := s.get("customer", r.str("customer")).(*stripe.Customer)
+	if customer == nil {
+		return nil, invalidRequest("customer", "No such customer: %s", r.str("customer"))
+	}
+
+	invoice, items := s.newInvoice(customer, nil, s.now.Unix(), s.now.Unix())
+	if len(items) == 0 {
+		return nil, invalidRequest("", "Nothing to invoice for customer")
+	}
+	invoice.NextPaymentAttempt = s.now.Add(time.Hour).Unix()
+	s.finalize(customer, invoice, items)
+	return invoice, nil
+}
+
+// retrieveInvoice answers GET /invoices/{id}.
+func (s *Server) retrieveInvoice(r *request) (interface{}, *apiError) {
+	return s.invoice(r.ids[0])
+}
+
+// retrieveUpcomingInvoice answers GET /invoices/upcoming, with the invoice the
+// customer would get at the next renewal of its subscription, or for its
+// pending items if it has none.
+func (s *Server) retrieveUpcomingInvoice(r *request) (interface{}, *apiError) {
+	r.require("customer")
+	if r.err != nil {
+		return nil, r.err
+	}
+	customer, _ := s.get("customer", r.str("customer")).(*stripe.Customer)
+	if customer == nil {
+		return nil, invalidRequest("customer", "No such customer: %s", r.str("customer"))
+	}
+
+	var next *stripe.Subscription
+	for _, o := range s.subscriptionsOf(customer.Id) {
+		sub := o.(*stripe.Subscription)
+		if isLive(sub) && !sub.CancelAtPeriodEnd && (next == nil || sub.CurrentPeriodEnd < next.CurrentPeriodEnd) {
+			next = sub
+		}
+	}
+
+	var invoice *stripe.Invoice
+	var items []interface{}
+	if next != nil {
+		renewed := *next
+		renewed.Status = "active"
+		invoice, items = s.newInvoice(customer, &renewed, next.CurrentPeriodEnd, periodEnd(next.Plan, next.CurrentPeriodEnd))
+		invoice.Date = next.CurrentPeriodEnd
+	} else {
+		invoice, items = s.newInvoice(customer, nil, s.now.Unix(), s.now.Unix())
+	}
+	if next == nil && len(items) == 0 {
+		return nil, notFound("", "No upcoming invoices for customer: %s", customer.Id)
+	}
+
+	invoice.Id, invoice.Lines.Url = "", "/v1/invoices/upcoming/lines?customer="+customer.Id
+	return invoice, nil
+}
+
+// updateInvoice answers POST /invoices/{id}. Closing an invoice stops the
+// attempts to pay it.
+func (s *Server) updateInvoice(r *request) (interface{}, *apiError) {
+	invoice, err := s.invoice(r.ids[0])
+	if err != nil {
+		return nil, err
+	}
+
+	before := snapshot(invoice)
+	if r.has("closed") {
+		invoice.Closed = r.bool("closed")
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	s.emitUpdate("invoice.updated", before, invoice)
+	return invoice, nil
+}
+
+// payInvoice answers POST /invoices/{id}/pay.
+func (s *Server) payInvoice(r *request) (interface{}, *apiError) {
+	invoice, err := s.invoice(r.ids[0])
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case invoice.Paid:
+		return nil, invalidRequest("", "Invoice is already paid")
+	case invoice.Closed:
+		return nil, invalidRequest("", "This invoice is already closed")
+	}
+
+	if err := s.pay(invoice); err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// listInvoices answers GET /invoices.
+func (s *Server) listInvoices(r *request) (interface{}, *apiError) {
+	customer := r.str("customer")
+	invoices := s.filter("invoice", func(o interface{}) bool {
+		invoice := o.(*stripe.Invoice)
+		return (customer == "" || invoice.Customer.Id == customer) && r.inRange("date", invoice.Date)
+	})
+	return r.page("/invoices", invoices)
+}
+
+// listInvoiceLines answers GET /invoices/{id}/lines, in the order of the
+// invoice.
+func (s *Server) listInvoiceLines(r *request) (interface{}, *apiError) {
+	invoice, err := s.invoice(r.ids[0])
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]interface{}, len(invoice.Lines.Data))
+	for i, line := range invoice.Lines.Data {
+		lines[len(lines)-1-i] = line
+	}
+	return r.page("/invoices/"+invoice.Id+"/lines", lines)
+}
+
+// invoiceItem returns the InvoiceItem with the given id.
+func (s *Server) invoiceItem(id string) (*stripe.InvoiceItem, *apiError) {
+	item, _ := s.get("ii", id).(*stripe.InvoiceItem)
+	if item == nil {
+		return nil, notFound("id", "No such invoiceitem: %s", id)
+	}
+	return item, nil
+}
+
+// createInvoiceItem answers POST /invoiceitems. The item is pending, to be
+// added to the next invoice of the customer, unless an open invoice is given.
+func (s *Server) createInvoiceItem(r *request) (interface{}, *apiError) {
+	r.require("customer", "amount", "currency")
+	item := &stripe.InvoiceItem{
+		Id:          s.newId("ii"),
+		Object:      "invoiceitem",
+		Amount:      r.int("amount"),
+		Currency:    strings.ToLower(r.str("currency")),
+		Customer:    stripe.CustomerField{Id: r.str("customer")},
+		Date:        s.now.Unix(),
+		Description: r.str("description"),
+		Metadata:    r.metadata(nil),
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	if s.get("customer", item.Customer.Id) == nil {
+		return nil, invalidRequest("customer", "No such customer: %s", item.Customer.Id)
+	}
+
+	if r.has("invoice") {
+		invoice, _ := s.get("invoice", r.str("invoice")).(*stripe.Invoice)
+		switch {
+		case invoice == nil:
+			return nil, invalidRequest("invoice", "No such invoice: %s", r.str("invoice"))
+		case invoice.Customer.Id != item.Customer.Id:
+			return nil, invalidRequest("invoice", "Invoice %s is not for customer %s", invoice.Id, item.Customer.Id)
+		case invoice.Paid || invoice.Closed:
+			return nil, invalidRequest("invoice", "Invoice %s is no longer open", invoice.Id)
+		}
+		before := snapshot(invoice)
+		item.Invoice = stripe.InvoiceField{Id: invoice.Id}
+		invoice.Lines.Data = append(invoice.Lines.Data, itemLine(item))
+		tally(invoice)
+		s.emitUpdate("invoice.updated", before, invoice)
+	}
+
+	s.put("ii", item.Id, item)
+	s.emit("invoiceitem.created", item, nil)
+	return item, nil
+}
+
+// retrieveInvoiceItem answers GET /invoiceitems/{id}.
+func (s *Server) retrieveInvoiceItem(r *request) (interface{}, *apiError) {
+	return s.invoiceItem(r.ids[0])
+}
+
+// updateInvoiceItem answers POST /invoiceitems/{id}. The amount can only be
+// changed while the item is pending.
+func (s *Server) updateInvoiceItem(r *request) (interface{}, *apiError) {
+	item, err := s.invoiceItem(r.ids[0])
+	if err != nil {
+		return nil, err
+	}
+	if r.has("amount") && item.Invoice.Id != "" {
+		return nil, invalidRequest("amount", "The amount of invoice item %s cannot be changed, because it is on invoice %s.", item.Id, item.Invoice.Id)
+	}
+
+	before := snapshot(item)
+	if r.has("amount") {
+		item.Amount = r.int("amount")
+	}
+	if r.has("description") {
+		item.Description = r.str("description")
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	item.Metadata = r.metadata(item.Metadata)
+	s.emitUpdate("invoiceitem.updated", before, item)
+	return item, nil
+}
+
+// deleteInvoiceItem answers DELETE /invoiceitems/{id}. Only pending items can
+// be deleted.
+func (s *Server) deleteInvoiceItem(r *request) (interface{}, *apiError) {
+	item, err := s.invoiceItem(r.ids[0])
+	if err != nil {
+		return nil, err
+	}
+	if item.Invoice.Id != "" {
+		return nil, invalidRequest("", "Invoice item %s cannot be deleted, because it is on invoice %s.", item.Id, item.Invoice.Id)
+	}
+
+	s.remove("ii", item.Id)
+	s.emit("invoiceitem.deleted", item, nil)
+	return deleted(item.Id), nil
+}
+
+// listInvoiceItems answers GET /invoiceitems.
+func (s *Server) listInvoiceItems(r *request) (interface{}, *apiError) {
+	customer := r.str("customer")
+	items := s.filter("ii", func(o interface{}) bool {
+		item := o.(*stripe.InvoiceItem)
+		return (customer == "" || item.Customer.Id == customer) && r.inRange("created", item.Date)
+	})
+	return r.page("/invoiceitems", items)
+}
@@ -0,0 +1,112 @@
+package stripetest
+
+import (
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"github.com/bmizerany/assert"
+	"testing"
+	"time"
+)
+
+func TestInvoicesItems(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	createPlan(client, "gold", 2000, 0)
+	customer, _ := client.Customers.Create(&stripe.CustomerParams{CardParams: cardParams(CardVisa)})
+	item, err := client.InvoiceItems.Create(&stripe.InvoiceItemParams{Customer: stripe.String(customer.Id), Amount: stripe.Int(500), Currency: stripe.String("usd"), Description: stripe.String("Setup")})
+	assert.Equal(t, err, nil)
+
+	sub, _ := client.Subscriptions.Create(customer.Id, &stripe.SubscriptionParams{Plan: stripe.String("gold")})
+	invoices, _ := client.Invoices.All()
+	invoice := invoices.Data[0]
+	assert.Equal(t, invoice.Total, int64(2500))
+	assert.Equal(t, invoice.Lines.Count, 2)
+
+	lines, err := client.Invoices.RetrieveLines(invoice.Id)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, lines.Data[0].Id, item.Id)
+	assert.Equal(t, lines.Data[1].Id, sub.Id)
+
+	item, _ = client.InvoiceItems.Retrieve(item.Id)
+	assert.Equal(t, item.Invoice.Id, invoice.Id)
+	_, err = client.InvoiceItems.Delete(item.Id)
+	assert.Equal(t, apiErr(err).Err.Type, "invalid_request_error")
+}
+
+func TestInvoicesCreate(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	customer, _ := client.Customers.Create(&stripe.CustomerParams{CardParams: cardParams(CardVisa)})
+	_, err := client.Invoices.Create(&stripe.InvoiceParams{Customer: stripe.String(customer.Id)})
+	assert.Equal(t, apiErr(err).Err.Message, "Nothing to invoice for customer")
+
+	client.InvoiceItems.Create(&stripe.InvoiceItemParams{Customer: stripe.String(customer.Id), Amount: stripe.Int(700), Currency: stripe.String("usd")})
+	invoice, err := client.Invoices.Create(&stripe.InvoiceParams{Customer: stripe.String(customer.Id)})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, invoice.AmountDue, int64(700))
+	assert.Equal(t, invoice.Paid, false)
+
+	server.Advance(time.Hour)
+	invoice, _ = client.Invoices.Retrieve(invoice.Id)
+	assert.T(t, invoice.Paid)
+
+	_, err = client.Invoices.Pay(invoice.Id)
+	assert.Equal(t, apiErr(err).Err.Message, "Invoice is already paid")
+}
+
+func TestInvoicesPay(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	customer, _ := client.Customers.Create(&stripe.CustomerParams{CardParams: cardParams(CardVisa)})
+	client.InvoiceItems.Create(&stripe.InvoiceItemParams{Customer: stripe.String(customer.Id), Amount: stripe.Int(700), Currency: stripe.String("usd")})
+	invoice, _ := client.Invoices.Create(&stripe.InvoiceParams{Customer: stripe.String(customer.Id)})
+
+	invoice, err := client.Invoices.Pay(invoice.Id)
+	assert.Equal(t, err, nil)
+	assert.T(t, invoice.Paid)
+	assert.Equal(t, invoice.AttemptCount, int64(1))
+}
+
+func TestInvoicesDiscount(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	createPlan(client, "gold", 2000, 0)
+	client.Coupons.Create(&stripe.CouponParams{Id: stripe.String("first"), Duration: stripe.String("once"), AmountOff: stripe.Int(500), Currency: stripe.String("usd")})
+	customer, _ := client.Customers.Create(&stripe.CustomerParams{Plan: stripe.String("gold"), Coupon: stripe.String("first"), CardParams: cardParams(CardVisa)})
+
+	invoices, _ := client.Invoices.All()
+	assert.Equal(t, invoices.Data[0].Subtotal, int64(2000))
+	assert.Equal(t, invoices.Data[0].Total, int64(1500))
+
+	customer, _ = client.Customers.Retrieve(customer.Id)
+	assert.Equal(t, customer.Discount, (*stripe.Discount)(nil))
+	upcoming, _ := client.Invoices.RetrieveUpcoming(customer.Id)
+	assert.Equal(t, upcoming.Total, int64(2000))
+}
+
+func TestInvoicesAccountBalance(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	createPlan(client, "gold", 2000, 0)
+	customer, _ := client.Customers.Create(&stripe.CustomerParams{AccountBalance: stripe.Int(-2500), Plan: stripe.String("gold")})
+
+	invoices, _ := client.Invoices.All()
+	assert.Equal(t, invoices.Data[0].AmountDue, int64(0))
+	assert.Equal(t, invoices.Data[0].EndingBalance, int64(-500))
+
+	customer, _ = client.Customers.Retrieve(customer.Id)
+	assert.Equal(t, customer.AccountBalance, int64(-500))
+}
+
+func TestInvoicesUpcomingNone(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	customer, _ := client.Customers.Create(&stripe.CustomerParams{})
+	_, err := client.Invoices.RetrieveUpcoming(customer.Id)
+	assert.Equal(t, apiErr(err).Err.Message, "No upcoming invoices for customer: "+customer.Id)
+}
@@ -0,0 +1,108 @@
+package stripetest
+
+import (
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"strings"
+	"time"
+)
+
+// plan returns the Plan with the given id.
+func (s *Server) plan(id string) (*stripe.Plan, *apiError) {
+	plan, _ := s.get("plan", id).(*stripe.Plan)
+	if plan == nil {
+		return nil, notFound("id", "No such plan: %s", id)
+	}
+	return plan, nil
+}
+
+// periodEnd returns the end of the billing period of the plan that starts at
+// start.
+func periodEnd(plan *stripe.Plan, start int64) int64 {
+	t, n := time.Unix(start, 0), int(plan.IntervalCount)
+	switch plan.Interval {
+	case "day":
+		t = t.AddDate(0, 0, n)
+	case "week":
+		t = t.AddDate(0, 0, 7*n)
+	case "month":
+		t = t.AddDate(0, n, 0)
+	default:
+		t = t.AddDate(n, 0, 0)
+	}
+	return t.Unix()
+}
+
+// createPlan answers POST /plans.
+func (s *Server) createPlan(r *request) (interface{}, *apiError) {
+	r.require("id", "amount", "currency", "interval", "name")
+	plan := &stripe.Plan{
+		Id:              r.str("id"),
+		Object:          "plan",
+		Amount:          r.int("amount"),
+		Currency:        strings.ToLower(r.str("currency")),
+		Interval:        r.str("interval"),
+		IntervalCount:   1,
+		Name:            r.str("name"),
+		TrialPeriodDays: r.int("trial_period_days"),
+		Metadata:        r.metadata(nil),
+	}
+	if r.has("interval_count") {
+		plan.IntervalCount = r.int("interval_count")
+	}
+
+	switch {
+	case r.err != nil:
+		return nil, r.err
+	case s.get("plan", plan.Id) != nil:
+		return nil, invalidRequest("id", "Plan already exists.")
+	case plan.Amount < 0:
+		return nil, invalidRequest("amount", "Invalid integer: %d", plan.Amount)
+	case plan.Interval != "day" && plan.Interval != "week" && plan.Interval != "month" && plan.Interval != "year":
+		return nil, invalidRequest("interval", "Invalid interval: must be one of day, week, month or year")
+	case plan.IntervalCount < 1:
+		return nil, invalidRequest("interval_count", "Invalid interval_count: must be at least 1")
+	}
+
+	s.put("plan", plan.Id, plan)
+	s.emit("plan.created", plan, nil)
+	return plan, nil
+}
+
+// retrievePlan answers GET /plans/{id}.
+func (s *Server) retrievePlan(r *request) (interface{}, *apiError) {
+	return s.plan(r.ids[0])
+}
+
+// updatePlan answers POST /plans/{id}. Only the name and metadata of a plan
+// can be changed.
+func (s *Server) updatePlan(r *request) (interface{}, *apiError) {
+	plan, err := s.plan(r.ids[0])
+	if err != nil {
+		return nil, err
+	}
+
+	before := snapshot(plan)
+	if r.has("name") {
+		plan.Name = r.str("name")
+	}
+	plan.Metadata = r.metadata(plan.Metadata)
+	s.emitUpdate("plan.updated", before, plan)
+	return plan, nil
+}
+
+// deletePlan answers DELETE /plans/{id}. Subscriptions to the plan are kept.
+func (s *Server) deletePlan(r *request) (interface{}, *apiError) {
+	plan, err := s.plan(r.ids[0])
+	if err != nil {
+		return nil, err
+	}
+
+	s.remove("plan", plan.Id)
+	s.emit("plan.deleted", plan, nil)
+	return deleted(plan.Id), nil
+}
+
+// listPlans answers GET /plans.
+func (s *Server) listPlans(r *request) (interface{}, *apiError) {
+	return r.page("/plans", s.all("plan"))
+}
@@ -0,0 +1,34 @@
+package stripetest
+
+import (
+	"errors"
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"github.com/bmizerany/assert"
+	"testing"
+)
+
+func TestPlans(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	createPlan(client, "gold", 2000, 0)
+	plan, err := client.Plans.Retrieve("gold")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, plan.Amount, int64(2000))
+	assert.Equal(t, plan.IntervalCount, int64(1))
+
+	_, err = client.Plans.Create(&stripe.PlanParams{Id: stripe.String("gold"), Amount: stripe.Int(1), Currency: stripe.String("usd"), Interval: stripe.String("month"), Name: stripe.String("Gold")})
+	assert.Equal(t, apiErr(err).Err.Message, "Plan already exists.")
+
+	plan, _ = client.Plans.Update("gold", &stripe.PlanParams{Name: stripe.String("Golden")})
+	assert.Equal(t, plan.Name, "Golden")
+
+	client.Plans.Delete("gold")
+	_, err = client.Plans.Retrieve("gold")
+	assert.T(t, errors.Is(err, stripe.ErrNotFound))
+}
+
+func TestPlansPeriodEnd(t *testing.T) {
+	plan := &stripe.Plan{Interval: "week", IntervalCount: 2}
+	assert.Equal(t, periodEnd(plan, 0), int64(14*24*60*60))
+}
@@ -0,0 +1,653 @@
+// Package stripetest serves a stateful, in-memory fake of the Stripe API, for
+// integration tests of billing code that run without network access:
+//
+//	server := stripetest.NewServer()
+//	defer server.Close()
+//	client := stripe.NewClientWith(nil, server.URL, "sk_test_123")
+//
+//	customer, _ := client.Customers.Create(&stripe.CustomerParams{...})
+//	customer, _ = client.Customers.Retrieve(customer.Id)
+//
+// The fake remembers what is created through it: customers can be retrieved
+// and listed once created, charges move the balance, subscriptions produce
+// invoices and charge for them, and the test card numbers of Stripe decline as
+// they would in test mode (see CardDeclined). Every change is recorded as an
+// Event, which can be listed, or tailed with EventClient.Tail.
+//
+// Time only moves when told to, with Advance, which renews subscriptions,
+// retries failed payments, pays out transfers and makes funds available as
+// Stripe would over that time.
+//
+// Recipients, application fees and disputes are not faked: their endpoints
+// answer with a 404, as unknown endpoints do.
+package stripetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server is a fake of the Stripe API, listening on a local address.
+type Server struct {
+	// URL is the base URL of the API, to give to stripe.NewClientWith.
+	URL string
+
+	server *httptest.Server
+
+	mu          sync.Mutex
+	now         time.Time
+	lastId      int
+	stores      map[string]*store
+	cardNumbers map[string]string
+	idempotent  map[string]*recorded
+}
+
+// store holds the objects of a kind by id, along with their ids in the order
+// they were created.
+type store struct {
+	objects map[string]interface{}
+	ids     []string
+}
+
+// recorded is a response, kept to be replayed for requests with the same
+// Idempotency-Key.
+type recorded struct {
+	status int
+	body   []byte
+}
+
+// NewServer starts and returns a Server. Its clock starts at the current time.
+func NewServer() *Server {
+	s := NewUnstartedServer()
+	s.server = httptest.NewServer(s)
+	s.URL = s.server.URL + "/v1"
+	return s
+}
+
+// NewUnstartedServer returns a Server that doesn't listen, to be used as the
+// http.Handler of a server of the caller's.
+func NewUnstartedServer() *Server {
+	return &Server{
+		now:         time.Now().Truncate(time.Second),
+		stores:      map[string]*store{},
+		cardNumbers: map[string]string{},
+		idempotent:  map[string]*recorded{},
+	}
+}
+
+// Close shuts the Server down.
+func (s *Server) Close() {
+	if s.server != nil {
+		s.server.Close()
+	}
+}
+
+// Now returns the time of the Server's clock.
+func (s *Server) Now() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.now
+}
+
+// Advance moves the clock of the Server forward by d, and does in order what
+// was due during that time: subscriptions are renewed and invoiced, invoices
+// are paid, transfers are paid out and charged funds become available.
+func (s *Server) Advance(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target := s.now.Add(d)
+	for {
+		next, ok := s.nextDue(target)
+		if !ok {
+			break
+		}
+		if next.After(s.now) {
+			s.now = next
+		}
+		s.runDue()
+	}
+	s.now = target
+}
+
+// nextDue returns the earliest time, up to target, something is due.
+func (s *Server) nextDue(target time.Time) (time.Time, bool) {
+	var next time.Time
+	consider := func(unix int64) {
+		if t := time.Unix(unix, 0); unix != 0 && !t.After(target) && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+
+	for _, o := range s.all("sub") {
+		if sub := o.(*stripe.Subscription); isLive(sub) {
+			consider(sub.CurrentPeriodEnd)
+		}
+	}
+	for _, o := range s.all("invoice") {
+		if invoice := o.(*stripe.Invoice); !invoice.Paid && !invoice.Closed {
+			consider(invoice.NextPaymentAttempt)
+		}
+	}
+	for _, o := range s.all("transfer") {
+		if transfer := o.(*stripe.Transfer); transfer.Status == "pending" {
+			consider(transfer.Date)
+		}
+	}
+	for _, o := range s.all("txn") {
+		if txn := o.(*stripe.BalanceTransaction); txn.Status == "pending" {
+			consider(txn.AvailableOn)
+		}
+	}
+
+	return next, !next.IsZero()
+}
+
+// runDue does what is due at the current time.
+func (s *Server) runDue() {
+	s.settleBalance()
+	s.payOutTransfers()
+	s.renewSubscriptions()
+	s.retryInvoices()
+}
+
+// ServeHTTP answers a request to the API.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Request-Id", s.newId("req"))
+	if version := r.Header.Get("Stripe-Version"); version != "" {
+		w.Header().Set("Stripe-Version", version)
+	}
+
+	key := r.Header.Get("Idempotency-Key")
+	if r.Method == "POST" && key != "" {
+		if response, ok := s.idempotent[key]; ok {
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(response.status)
+			w.Write(response.body)
+			return
+		}
+	}
+
+	status, body := s.serve(r)
+	if r.Method == "POST" && key != "" {
+		s.idempotent[key] = &recorded{status, body}
+	}
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// serve authenticates and routes r, and returns the status and body of the
+// response.
+func (s *Server) serve(r *http.Request) (int, []byte) {
+	if err := authenticate(r); err != nil {
+		return err.response()
+	}
+
+	req, err := newRequest(r)
+	if err != nil {
+		return err.response()
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v1")
+	for _, route := range routes {
+		if ids, ok := route.match(r.Method, path); ok {
+			req.ids = ids
+			v, err := route.handle(s, req)
+			if err != nil {
+				return err.response()
+			}
+			body, _ := json.Marshal(v)
+			return http.StatusOK, body
+		}
+	}
+
+	return notFound("", "Unrecognized request URL (%s: /v1%s).", r.Method, path).response()
+}
+
+// authenticate checks that r carries a test mode secret key.
+func authenticate(r *http.Request) *apiError {
+	auth := r.Header.Get("Authorization")
+	if auth == "" {
+		return &apiError{Status: http.StatusUnauthorized, Type: "invalid_request_error", Message: "You did not provide an API key. You need to provide your API key in the Authorization header, using Bearer auth (e.g. 'Authorization: Bearer YOUR_SECRET_KEY')."}
+	}
+	if key := strings.TrimPrefix(auth, "Bearer "); !strings.HasPrefix(key, "sk_test_") {
+		return &apiError{Status: http.StatusUnauthorized, Type: "invalid_request_error", Message: "Invalid API Key provided: " + stripe.Redact(key) + ". stripetest only accepts test mode secret keys."}
+	}
+	return nil
+}
+
+// route is an endpoint of the API. The segments of its pattern that start with
+// a colon match any id.
+type route struct {
+	method  string
+	pattern string
+	handle  func(s *Server, r *request) (interface{}, *apiError)
+}
+
+// match reports whether route matches the request, and returns its ids.
+func (route route) match(method, path string) ([]string, bool) {
+	if method != route.method {
+		return nil, false
+	}
+
+	want := strings.Split(route.pattern, "/")
+	got := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	if len(want) != len(got) {
+		return nil, false
+	}
+
+	var ids []string
+	for i := range want {
+		switch {
+		case strings.HasPrefix(want[i], ":") && got[i] != "":
+			ids = append(ids, got[i])
+		case want[i] != got[i]:
+			return nil, false
+		}
+	}
+	return ids, true
+}
+
+// routes are the endpoints the Server fakes. Fixed paths come before those
+// with ids they would match.
+var routes = []route{
+	{"GET", "/account", (*Server).retrieveAccount},
+
+	{"GET", "/balance", (*Server).retrieveBalance},
+	{"GET", "/balance/history", (*Server).listBalanceTransactions},
+	{"GET", "/balance/history/:id", (*Server).retrieveBalanceTransaction},
+
+	{"POST", "/tokens", (*Server).createToken},
+	{"GET", "/tokens/:id", (*Server).retrieveToken},
+
+	{"POST", "/customers", (*Server).createCustomer},
+	{"GET", "/customers", (*Server).listCustomers},
+	{"GET", "/customers/:id", (*Server).retrieveCustomer},
+	{"POST", "/customers/:id", (*Server).updateCustomer},
+	{"DELETE", "/customers/:id", (*Server).deleteCustomer},
+	{"DELETE", "/customers/:id/discount", (*Server).deleteDiscount},
+
+	{"POST", "/customers/:id/cards", (*Server).createCard},
+	{"GET", "/customers/:id/cards", (*Server).listCards},
+	{"GET", "/customers/:id/cards/:id", (*Server).retrieveCard},
+	{"POST", "/customers/:id/cards/:id", (*Server).updateCard},
+	{"DELETE", "/customers/:id/cards/:id", (*Server).deleteCard},
+
+	{"POST", "/customers/:id/subscriptions", (*Server).createSubscription},
+	{"GET", "/customers/:id/subscriptions", (*Server).listSubscriptions},
+	{"GET", "/customers/:id/subscriptions/:id", (*Server).retrieveSubscription},
+	{"POST", "/customers/:id/subscriptions/:id", (*Server).updateSubscription},
+	{"DELETE", "/customers/:id/subscriptions/:id", (*Server).cancelSubscription},
+
+	{"POST", "/charges", (*Server).createCharge},
+	{"GET", "/charges", (*Server).listCharges},
+	{"GET", "/charges/:id", (*Server).retrieveCharge},
+	{"POST", "/charges/:id", (*Server).updateCharge},
+	{"POST", "/charges/:id/capture", (*Server).captureCharge},
+	{"POST", "/charges/:id/refund", (*Server).refundCharge},
+
+	{"POST", "/plans", (*Server).createPlan},
+	{"GET", "/plans", (*Server).listPlans},
+	{"GET", "/plans/:id", (*Server).retrievePlan},
+	{"POST", "/plans/:id", (*Server).updatePlan},
+	{"DELETE", "/plans/:id", (*Server).deletePlan},
+
+	{"POST", "/coupons", (*Server).createCoupon},
+	{"GET", "/coupons", (*Server).listCoupons},
+	{"GET", "/coupons/:id", (*Server).retrieveCoupon},
+	{"DELETE", "/coupons/:id", (*Server).deleteCoupon},
+
+	{"POST", "/invoiceitems", (*Server).createInvoiceItem},
+	{"GET", "/invoiceitems", (*Server).listInvoiceItems},
+	{"GET", "/invoiceitems/:id", (*Server).retrieveInvoiceItem},
+	{"POST", "/invoiceitems/:id", (*Server).updateInvoiceItem},
+	{"DELETE", "/invoiceitems/:id", (*Server).deleteInvoiceItem},
+
+	{"POST", "/invoices", (*Server).createInvoice},
+	{"GET", "/invoices", (*Server).listInvoices},
+	{"GET", "/invoices/upcoming", (*Server).retrieveUpcomingInvoice},
+	{"GET", "/invoices/:id", (*Server).retrieveInvoice},
+	{"POST", "/invoices/:id", (*Server).updateInvoice},
+	{"POST", "/invoices/:id/pay", (*Server).payInvoice},
+	{"GET", "/invoices/:id/lines", (*Server).listInvoiceLines},
+
+	{"POST", "/transfers", (*Server).createTransfer},
+	{"GET", "/transfers", (*Server).listTransfers},
+	{"GET", "/transfers/:id", (*Server).retrieveTransfer},
+	{"POST", "/transfers/:id", (*Server).updateTransfer},
+	{"POST", "/transfers/:id/cancel", (*Server).cancelTransfer},
+
+	{"GET", "/events", (*Server).listEvents},
+	{"GET", "/events/:id", (*Server).retrieveEvent},
+}
+
+// apiError is an error response of the API.
+type apiError struct {
+	Status      int    `json:"-"`
+	Type        string `json:"type"`
+	Message     string `json:"message"`
+	Code        string `json:"code,omitempty"`
+	Param       string `json:"param,omitempty"`
+	DeclineCode string `json:"decline_code,omitempty"`
+}
+
+// response returns the status and body of the response for the error.
+func (e *apiError) response() (int, []byte) {
+	body, _ := json.Marshal(map[string]interface{}{"error": e})
+	return e.Status, body
+}
+
+// invalidRequest returns an invalid_request_error about param.
+func invalidRequest(param, format string, args ...interface{}) *apiError {
+	return &apiError{Status: http.StatusBadRequest, Type: "invalid_request_error", Message: fmt.Sprintf(format, args...), Param: param}
+}
+
+// notFound returns an invalid_request_error for an object that doesn't exist.
+func notFound(param, format string, args ...interface{}) *apiError {
+	e := invalidRequest(param, format, args...)
+	e.Status = http.StatusNotFound
+	return e
+}
+
+// request holds the ids in the path of a request, and its params.
+type request struct {
+	ids  []string
+	form url.Values
+	err  *apiError
+}
+
+// newRequest parses the params of r, from its query, or from its body for
+// methods other than GET, DELETE included.
+func newRequest(r *http.Request) (*request, *apiError) {
+	form := r.URL.Query()
+	if r.Method != "GET" {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, invalidRequest("", "Could not read the request body: %v", err)
+		}
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return nil, invalidRequest("", "Invalid request body: %v", err)
+		}
+		for k, v := range values {
+			form[k] = append(form[k], v...)
+		}
+	}
+	return &request{form: form}, nil
+}
+
+// has reports whether the param is set.
+func (r *request) has(name string) bool {
+	_, ok := r.form[name]
+	return ok
+}
+
+// str returns the param, or "" if it is not set.
+func (r *request) str(name string) string {
+	return r.form.Get(name)
+}
+
+// int returns the param as an integer, or 0 if it is not set. If it is not
+// an integer, the error is recorded in r.err.
+func (r *request) int(name string) int64 {
+	if !r.has(name) {
+		return 0
+	}
+	i, err := strconv.ParseInt(r.str(name), 10, 64)
+	if err != nil && r.err == nil {
+		r.err = invalidRequest(name, "Invalid integer: %s", r.str(name))
+	}
+	return i
+}
+
+// bool returns the param as a bool, or false if it is not set.
+func (r *request) bool(name string) bool {
+	b, err := strconv.ParseBool(r.str(name))
+	if r.has(name) && err != nil && r.err == nil {
+		r.err = invalidRequest(name, "Invalid boolean: %s", r.str(name))
+	}
+	return b
+}
+
+// require records an error in r.err if any of the params is not set.
+func (r *request) require(names ...string) {
+	for _, name := range names {
+		if !r.has(name) && r.err == nil {
+			r.err = invalidRequest(name, "Missing required param: %s.", name)
+		}
+	}
+}
+
+// metadata returns m, updated with the metadata[key] params. Keys set to an
+// empty value are deleted.
+func (r *request) metadata(m stripe.Metadata) stripe.Metadata {
+	for name := range r.form {
+		if !strings.HasPrefix(name, "metadata[") || !strings.HasSuffix(name, "]") {
+			continue
+		}
+		if m == nil {
+			m = stripe.Metadata{}
+		}
+		key := name[len("metadata[") : len(name)-1]
+		if value := r.str(name); value != "" {
+			m[key] = value
+		} else {
+			delete(m, key)
+		}
+	}
+	return m
+}
+
+// inRange reports whether the timestamp matches the param, either a timestamp
+// or a range given with name[gt], name[gte], name[lt] and name[lte].
+func (r *request) inRange(name string, timestamp int64) bool {
+	if r.has(name) && r.int(name) != timestamp {
+		return false
+	}
+	for _, bound := range []struct {
+		op string
+		ok func(limit int64) bool
+	}{
+		{"gt", func(limit int64) bool { return timestamp > limit }},
+		{"gte", func(limit int64) bool { return timestamp >= limit }},
+		{"lt", func(limit int64) bool { return timestamp < limit }},
+		{"lte", func(limit int64) bool { return timestamp <= limit }},
+	} {
+		key := name + "[" + bound.op + "]"
+		if r.has(key) && !bound.ok(r.int(key)) {
+			return false
+		}
+	}
+	return true
+}
+
+// list is a page of a list response.
+type list struct {
+	Object  string        `json:"object"`
+	Url     string        `json:"url"`
+	Count   int           `json:"count"`
+	HasMore bool          `json:"has_more"`
+	Data    []interface{} `json:"data"`
+}
+
+// page returns the page of objects, given oldest first, that the limit,
+// starting_after and ending_before params ask for. count is taken as limit,
+// as older clients send it, and offset skips that many objects when neither
+// starting_after nor ending_before is given. Pages list the newest objects
+// first.
+func (r *request) page(url string, objects []interface{}) (*list, *apiError) {
+	limit := 10
+	switch {
+	case r.has("limit"):
+		limit = int(r.int("limit"))
+	case r.has("count"):
+		limit = int(r.int("count"))
+	}
+	offset := int(r.int("offset"))
+	if r.err != nil {
+		return nil, r.err
+	}
+	if limit < 1 || limit > 100 {
+		return nil, invalidRequest("limit", "Invalid limit: must be between 1 and 100")
+	}
+	if offset < 0 {
+		return nil, invalidRequest("offset", "Invalid offset: must be at least 0")
+	}
+
+	newest := make([]interface{}, len(objects))
+	for i, o := range objects {
+		newest[len(objects)-1-i] = o
+	}
+
+	index := func(param string) (int, *apiError) {
+		id := r.str(param)
+		for i, o := range newest {
+			if idOf(o) == id {
+				return i, nil
+			}
+		}
+		return 0, invalidRequest(param, "No such object: %s", id)
+	}
+
+	// The cursors below start from elsewhere, and take the place of offset.
+	from, to, more := offset, len(newest), false
+	if from > to {
+		from = to
+	}
+	switch {
+	case r.str("ending_before") != "":
+		i, err := index("ending_before")
+		if err != nil {
+			return nil, err
+		}
+		from, to = i-limit, i
+		if from < 0 {
+			from = 0
+		}
+		more = from > 0
+	case r.str("starting_after") != "":
+		i, err := index("starting_after")
+		if err != nil {
+			return nil, err
+		}
+		from = i + 1
+		fallthrough
+	default:
+		if to-from > limit {
+			to, more = from+limit, true
+		}
+	}
+
+	data := newest[from:to]
+	if data == nil {
+		data = []interface{}{}
+	}
+	return &list{Object: "list", Url: "/v1" + url, Count: len(objects), HasMore: more, Data: data}, nil
+}
+
+// idOf returns the Id field of the object o points to.
+func idOf(o interface{}) string {
+	return reflect.Indirect(reflect.ValueOf(o)).FieldByName("Id").String()
+}
+
+// newId returns a new id, with the given prefix, unique within the Server.
+func (s *Server) newId(prefix string) string {
+	s.lastId++
+	return prefix + "_" + strconv.Itoa(s.lastId)
+}
+
+// put stores the object of the kind with the given id.
+func (s *Server) put(kind, id string, o interface{}) {
+	st, ok := s.stores[kind]
+	if !ok {
+		st = &store{objects: map[string]interface{}{}}
+		s.stores[kind] = st
+	}
+	if _, ok := st.objects[id]; !ok {
+		st.ids = append(st.ids, id)
+	}
+	st.objects[id] = o
+}
+
+// get returns the object of the kind with the given id, or nil.
+func (s *Server) get(kind, id string) interface{} {
+	if st, ok := s.stores[kind]; ok {
+		return st.objects[id]
+	}
+	return nil
+}
+
+// remove deletes the object of the kind with the given id.
+func (s *Server) remove(kind, id string) {
+	st, ok := s.stores[kind]
+	if !ok {
+		return
+	}
+	delete(st.objects, id)
+	for i, stored := range st.ids {
+		if stored == id {
+			st.ids = append(st.ids[:i:i], st.ids[i+1:]...)
+			break
+		}
+	}
+}
+
+// all returns the objects of the kind, oldest first.
+func (s *Server) all(kind string) []interface{} {
+	st, ok := s.stores[kind]
+	if !ok {
+		return nil
+	}
+	objects := make([]interface{}, len(st.ids))
+	for i, id := range st.ids {
+		objects[i] = st.objects[id]
+	}
+	return objects
+}
+
+// filter returns the objects of the kind for which keep returns true, oldest
+// first.
+func (s *Server) filter(kind string, keep func(o interface{}) bool) []interface{} {
+	var objects []interface{}
+	for _, o := range s.all(kind) {
+		if keep(o) {
+			objects = append(objects, o)
+		}
+	}
+	return objects
+}
+
+// deleted is the response to the deletion of the object with the given id.
+func deleted(id string) *stripe.DeleteResponse {
+	return &stripe.DeleteResponse{Id: id, Deleted: true}
+}
+
+// retrieveAccount answers GET /account.
+func (s *Server) retrieveAccount(r *request) (interface{}, *apiError) {
+	return &stripe.Account{
+		Id:                  "acct_stripetest",
+		Object:              "account",
+		ChargeEnabled:       true,
+		Country:             "US",
+		CurrenciesSupported: []string{"usd"},
+		DefaultCurrency:     "usd",
+		DetailsSubmitted:    true,
+		TransferEnabled:     true,
+		DisplayName:         "stripetest",
+		Email:               "test@example.com",
+	}, nil
+}
@@ -0,0 +1,166 @@
+package stripetest
+
+import (
+	"errors"
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"github.com/bmizerany/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// setup starts a Server, and returns it along with a Client of it.
+func setup() (*Server, stripe.Client) {
+	server := NewServer()
+	return server, stripe.NewClientWith(nil, server.URL, "sk_test_123")
+}
+
+// cardParams returns the CardParams of a card with the given number that
+// expires next year.
+func cardParams(number string) *stripe.CardParams {
+	return &stripe.CardParams{
+		Number:   stripe.String(number),
+		ExpMonth: stripe.Int(12),
+		ExpYear:  stripe.Int(time.Now().Year() + 1),
+		CVC:      stripe.String("123"),
+	}
+}
+
+// apiErr returns the error the API answered with.
+func apiErr(err error) *stripe.ErrorResponse {
+	var e *stripe.ErrorResponse
+	if !errors.As(err, &e) {
+		return &stripe.ErrorResponse{}
+	}
+	return e
+}
+
+func TestServerAuthentication(t *testing.T) {
+	server, _ := setup()
+	defer server.Close()
+
+	for _, key := range []string{"", "sk_live_123", "pk_test_123"} {
+		client := stripe.NewClientWith(nil, server.URL, key)
+		_, err := client.Customers.All()
+		var authErr *stripe.AuthenticationError
+		assert.T(t, errors.As(err, &authErr), key)
+	}
+}
+
+func TestServerUnknownEndpoint(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	_, err := client.Recipients.Retrieve("rp_123")
+	assert.T(t, errors.Is(err, stripe.ErrNotFound))
+	assert.Equal(t, apiErr(err).Err.Message, "Unrecognized request URL (GET: /v1/recipients/rp_123).")
+}
+
+func TestServerNotFound(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	_, err := client.Customers.Retrieve("cus_missing")
+	assert.T(t, errors.Is(err, stripe.ErrNotFound))
+	assert.Equal(t, apiErr(err).Err.Message, "No such customer: cus_missing")
+}
+
+func TestServerIdempotency(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	params := &stripe.ChargeParams{Amount: stripe.Int(100), Currency: stripe.String("usd"), CardParams: cardParams(CardVisa)}
+	first, err := client.Charges.Create(params, stripe.IdempotencyKey("key"))
+	assert.Equal(t, err, nil)
+	second, err := client.Charges.Create(params, stripe.IdempotencyKey("key"))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, second.Id, first.Id)
+
+	charges, _ := client.Charges.All()
+	assert.Equal(t, charges.Count, 1)
+}
+
+func TestServerRequestId(t *testing.T) {
+	server, _ := setup()
+	defer server.Close()
+
+	req, _ := http.NewRequest("GET", server.URL+"/account", nil)
+	req.Header.Set("Authorization", "Bearer sk_test_123")
+	res, err := http.DefaultClient.Do(req)
+	assert.Equal(t, err, nil)
+	res.Body.Close()
+	assert.Equal(t, res.StatusCode, http.StatusOK)
+	assert.NotEqual(t, res.Header.Get("Request-Id"), "")
+}
+
+func TestServerPagination(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	var ids []string
+	for i := 0; i < 15; i++ {
+		customer, _ := client.Customers.Create(&stripe.CustomerParams{})
+		ids = append(ids, customer.Id)
+	}
+
+	page, err := client.Customers.AllWithParams(&stripe.CustomerListParams{ListParams: stripe.ListParams{Limit: 5}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, page.Count, 15)
+	assert.Equal(t, len(page.Data), 5)
+	assert.Equal(t, page.Data[0].Id, ids[14])
+	assert.T(t, page.HasMore)
+
+	page, _ = client.Customers.AllWithParams(&stripe.CustomerListParams{ListParams: stripe.ListParams{Limit: 5, EndingBefore: ids[12]}})
+	assert.Equal(t, len(page.Data), 2)
+	assert.Equal(t, page.Data[0].Id, ids[14])
+
+	var seen []string
+	iter := client.Customers.Iter(&stripe.CustomerListParams{ListParams: stripe.ListParams{Limit: 4}})
+	for iter.Next() {
+		seen = append(seen, iter.Customer().Id)
+	}
+	assert.Equal(t, iter.Err(), nil)
+	assert.Equal(t, len(seen), 15)
+	assert.Equal(t, seen[14], ids[0])
+}
+
+func TestServerPaginationOffset(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		customer, _ := client.Customers.Create(&stripe.CustomerParams{})
+		ids = append(ids, customer.Id)
+	}
+
+	page, err := client.Customers.AllWithParams(&stripe.CustomerListParams{ListParams: stripe.ListParams{Offset: 2}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, page.Count, 3)
+	assert.Equal(t, len(page.Data), 1)
+	assert.Equal(t, page.Data[0].Id, ids[0])
+
+	page, _ = client.Customers.AllWithParams(&stripe.CustomerListParams{ListParams: stripe.ListParams{Offset: 5}})
+	assert.Equal(t, len(page.Data), 0)
+
+	_, err = client.Customers.AllWithParams(&stripe.CustomerListParams{ListParams: stripe.ListParams{Offset: -1}})
+	assert.Equal(t, apiErr(err).Err.Param, "offset")
+
+	// Iter only sends the offset for the first page.
+	var seen []string
+	iter := client.Customers.Iter(&stripe.CustomerListParams{ListParams: stripe.ListParams{Limit: 1, Offset: 1}})
+	for iter.Next() {
+		seen = append(seen, iter.Customer().Id)
+	}
+	assert.Equal(t, iter.Err(), nil)
+	assert.Equal(t, seen, []string{ids[1], ids[0]})
+}
+
+func TestServerAdvance(t *testing.T) {
+	server, _ := setup()
+	defer server.Close()
+
+	start := server.Now()
+	server.Advance(36 * time.Hour)
+	assert.Equal(t, server.Now(), start.Add(36*time.Hour))
+}
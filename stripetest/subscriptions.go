@@ -0,0 +1,297 @@
+package stripetest
+
+import (
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"time"
+)
+
+// isLive reports whether the subscription still bills its customer.
+func isLive(sub *stripe.Subscription) bool {
+	return sub.Status == "trialing" || sub.Status == "active" || sub.Status == "past_due"
+}
+
+// subscriptionsOf returns the Subscriptions of the customer that are not
+// canceled, oldest first.
+func (s *Server) subscriptionsOf(customerId string) []interface{} {
+	return s.filter("sub", func(o interface{}) bool {
+		sub := o.(*stripe.Subscription)
+		return sub.Customer == customerId && sub.Status != "canceled"
+	})
+}
+
+// customerSubscription returns the Subscription with the given id of the
+// customer in the path.
+func (s *Server) customerSubscription(r *request) (*stripe.Customer, *stripe.Subscription, *apiError) {
+	customer, err := s.customer(r.ids[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	sub, _ := s.get("sub", r.ids[1]).(*stripe.Subscription)
+	if sub == nil || sub.Customer != customer.Id {
+		return nil, nil, notFound("id", "Customer %s does not have a subscription with ID %s", customer.Id, r.ids[1])
+	}
+	return customer, sub, nil
+}
+
+// trialEnd returns the trial_end param, or 0 if it is not set. "now" ends the
+// trial at once.
+func (s *Server) trialEnd(r *request) (int64, *apiError) {
+	if r.str("trial_end") == "now" {
+		return s.now.Unix(), nil
+	}
+	end := r.int("trial_end")
+	if r.err != nil {
+		return 0, r.err
+	}
+	if r.has("trial_end") && end <= s.now.Unix() {
+		return 0, invalidRequest("trial_end", "Invalid timestamp: must be an integer Unix timestamp in the future.")
+	}
+	return end, nil
+}
+
+// subscribe subscribes the customer to the plan param, and pays the first
+// invoice. Nothing is stored unless it is paid.
+func (s *Server) subscribe(customer *stripe.Customer, r *request) (*stripe.Subscription, *apiError) {
+	r.require("plan")
+	quantity := int64(1)
+	if r.has("quantity") {
+		quantity = r.int("quantity")
+	}
+	trialEnd, err := s.trialEnd(r)
+	if err != nil {
+		return nil, err
+	}
+	plan, _ := s.get("plan", r.str("plan")).(*stripe.Plan)
+	if plan == nil {
+		return nil, invalidRequest("plan", "No such plan: %s", r.str("plan"))
+	}
+
+	copied := *plan
+	sub := &stripe.Subscription{
+		Id:                 s.newId("sub"),
+		Object:             "subscription",
+		Customer:           customer.Id,
+		Plan:               &copied,
+		Quantity:           quantity,
+		Start:              s.now.Unix(),
+		Status:             "active",
+		CurrentPeriodStart: s.now.Unix(),
+	}
+	if trialEnd == 0 && plan.TrialPeriodDays > 0 {
+		trialEnd = s.now.AddDate(0, 0, int(plan.TrialPeriodDays)).Unix()
+	}
+	if trialEnd > s.now.Unix() {
+		sub.Status, sub.TrialStart, sub.TrialEnd, sub.CurrentPeriodEnd = "trialing", s.now.Unix(), trialEnd, trialEnd
+	} else {
+		sub.CurrentPeriodEnd = periodEnd(plan, sub.CurrentPeriodStart)
+	}
+
+	invoice, items := s.newInvoice(customer, sub, sub.CurrentPeriodStart, sub.CurrentPeriodEnd)
+	if err := s.attempt(invoice); err != nil {
+		return nil, err
+	}
+
+	s.put("sub", sub.Id, sub)
+	s.emit("customer.subscription.created", sub, nil)
+	s.finalize(customer, invoice, items)
+	s.emit("invoice.payment_succeeded", invoice, nil)
+	return sub, nil
+}
+
+// cancel cancels the subscription at once.
+func (s *Server) cancel(sub *stripe.Subscription) {
+	sub.Status, sub.CanceledAt, sub.EndedAt = "canceled", s.now.Unix(), s.now.Unix()
+	s.emit("customer.subscription.deleted", sub, nil)
+}
+
+// renewSubscriptions starts the next period of the subscriptions whose
+// period has ended, and invoices them for it. Payment is attempted an hour
+// later, or at once if nothing is due. Subscriptions canceled at the end of
+// the period end instead.
+func (s *Server) renewSubscriptions() {
+	for _, o := range s.all("sub") {
+		sub := o.(*stripe.Subscription)
+		if !isLive(sub) || sub.CurrentPeriodEnd > s.now.Unix() {
+			continue
+		}
+		customer, _ := s.get("customer", sub.Customer).(*stripe.Customer)
+		if sub.CancelAtPeriodEnd || customer == nil {
+			s.cancel(sub)
+			continue
+		}
+
+		before := snapshot(sub)
+		sub.CurrentPeriodStart = sub.CurrentPeriodEnd
+		sub.CurrentPeriodEnd = periodEnd(sub.Plan, sub.CurrentPeriodStart)
+		if sub.Status == "trialing" {
+			sub.Status = "active"
+		}
+		s.emitUpdate("customer.subscription.updated", before, sub)
+
+		invoice, items := s.newInvoice(customer, sub, sub.CurrentPeriodStart, sub.CurrentPeriodEnd)
+		invoice.NextPaymentAttempt = s.now.Add(time.Hour).Unix()
+		s.finalize(customer, invoice, items)
+		if invoice.AmountDue == 0 {
+			s.pay(invoice)
+		}
+	}
+}
+
+// createSubscription answers POST /customers/{id}/subscriptions. A card
+// becomes the default card of the customer, and a coupon its discount, only
+// if the subscription is created.
+func (s *Server) createSubscription(r *request) (interface{}, *apiError) {
+	customer, err := s.customer(r.ids[0])
+	if err != nil {
+		return nil, err
+	}
+
+	card, err := s.cardParam(r)
+	if err != nil {
+		return nil, err
+	}
+	defaultCard, discount := customer.DefaultCard, customer.Discount
+	undo := func() {
+		if card != nil {
+			s.remove("card", card.Id)
+		}
+		if coupon, _ := s.get("coupon", r.str("coupon")).(*stripe.Coupon); coupon != nil && customer.Discount != discount {
+			coupon.TimesRedeemed--
+		}
+		customer.DefaultCard, customer.Discount = defaultCard, discount
+	}
+
+	if card != nil {
+		customer.DefaultCard = ""
+		if err := s.attach(customer, card); err != nil {
+			customer.DefaultCard = defaultCard
+			return nil, err
+		}
+	}
+	if r.has("coupon") {
+		if err := s.applyCoupon(customer, r.str("coupon")); err != nil {
+			undo()
+			return nil, err
+		}
+	}
+
+	sub, err := s.subscribe(customer, r)
+	if err != nil {
+		undo()
+		return nil, err
+	}
+	if card != nil && defaultCard != "" {
+		s.remove("card", defaultCard)
+	}
+	if customer.Discount != discount {
+		s.emit("customer.discount.created", customer.Discount, nil)
+	}
+	return sub, nil
+}
+
+// retrieveSubscription answers GET /customers/{id}/subscriptions/{id}.
+func (s *Server) retrieveSubscription(r *request) (interface{}, *apiError) {
+	_, sub, err := s.customerSubscription(r)
+	if err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// updateSubscription answers POST /customers/{id}/subscriptions/{id}. A new
+// plan or quantity is billed from the next period on, without proration.
+func (s *Server) updateSubscription(r *request) (interface{}, *apiError) {
+	customer, sub, err := s.customerSubscription(r)
+	if err != nil {
+		return nil, err
+	}
+	if !isLive(sub) {
+		return nil, invalidRequest("", "Subscription %s is canceled.", sub.Id)
+	}
+
+	var plan *stripe.Plan
+	if r.has("plan") {
+		if plan, _ = s.get("plan", r.str("plan")).(*stripe.Plan); plan == nil {
+			return nil, invalidRequest("plan", "No such plan: %s", r.str("plan"))
+		}
+	}
+	quantity := r.int("quantity")
+	trialEnd, err := s.trialEnd(r)
+	if err != nil {
+		return nil, err
+	}
+
+	card, err := s.cardParam(r)
+	if err != nil {
+		return nil, err
+	}
+	if card != nil {
+		old := customer.DefaultCard
+		customer.DefaultCard = ""
+		if err := s.attach(customer, card); err != nil {
+			customer.DefaultCard = old
+			return nil, err
+		}
+		if old != "" {
+			s.remove("card", old)
+		}
+	}
+	if r.has("coupon") {
+		if err := s.applyCoupon(customer, r.str("coupon")); err != nil {
+			return nil, err
+		}
+		s.emit("customer.discount.created", customer.Discount, nil)
+	}
+
+	before := snapshot(sub)
+	if plan != nil {
+		copied := *plan
+		sub.Plan = &copied
+	}
+	if r.has("quantity") {
+		sub.Quantity = quantity
+	}
+	if trialEnd != 0 {
+		sub.TrialEnd, sub.CurrentPeriodEnd = trialEnd, trialEnd
+		if sub.Status == "active" && trialEnd > s.now.Unix() {
+			sub.Status, sub.TrialStart = "trialing", s.now.Unix()
+		}
+	}
+	s.emitUpdate("customer.subscription.updated", before, sub)
+	return sub, nil
+}
+
+// cancelSubscription answers DELETE /customers/{id}/subscriptions/{id}. With
+// at_period_end, the subscription stays active until its period ends.
+func (s *Server) cancelSubscription(r *request) (interface{}, *apiError) {
+	_, sub, err := s.customerSubscription(r)
+	if err != nil {
+		return nil, err
+	}
+	if !isLive(sub) {
+		return nil, notFound("id", "Customer %s does not have a subscription with ID %s", sub.Customer, sub.Id)
+	}
+
+	atPeriodEnd := r.bool("at_period_end")
+	if r.err != nil {
+		return nil, r.err
+	}
+	if atPeriodEnd {
+		before := snapshot(sub)
+		sub.CancelAtPeriodEnd, sub.CanceledAt = true, s.now.Unix()
+		s.emitUpdate("customer.subscription.updated", before, sub)
+		return sub, nil
+	}
+
+	s.cancel(sub)
+	return sub, nil
+}
+
+// listSubscriptions answers GET /customers/{id}/subscriptions.
+func (s *Server) listSubscriptions(r *request) (interface{}, *apiError) {
+	customer, err := s.customer(r.ids[0])
+	if err != nil {
+		return nil, err
+	}
+	return r.page("/customers/"+customer.Id+"/subscriptions", s.subscriptionsOf(customer.Id))
+}
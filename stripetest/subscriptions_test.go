@@ -0,0 +1,152 @@
+package stripetest
+
+import (
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"github.com/bmizerany/assert"
+	"testing"
+	"time"
+)
+
+// createPlan creates a monthly plan with the given id, amount and trial.
+func createPlan(client stripe.Client, id string, amount, trialDays int) {
+	client.Plans.Create(&stripe.PlanParams{
+		Id:              stripe.String(id),
+		Amount:          stripe.Int(amount),
+		Currency:        stripe.String("usd"),
+		Interval:        stripe.String("month"),
+		Name:            stripe.String(id),
+		TrialPeriodDays: stripe.Int(trialDays),
+	})
+}
+
+func TestSubscriptionsCreate(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	createPlan(client, "gold", 2000, 0)
+	customer, _ := client.Customers.Create(&stripe.CustomerParams{CardParams: cardParams(CardVisa)})
+
+	sub, err := client.Subscriptions.Create(customer.Id, &stripe.SubscriptionParams{Plan: stripe.String("gold"), Quantity: stripe.Int(2)})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, sub.Status, "active")
+	assert.Equal(t, sub.CurrentPeriodEnd, server.Now().AddDate(0, 1, 0).Unix())
+
+	invoices, _ := client.Invoices.AllWithParams(&stripe.InvoiceListParams{Customer: customer.Id})
+	assert.Equal(t, invoices.Count, 1)
+	assert.T(t, invoices.Data[0].Paid)
+	assert.Equal(t, invoices.Data[0].Total, int64(4000))
+
+	charge, _ := client.Charges.Retrieve(invoices.Data[0].Charge.Id)
+	assert.Equal(t, charge.Amount, int64(4000))
+	assert.Equal(t, charge.Invoice.Id, invoices.Data[0].Id)
+}
+
+func TestSubscriptionsCreateDeclined(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	createPlan(client, "gold", 2000, 0)
+	_, err := client.Customers.Create(&stripe.CustomerParams{Plan: stripe.String("gold"), CardParams: cardParams(CardChargeFails)})
+	assert.Equal(t, apiErr(err).Err.Code, "card_declined")
+
+	customers, _ := client.Customers.All()
+	assert.Equal(t, customers.Count, 0)
+	invoices, _ := client.Invoices.All()
+	assert.Equal(t, invoices.Count, 0)
+}
+
+func TestSubscriptionsRenew(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	createPlan(client, "gold", 2000, 0)
+	customer, _ := client.Customers.Create(&stripe.CustomerParams{Plan: stripe.String("gold"), CardParams: cardParams(CardVisa)})
+	sub := customer.Subscriptions[0]
+
+	server.Advance(time.Unix(sub.CurrentPeriodEnd, 0).Sub(server.Now()) + 2*time.Hour)
+
+	renewed, _ := client.Subscriptions.Retrieve(customer.Id, sub.Id)
+	assert.Equal(t, renewed.CurrentPeriodStart, sub.CurrentPeriodEnd)
+
+	invoices, _ := client.Invoices.AllWithParams(&stripe.InvoiceListParams{Customer: customer.Id})
+	assert.Equal(t, invoices.Count, 2)
+	assert.T(t, invoices.Data[0].Paid)
+	assert.Equal(t, invoices.Data[0].PeriodStart, sub.CurrentPeriodEnd)
+}
+
+func TestSubscriptionsTrial(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	createPlan(client, "gold", 2000, 14)
+	customer, err := client.Customers.Create(&stripe.CustomerParams{Plan: stripe.String("gold")})
+	assert.Equal(t, err, nil)
+	sub := customer.Subscriptions[0]
+	assert.Equal(t, sub.Status, "trialing")
+	assert.Equal(t, sub.TrialEnd, server.Now().AddDate(0, 0, 14).Unix())
+
+	invoice, err := client.Invoices.RetrieveUpcoming(customer.Id)
+	assert.Equal(t, err, nil)
+	assert.Equal(t, invoice.AmountDue, int64(2000))
+	assert.Equal(t, invoice.Date, sub.TrialEnd)
+}
+
+func TestSubscriptionsPastDue(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	createPlan(client, "gold", 2000, 0)
+	customer, _ := client.Customers.Create(&stripe.CustomerParams{Plan: stripe.String("gold"), CardParams: cardParams(CardVisa)})
+	sub := customer.Subscriptions[0]
+	client.Customers.Update(customer.Id, &stripe.CustomerParams{CardParams: cardParams(CardChargeFails)})
+
+	untilRenewal := time.Unix(sub.CurrentPeriodEnd, 0).Sub(server.Now())
+	server.Advance(untilRenewal + 2*time.Hour)
+	sub2, _ := client.Subscriptions.Retrieve(customer.Id, sub.Id)
+	assert.Equal(t, sub2.Status, "past_due")
+
+	server.Advance(10 * 24 * time.Hour)
+	sub2, _ = client.Subscriptions.Retrieve(customer.Id, sub.Id)
+	assert.Equal(t, sub2.Status, "canceled")
+
+	events, _ := client.Events.AllWithParams(&stripe.EventListParams{Type: "invoice.payment_failed"})
+	assert.Equal(t, events.Count, 4)
+}
+
+func TestSubscriptionsCancelAtPeriodEnd(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	createPlan(client, "gold", 2000, 0)
+	customer, _ := client.Customers.Create(&stripe.CustomerParams{Plan: stripe.String("gold"), CardParams: cardParams(CardVisa)})
+	sub := customer.Subscriptions[0]
+
+	canceled, err := client.Subscriptions.Delete(customer.Id, sub.Id, &stripe.SubscriptionParams{AtPeriodEnd: stripe.Bool(true)})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, canceled.Status, "active")
+	assert.T(t, canceled.CancelAtPeriodEnd)
+
+	server.Advance(time.Unix(sub.CurrentPeriodEnd, 0).Sub(server.Now()))
+	canceled, _ = client.Subscriptions.Retrieve(customer.Id, sub.Id)
+	assert.Equal(t, canceled.Status, "canceled")
+
+	invoices, _ := client.Invoices.All()
+	assert.Equal(t, invoices.Count, 1)
+}
+
+func TestSubscriptionsUpdate(t *testing.T) {
+	server, client := setup()
+	defer server.Close()
+
+	createPlan(client, "gold", 2000, 0)
+	createPlan(client, "silver", 1000, 0)
+	customer, _ := client.Customers.Create(&stripe.CustomerParams{Plan: stripe.String("gold"), CardParams: cardParams(CardVisa)})
+
+	sub, err := client.Subscriptions.Update(customer.Id, customer.Subscriptions[0].Id, &stripe.SubscriptionParams{Plan: stripe.String("silver")})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, sub.Plan.Id, "silver")
+
+	subs, _ := client.Subscriptions.All(customer.Id)
+	assert.Equal(t, subs.Count, 1)
+	assert.Equal(t, subs.Data[0].Plan.Id, "silver")
+}
@@ -0,0 +1,167 @@
+package webhook
+
+import (
+	"context"
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultRetention is how long a Deduplicator remembers an event, unless set
+// otherwise. Stripe retries the delivery of an event for up to three days.
+const DefaultRetention = 7 * 24 * time.Hour
+
+// expireInterval is how often a Deduplicator expires the Records of its Store.
+const expireInterval = time.Minute
+
+// Deduplicator makes sure each event is handled once, even though Stripe may
+// deliver it several times. It wraps HandlerFuncs so that:
+//
+//   - an event that was handled is skipped when delivered again, as long as
+//     it is remembered, for the retention period after it was handled.
+//   - an event whose handling failed is handled again when delivered again.
+//   - deliveries of the same event that arrive at once are handled one after
+//     the other, so the later ones are skipped if the first succeeds.
+//
+// An event counts as handled once its HandlerFunc returns nil, or an error
+// answered with a 2xx status (see WithStatus).
+//
+// Deliveries are serialized within the process only; processes sharing a
+// Store may handle the same event at once.
+type Deduplicator struct {
+	store     Store
+	retention time.Duration
+	now       func() time.Time
+
+	mu         sync.Mutex
+	locks      map[string]*eventLock
+	lastExpire time.Time
+}
+
+// eventLock serializes the deliveries of an event. refs counts the deliveries
+// holding or waiting for it, so that it is dropped after the last one.
+type eventLock struct {
+	ch   chan struct{}
+	refs int
+}
+
+// NewDeduplicator returns a Deduplicator that keeps the Records of the events
+// it processes in store, for retention, or DefaultRetention if 0.
+func NewDeduplicator(store Store, retention time.Duration) *Deduplicator {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	return &Deduplicator{
+		store:     store,
+		retention: retention,
+		now:       time.Now,
+		locks:     map[string]*eventLock{},
+	}
+}
+
+// Wrap returns a HandlerFunc that processes each event with fn through
+// Process:
+//
+//	dedup := webhook.NewDeduplicator(store, 0)
+//	handler.Handle("charge.succeeded", dedup.Wrap(fulfillOrder))
+func (d *Deduplicator) Wrap(fn HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, event *stripe.Event) error {
+		return d.Process(ctx, event, fn)
+	}
+}
+
+// Process calls fn with event unless it was already handled, and records the
+// outcome. If ctx is done while waiting for another delivery of the event,
+// ctx.Err() is returned.
+//
+// If fn succeeds but its Record can't be stored, the error of the Store is
+// returned with a 200 status, so that the Handler reports it without Stripe
+// delivering the event again.
+func (d *Deduplicator) Process(ctx context.Context, event *stripe.Event, fn HandlerFunc) error {
+	unlock, err := d.lock(ctx, event.Id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	now := d.now()
+	d.expire(ctx, now)
+
+	record, err := d.store.Get(ctx, event.Id)
+	if err != nil {
+		return err
+	}
+	if record != nil && !now.Before(record.Updated.Add(d.retention)) {
+		record = nil
+	}
+	if record != nil && record.Done {
+		return nil
+	}
+	if record == nil {
+		record = &Record{EventId: event.Id, Type: event.Type}
+	}
+
+	err = fn(ctx, event)
+
+	record.Attempts++
+	record.Updated = d.now()
+	record.Done = err == nil || statusOf(err) < 300
+	record.Err = ""
+	if err != nil {
+		record.Err = err.Error()
+	}
+
+	if putErr := d.store.Put(ctx, record); putErr != nil && err == nil {
+		return WithStatus(http.StatusOK, putErr)
+	}
+	return err
+}
+
+// lock waits until no other delivery of the event with the given id is being
+// processed, and returns the func that lets the next one through.
+func (d *Deduplicator) lock(ctx context.Context, id string) (func(), error) {
+	d.mu.Lock()
+	l, ok := d.locks[id]
+	if !ok {
+		l = &eventLock{ch: make(chan struct{}, 1)}
+		d.locks[id] = l
+	}
+	l.refs++
+	d.mu.Unlock()
+
+	release := func() {
+		d.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(d.locks, id)
+		}
+		d.mu.Unlock()
+	}
+
+	select {
+	case l.ch <- struct{}{}:
+		return func() {
+			<-l.ch
+			release()
+		}, nil
+	case <-ctx.Done():
+		release()
+		return nil, ctx.Err()
+	}
+}
+
+// expire deletes the Records that are past the retention period, at most
+// once every expireInterval. As it is only housekeeping, errors are ignored:
+// what failed to expire is expired the next time.
+func (d *Deduplicator) expire(ctx context.Context, now time.Time) {
+	d.mu.Lock()
+	due := now.Sub(d.lastExpire) >= expireInterval
+	if due {
+		d.lastExpire = now
+	}
+	d.mu.Unlock()
+
+	if due {
+		d.store.Expire(ctx, now.Add(-d.retention))
+	}
+}
@@ -0,0 +1,185 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"github.com/bmizerany/assert"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// counter returns a HandlerFunc that counts its calls, and returns the errors
+// in errs in turn, then nil.
+func counter(calls *int, errs ...error) HandlerFunc {
+	return func(ctx context.Context, event *stripe.Event) error {
+		*calls++
+		if len(errs) == 0 {
+			return nil
+		}
+		err := errs[0]
+		errs = errs[1:]
+		return err
+	}
+}
+
+func TestDeduplicatorSkipsDuplicates(t *testing.T) {
+	store := NewMemoryStore()
+	d := NewDeduplicator(store, 0)
+	event := &stripe.Event{Id: "evt_123", Type: "charge.succeeded"}
+
+	calls := 0
+	fn := d.Wrap(counter(&calls))
+	assert.Equal(t, fn(context.Background(), event), nil)
+	assert.Equal(t, fn(context.Background(), event), nil)
+	assert.Equal(t, calls, 1)
+
+	// Other events are not duplicates.
+	assert.Equal(t, fn(context.Background(), &stripe.Event{Id: "evt_456"}), nil)
+	assert.Equal(t, calls, 2)
+
+	record, _ := store.Get(context.Background(), "evt_123")
+	assert.Equal(t, record.Type, "charge.succeeded")
+	assert.Equal(t, record.Attempts, 1)
+	assert.T(t, record.Done)
+}
+
+func TestDeduplicatorRetriesFailures(t *testing.T) {
+	store := NewMemoryStore()
+	d := NewDeduplicator(store, 0)
+	event := &stripe.Event{Id: "evt_123"}
+
+	calls := 0
+	fn := d.Wrap(counter(&calls, errors.New("database is down")))
+	assert.Equal(t, fn(context.Background(), event).Error(), "database is down")
+
+	record, _ := store.Get(context.Background(), "evt_123")
+	assert.T(t, !record.Done)
+	assert.Equal(t, record.Err, "database is down")
+
+	assert.Equal(t, fn(context.Background(), event), nil)
+	assert.Equal(t, fn(context.Background(), event), nil)
+	assert.Equal(t, calls, 2)
+
+	record, _ = store.Get(context.Background(), "evt_123")
+	assert.T(t, record.Done)
+	assert.Equal(t, record.Attempts, 2)
+	assert.Equal(t, record.Err, "")
+}
+
+func TestDeduplicatorAcknowledgedErrors(t *testing.T) {
+	d := NewDeduplicator(NewMemoryStore(), 0)
+	event := &stripe.Event{Id: "evt_123"}
+
+	calls := 0
+	fn := d.Wrap(counter(&calls, WithStatus(http.StatusOK, errors.New("unknown order"))))
+	assert.Equal(t, fn(context.Background(), event).Error(), "unknown order")
+	assert.Equal(t, fn(context.Background(), event), nil)
+	assert.Equal(t, calls, 1)
+}
+
+func TestDeduplicatorRetention(t *testing.T) {
+	store := NewMemoryStore()
+	d := NewDeduplicator(store, time.Hour)
+	now := time.Unix(1400000000, 0)
+	d.now = func() time.Time { return now }
+	event := &stripe.Event{Id: "evt_123"}
+
+	calls := 0
+	fn := d.Wrap(counter(&calls))
+	fn(context.Background(), event)
+
+	now = now.Add(59 * time.Minute)
+	fn(context.Background(), event)
+	assert.Equal(t, calls, 1)
+
+	// Past the retention, the event is forgotten, and expired from the Store.
+	now = now.Add(2 * time.Minute)
+	fn(context.Background(), &stripe.Event{Id: "evt_456"})
+	record, _ := store.Get(context.Background(), "evt_123")
+	assert.Equal(t, record, (*Record)(nil))
+
+	fn(context.Background(), event)
+	assert.Equal(t, calls, 3)
+}
+
+func TestDeduplicatorSerializesDeliveries(t *testing.T) {
+	d := NewDeduplicator(NewMemoryStore(), 0)
+	event := &stripe.Event{Id: "evt_123"}
+
+	var mu sync.Mutex
+	calls, active, maxActive := 0, 0, 0
+	fn := d.Wrap(func(ctx context.Context, event *stripe.Event) error {
+		mu.Lock()
+		calls++
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn(context.Background(), event)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, calls, 1)
+	assert.Equal(t, maxActive, 1)
+	assert.Equal(t, len(d.locks), 0)
+}
+
+func TestDeduplicatorCanceledWhileWaiting(t *testing.T) {
+	d := NewDeduplicator(NewMemoryStore(), 0)
+	event := &stripe.Event{Id: "evt_123"}
+
+	started, release := make(chan struct{}), make(chan struct{})
+	go d.Process(context.Background(), event, func(ctx context.Context, event *stripe.Event) error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := d.Process(ctx, event, counter(new(int)))
+	assert.Equal(t, err, context.DeadlineExceeded)
+	assert.Equal(t, statusOf(err), http.StatusServiceUnavailable)
+	close(release)
+}
+
+// failingStore is a Store whose Put always fails.
+type failingStore struct{ *MemoryStore }
+
+func (s failingStore) Put(ctx context.Context, record *Record) error {
+	return errors.New("disk is full")
+}
+
+func TestDeduplicatorStoreError(t *testing.T) {
+	d := NewDeduplicator(failingStore{NewMemoryStore()}, 0)
+	event := &stripe.Event{Id: "evt_123"}
+
+	// The event was handled, so it is acknowledged.
+	err := d.Process(context.Background(), event, counter(new(int)))
+	assert.Equal(t, err.Error(), "disk is full")
+	assert.Equal(t, statusOf(err), http.StatusOK)
+
+	// The error of the HandlerFunc wins.
+	err = d.Process(context.Background(), event, counter(new(int), errors.New("database is down")))
+	assert.Equal(t, err.Error(), "database is down")
+}
@@ -0,0 +1,210 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// MaxBodyBytes is the size above which the Handler rejects a payload with a
+// 413, without reading it further.
+const MaxBodyBytes = 1 << 20
+
+// HandlerFunc handles an event of a type it was registered for. Returning an
+// error makes the Handler answer with a status other than 2xx, so that Stripe
+// delivers the event again later; see StatusError.
+type HandlerFunc func(ctx context.Context, event *stripe.Event) error
+
+// StatusError is an error that sets the HTTP status the Handler answers with.
+// HandlerFuncs return it, through WithStatus, when the default status of
+// their errors does not fit.
+type StatusError struct {
+	Status int
+	Err    error
+}
+
+// StatusError must implement an Error() method to satisfy the error interface.
+func (e *StatusError) Error() string { return e.Err.Error() }
+
+// Unwrap returns the underlying error.
+func (e *StatusError) Unwrap() error { return e.Err }
+
+// WithStatus returns err as a StatusError with the given status. An error with
+// a 2xx status tells Stripe the event was handled, so that it is not sent
+// again, as for an event that can never be handled:
+//
+//	return webhook.WithStatus(http.StatusOK, err)
+func WithStatus(status int, err error) error {
+	return &StatusError{Status: status, Err: err}
+}
+
+// Handler is an http.Handler for a webhook endpoint. It verifies the signature
+// of each event it receives, and calls the HandlerFunc registered for its type:
+//
+//	handler := webhook.NewHandler([]string{secret})
+//	handler.Handle("charge.succeeded", fulfillOrder)
+//	handler.Handle("invoice.*", syncInvoice)
+//	http.Handle("/webhook", handler)
+//
+// It answers with:
+//
+//   - 200 once the HandlerFunc returns nil, or when no HandlerFunc is
+//     registered for the type of the event.
+//   - 400 when the signature or the payload is invalid, 405 when the request
+//     is not a POST and 413 when the payload is larger than MaxBodyBytes.
+//   - the status of a StatusError returned by the HandlerFunc, 503 for a
+//     context error, such as an exceeded deadline, and 500 for any other.
+type Handler struct {
+	secrets []string
+	opts    []Option
+
+	mu       sync.RWMutex
+	handlers map[string]HandlerFunc
+	patterns []string // the registered patterns, longest first
+	fallback HandlerFunc
+	onError  func(event *stripe.Event, err error)
+}
+
+// NewHandler returns a Handler verifying events against secrets, as
+// ConstructEvent does with opts.
+func NewHandler(secrets []string, opts ...Option) *Handler {
+	return &Handler{
+		secrets:  secrets,
+		opts:     opts,
+		handlers: map[string]HandlerFunc{},
+	}
+}
+
+// Handle registers fn for events of the given type, such as
+// "charge.succeeded", or of the types pattern matches as stripe.MatchEventType
+// does, such as "invoice.*", "customer.subscription.*" or "*". An exact type is
+// preferred over a wildcard, and a longer wildcard over a shorter one.
+//
+// Handle panics if a HandlerFunc is already registered for pattern.
+func (h *Handler) Handle(pattern string, fn HandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if pattern == "" || fn == nil {
+		panic("webhook: invalid registration for " + pattern)
+	}
+	if _, ok := h.handlers[pattern]; ok {
+		panic("webhook: multiple registrations for " + pattern)
+	}
+	h.handlers[pattern] = fn
+
+	h.patterns = append(h.patterns, pattern)
+	sort.Slice(h.patterns, func(i, j int) bool { return len(h.patterns[i]) > len(h.patterns[j]) })
+}
+
+// HandleFallback registers fn for the events no other HandlerFunc matches.
+// Without one, those events are acknowledged with a 200.
+func (h *Handler) HandleFallback(fn HandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.fallback = fn
+}
+
+// OnError registers fn to be called with every error the Handler answers
+// with, such as to log it. event is nil if the request was rejected before
+// the event could be decoded.
+func (h *Handler) OnError(fn func(event *stripe.Event, err error)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onError = fn
+}
+
+// handlerFor returns the HandlerFunc registered for events of type t, or nil.
+func (h *Handler) handlerFor(t string) HandlerFunc {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if fn, ok := h.handlers[t]; ok {
+		return fn
+	}
+	for _, pattern := range h.patterns {
+		if stripe.MatchEventType(pattern, t) {
+			return h.handlers[pattern]
+		}
+	}
+	return h.fallback
+}
+
+// ServeHTTP verifies the event in the body of r, and calls the HandlerFunc
+// registered for its type.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		h.fail(w, nil, http.StatusMethodNotAllowed, errors.New("webhook: method "+r.Method+" not allowed"))
+		return
+	}
+
+	payload, err := ioutil.ReadAll(io.LimitReader(r.Body, MaxBodyBytes+1))
+	if err != nil {
+		h.fail(w, nil, http.StatusBadRequest, err)
+		return
+	}
+	if len(payload) > MaxBodyBytes {
+		h.fail(w, nil, http.StatusRequestEntityTooLarge, errors.New("webhook: payload too large"))
+		return
+	}
+
+	event, err := ConstructEvent(payload, r.Header.Get(SignatureHeader), h.secrets, h.opts...)
+	if err != nil {
+		h.fail(w, nil, http.StatusBadRequest, err)
+		return
+	}
+
+	fn := h.handlerFor(event.Type)
+	if fn == nil {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if err := fn(r.Context(), event); err != nil {
+		h.fail(w, event, statusOf(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// fail reports err to the OnError func, and answers with status. The message
+// of errors raised by HandlerFuncs is not sent back, only that of those about
+// the request itself.
+func (h *Handler) fail(w http.ResponseWriter, event *stripe.Event, status int, err error) {
+	h.mu.RLock()
+	onError := h.onError
+	h.mu.RUnlock()
+
+	if onError != nil {
+		onError(event, err)
+	}
+
+	switch {
+	case status < 300:
+		w.WriteHeader(status)
+	case event != nil:
+		http.Error(w, http.StatusText(status), status)
+	default:
+		http.Error(w, err.Error(), status)
+	}
+}
+
+// statusOf returns the status to answer with for err, returned by a
+// HandlerFunc.
+func statusOf(err error) int {
+	var statusErr *StatusError
+	switch {
+	case errors.As(err, &statusErr):
+		return statusErr.Status
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,163 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"github.com/bmizerany/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// deliver sends an event of type t, signed with secret, to h, and returns the
+// response.
+func deliver(h http.Handler, t string) *httptest.ResponseRecorder {
+	payload, header, _ := GenerateTestPayload(&stripe.Event{Id: "evt_123456789", Type: t}, secret)
+	req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(payload))
+	req.Header.Set(SignatureHeader, header)
+
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	return res
+}
+
+// record returns a HandlerFunc that appends name to calls, and returns err.
+func record(calls *[]string, name string, err error) HandlerFunc {
+	return func(ctx context.Context, event *stripe.Event) error {
+		*calls = append(*calls, name+" "+event.Type)
+		return err
+	}
+}
+
+func TestHandlerRouting(t *testing.T) {
+	var calls []string
+	h := NewHandler([]string{secret})
+	h.Handle("charge.succeeded", record(&calls, "charge", nil))
+	h.Handle("invoice.*", record(&calls, "invoice", nil))
+	h.Handle("customer.*", record(&calls, "customer", nil))
+	h.Handle("customer.subscription.*", record(&calls, "subscription", nil))
+	h.Handle("customer.subscription.deleted", record(&calls, "deleted", nil))
+
+	for _, typ := range []string{
+		"charge.succeeded",
+		"invoice.payment_failed",
+		"invoice.created",
+		"customer.updated",
+		"customer.subscription.updated",
+		"customer.subscription.deleted",
+		"charge.failed",
+		"invoiceitem.created",
+	} {
+		res := deliver(h, typ)
+		assert.Equal(t, res.Code, http.StatusOK)
+	}
+
+	assert.Equal(t, calls, []string{
+		"charge charge.succeeded",
+		"invoice invoice.payment_failed",
+		"invoice invoice.created",
+		"customer customer.updated",
+		"subscription customer.subscription.updated",
+		"deleted customer.subscription.deleted",
+	})
+}
+
+func TestHandlerFallback(t *testing.T) {
+	var calls []string
+	h := NewHandler([]string{secret})
+	h.Handle("charge.succeeded", record(&calls, "charge", nil))
+	h.HandleFallback(record(&calls, "fallback", nil))
+
+	deliver(h, "charge.succeeded")
+	deliver(h, "transfer.paid")
+	assert.Equal(t, calls, []string{"charge charge.succeeded", "fallback transfer.paid"})
+
+	// A "*" wildcard matches before the fallback.
+	h.Handle("*", record(&calls, "any", nil))
+	deliver(h, "transfer.paid")
+	assert.Equal(t, calls[2], "any transfer.paid")
+}
+
+func TestHandlerErrorStatuses(t *testing.T) {
+	var calls []string
+	var reported []error
+	h := NewHandler([]string{secret})
+	h.OnError(func(event *stripe.Event, err error) { reported = append(reported, err) })
+	h.Handle("charge.failed", record(&calls, "failed", errors.New("database is down")))
+	h.Handle("charge.refunded", record(&calls, "refunded", context.DeadlineExceeded))
+	h.Handle("charge.dispute.created", record(&calls, "dispute", WithStatus(http.StatusConflict, errors.New("busy"))))
+	h.Handle("charge.captured", record(&calls, "captured", WithStatus(http.StatusOK, errors.New("unknown order"))))
+
+	res := deliver(h, "charge.failed")
+	assert.Equal(t, res.Code, http.StatusInternalServerError)
+	assert.T(t, !strings.Contains(res.Body.String(), "database"), res.Body.String())
+
+	res = deliver(h, "charge.refunded")
+	assert.Equal(t, res.Code, http.StatusServiceUnavailable)
+
+	res = deliver(h, "charge.dispute.created")
+	assert.Equal(t, res.Code, http.StatusConflict)
+
+	res = deliver(h, "charge.captured")
+	assert.Equal(t, res.Code, http.StatusOK)
+
+	assert.Equal(t, len(calls), 4)
+	assert.Equal(t, len(reported), 4)
+	assert.Equal(t, reported[0].Error(), "database is down")
+}
+
+func TestHandlerRejectsRequests(t *testing.T) {
+	var calls []string
+	var reported []error
+	h := NewHandler([]string{secret})
+	h.OnError(func(event *stripe.Event, err error) {
+		assert.Equal(t, event, (*stripe.Event)(nil))
+		reported = append(reported, err)
+	})
+	h.Handle("*", record(&calls, "any", nil))
+
+	// Wrong method.
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, httptest.NewRequest("GET", "/webhook", nil))
+	assert.Equal(t, res.Code, http.StatusMethodNotAllowed)
+	assert.Equal(t, res.Header().Get("Allow"), "POST")
+
+	// Bad signature.
+	payload, _, _ := GenerateTestPayload(&stripe.Event{Id: "evt_123", Type: "charge.succeeded"}, secret)
+	req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(payload))
+	req.Header.Set(SignatureHeader, "t=1400000000,v1=abcdef")
+	res = httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	assert.Equal(t, res.Code, http.StatusBadRequest)
+	assert.T(t, strings.Contains(res.Body.String(), "no signature matches"), res.Body.String())
+
+	// Unsigned.
+	res = httptest.NewRecorder()
+	h.ServeHTTP(res, httptest.NewRequest("POST", "/webhook", bytes.NewReader(payload)))
+	assert.Equal(t, res.Code, http.StatusBadRequest)
+
+	// Too large.
+	large := bytes.Repeat([]byte(" "), MaxBodyBytes+1)
+	req = httptest.NewRequest("POST", "/webhook", bytes.NewReader(large))
+	req.Header.Set(SignatureHeader, GenerateHeader(large, signedAt, secret))
+	res = httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	assert.Equal(t, res.Code, http.StatusRequestEntityTooLarge)
+
+	assert.Equal(t, len(calls), 0)
+	assert.Equal(t, len(reported), 4)
+	assert.T(t, errors.Is(reported[2], ErrNotSigned))
+}
+
+func TestHandlerDuplicateRegistration(t *testing.T) {
+	h := NewHandler([]string{secret})
+	h.Handle("invoice.*", record(new([]string), "invoice", nil))
+
+	defer func() {
+		assert.Equal(t, recover(), "webhook: multiple registrations for invoice.*")
+	}()
+	h.Handle("invoice.*", record(new([]string), "invoice", nil))
+}
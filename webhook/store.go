@@ -0,0 +1,247 @@
+package webhook
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Record is what a Store keeps of an event that was processed: whether it was
+// handled, and the error of the last attempt if not.
+type Record struct {
+	EventId  string    `json:"id"`
+	Type     string    `json:"type"`
+	Done     bool      `json:"done"`
+	Attempts int       `json:"attempts"`
+	Err      string    `json:"error,omitempty"`
+	Updated  time.Time `json:"updated"`
+}
+
+// Store keeps the Records of the events a Deduplicator processed. Its methods
+// may be called concurrently, though never for the same event at once.
+type Store interface {
+	// Get returns the Record of the event with the given id, or nil if there
+	// is none.
+	Get(ctx context.Context, eventId string) (*Record, error)
+
+	// Put adds record, or replaces the one of the same event.
+	Put(ctx context.Context, record *Record) error
+
+	// Expire deletes the Records last updated before t.
+	Expire(ctx context.Context, before time.Time) error
+}
+
+// MemoryStore is a Store that keeps Records in memory, for a single process
+// that can afford to forget them when it restarts.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: map[string]Record{}}
+}
+
+// Get returns a copy of the Record of the event with the given id, or nil.
+func (s *MemoryStore) Get(ctx context.Context, eventId string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[eventId]
+	if !ok {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+// Put keeps a copy of record.
+func (s *MemoryStore) Put(ctx context.Context, record *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[record.EventId] = *record
+	return nil
+}
+
+// Expire deletes the Records last updated before t.
+func (s *MemoryStore) Expire(ctx context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, record := range s.records {
+		if record.Updated.Before(before) {
+			delete(s.records, id)
+		}
+	}
+	return nil
+}
+
+// FileStore is a Store that keeps Records in a file, so that they outlive the
+// process. Records are appended to the file as JSON lines when Put, and the
+// file is rewritten without those that expired by Expire. Every Record is
+// also kept in memory, so a FileStore suits a single process handling a
+// moderate number of events.
+type FileStore struct {
+	mu      sync.Mutex
+	path    string
+	file    *os.File
+	records map[string]Record
+}
+
+// OpenFileStore returns a FileStore keeping Records in the file at path,
+// loading those already in it. The file is created if it does not exist.
+func OpenFileStore(path string) (*FileStore, error) {
+	s := &FileStore{path: path, records: map[string]Record{}}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	s.file = file
+	return s, nil
+}
+
+// load reads the Records in the file, the last line of each event winning. A
+// last line that was cut short, by a crash while it was written, is dropped.
+func (s *FileStore) load() error {
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if end := bytes.LastIndexByte(data, '\n') + 1; end < len(data) {
+		if err := os.Truncate(s.path, int64(end)); err != nil {
+			return err
+		}
+		data = data[:end]
+	}
+
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		record := Record{}
+		if err := json.Unmarshal(line, &record); err != nil {
+			return fmt.Errorf("webhook: %s, line %d: %v", s.path, i+1, err)
+		}
+		s.records[record.EventId] = record
+	}
+	return nil
+}
+
+// Get returns a copy of the Record of the event with the given id, or nil.
+func (s *FileStore) Get(ctx context.Context, eventId string) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[eventId]
+	if !ok {
+		return nil, nil
+	}
+	return &record, nil
+}
+
+// Put appends record to the file, and syncs it.
+func (s *FileStore) Put(ctx context.Context, record *Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	if err := s.file.Sync(); err != nil {
+		return err
+	}
+
+	s.records[record.EventId] = *record
+	return nil
+}
+
+// Expire deletes the Records last updated before t, and rewrites the file
+// with those that are left. The file is replaced at once, so that a crash
+// leaves either the old or the new one.
+func (s *FileStore) Expire(ctx context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expired := false
+	for _, record := range s.records {
+		if record.Updated.Before(before) {
+			expired = true
+			break
+		}
+	}
+	if !expired {
+		return nil
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	kept := map[string]Record{}
+	for id, record := range s.records {
+		if record.Updated.Before(before) {
+			continue
+		}
+		line, err := json.Marshal(record)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+		w.Write(append(line, '\n'))
+		kept[id] = record
+	}
+
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	s.file.Close()
+	s.file, s.records = file, kept
+	return nil
+}
+
+// Close closes the file.
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
@@ -0,0 +1,118 @@
+package webhook
+
+import (
+	"context"
+	"github.com/bmizerany/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testStore checks the behaviour every Store must have.
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+	at := time.Unix(1400000000, 0).UTC()
+
+	record, err := store.Get(ctx, "evt_123")
+	assert.Equal(t, err, nil)
+	assert.Equal(t, record, (*Record)(nil))
+
+	assert.Equal(t, store.Put(ctx, &Record{EventId: "evt_123", Attempts: 1, Err: "failed", Updated: at}), nil)
+	assert.Equal(t, store.Put(ctx, &Record{EventId: "evt_123", Done: true, Attempts: 2, Updated: at.Add(time.Hour)}), nil)
+	assert.Equal(t, store.Put(ctx, &Record{EventId: "evt_456", Done: true, Attempts: 1, Updated: at}), nil)
+
+	record, _ = store.Get(ctx, "evt_123")
+	assert.Equal(t, *record, Record{EventId: "evt_123", Done: true, Attempts: 2, Updated: at.Add(time.Hour)})
+
+	// Records are copies.
+	record.Attempts = 10
+	record, _ = store.Get(ctx, "evt_123")
+	assert.Equal(t, record.Attempts, 2)
+
+	assert.Equal(t, store.Expire(ctx, at.Add(time.Minute)), nil)
+	record, _ = store.Get(ctx, "evt_456")
+	assert.Equal(t, record, (*Record)(nil))
+	record, _ = store.Get(ctx, "evt_123")
+	assert.Equal(t, record.Attempts, 2)
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+// tempPath returns the path of a file in a new temporary directory, and the
+// func that removes it.
+func tempPath(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "webhook")
+	assert.Equal(t, err, nil)
+	return filepath.Join(dir, "events.jsonl"), func() { os.RemoveAll(dir) }
+}
+
+func TestFileStore(t *testing.T) {
+	path, cleanup := tempPath(t)
+	defer cleanup()
+
+	store, err := OpenFileStore(path)
+	assert.Equal(t, err, nil)
+	testStore(t, store)
+	assert.Equal(t, store.Close(), nil)
+
+	// The file only holds the Records left after Expire.
+	data, _ := ioutil.ReadFile(path)
+	assert.Equal(t, strings.Count(string(data), "\n"), 1)
+}
+
+func TestFileStoreReopen(t *testing.T) {
+	path, cleanup := tempPath(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	store, _ := OpenFileStore(path)
+	store.Put(ctx, &Record{EventId: "evt_123", Attempts: 1})
+	store.Put(ctx, &Record{EventId: "evt_123", Done: true, Attempts: 2})
+	store.Close()
+
+	store, err := OpenFileStore(path)
+	assert.Equal(t, err, nil)
+	defer store.Close()
+	record, _ := store.Get(ctx, "evt_123")
+	assert.Equal(t, record.Attempts, 2)
+	assert.T(t, record.Done)
+}
+
+func TestFileStoreTruncatedLine(t *testing.T) {
+	path, cleanup := tempPath(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	ioutil.WriteFile(path, []byte(`{"id":"evt_123","done":true,"attempts":1}`+"\n"+`{"id":"evt_456","do`), 0600)
+
+	store, err := OpenFileStore(path)
+	assert.Equal(t, err, nil)
+	record, _ := store.Get(ctx, "evt_123")
+	assert.T(t, record.Done)
+	record, _ = store.Get(ctx, "evt_456")
+	assert.Equal(t, record, (*Record)(nil))
+
+	// Records are appended after the last whole line.
+	store.Put(ctx, &Record{EventId: "evt_789", Done: true})
+	store.Close()
+
+	store, err = OpenFileStore(path)
+	assert.Equal(t, err, nil)
+	defer store.Close()
+	record, _ = store.Get(ctx, "evt_789")
+	assert.T(t, record.Done)
+}
+
+func TestFileStoreCorrupt(t *testing.T) {
+	path, cleanup := tempPath(t)
+	defer cleanup()
+
+	ioutil.WriteFile(path, []byte("garbage\n"+`{"id":"evt_123"}`+"\n"), 0600)
+	_, err := OpenFileStore(path)
+	assert.T(t, strings.HasPrefix(err.Error(), "webhook: "+path+", line 1: "), err)
+}
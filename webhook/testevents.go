@@ -0,0 +1,259 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// eventKinds maps each event type to the kind of object it is about, for the
+// objects stripe.NewEventObject builds.
+//
+// For more information: https://stripe.com/docs/api#event_types
+var eventKinds = map[string]string{
+	"account.updated":                      "account",
+	"application_fee.created":              "application_fee",
+	"application_fee.refunded":             "application_fee",
+	"balance.available":                    "balance",
+	"charge.succeeded":                     "charge",
+	"charge.failed":                        "charge",
+	"charge.refunded":                      "charge",
+	"charge.captured":                      "charge",
+	"charge.updated":                       "charge",
+	"charge.dispute.created":               "dispute",
+	"charge.dispute.updated":               "dispute",
+	"charge.dispute.closed":                "dispute",
+	"customer.created":                     "customer",
+	"customer.updated":                     "customer",
+	"customer.deleted":                     "customer",
+	"customer.card.created":                "card",
+	"customer.card.updated":                "card",
+	"customer.card.deleted":                "card",
+	"customer.subscription.created":        "subscription",
+	"customer.subscription.updated":        "subscription",
+	"customer.subscription.deleted":        "subscription",
+	"customer.subscription.trial_will_end": "subscription",
+	"customer.discount.created":            "discount",
+	"customer.discount.updated":            "discount",
+	"customer.discount.deleted":            "discount",
+	"invoice.created":                      "invoice",
+	"invoice.updated":                      "invoice",
+	"invoice.payment_succeeded":            "invoice",
+	"invoice.payment_failed":               "invoice",
+	"invoiceitem.created":                  "invoiceitem",
+	"invoiceitem.updated":                  "invoiceitem",
+	"invoiceitem.deleted":                  "invoiceitem",
+	"plan.created":                         "plan",
+	"plan.updated":                         "plan",
+	"plan.deleted":                         "plan",
+	"coupon.created":                       "coupon",
+	"coupon.deleted":                       "coupon",
+	"recipient.created":                    "recipient",
+	"recipient.updated":                    "recipient",
+	"recipient.deleted":                    "recipient",
+	"transfer.created":                     "transfer",
+	"transfer.updated":                     "transfer",
+	"transfer.paid":                        "transfer",
+	"transfer.failed":                      "transfer",
+}
+
+// idPrefixes maps each object to the prefix of its ids, for the objects that
+// have one.
+var idPrefixes = map[string]string{
+	"account":         "acct_",
+	"application_fee": "fee_",
+	"card":            "card_",
+	"charge":          "ch_",
+	"coupon":          "co_",
+	"customer":        "cus_",
+	"invoice":         "in_",
+	"invoiceitem":     "ii_",
+	"plan":            "plan_",
+	"recipient":       "rp_",
+	"subscription":    "sub_",
+	"transfer":        "tr_",
+}
+
+// EventTypes returns, sorted, the event types NewTestEvent can build.
+func EventTypes() []string {
+	types := make([]string, 0, len(eventKinds))
+	for t := range eventKinds {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// EventOption configures the Events built by NewTestEvent.
+type EventOption func(*eventOptions)
+
+type eventOptions struct {
+	previous interface{}
+	livemode bool
+	created  time.Time
+}
+
+// WithPrevious sets the previous_attributes of an update event. previous is
+// either the attributes themselves, as a map[string]interface{}, or the whole
+// resource before the update, of the same type as the object of the Event:
+// its attributes that differ from the object's are the previous attributes,
+// so it is easiest built as a copy of the object.
+func WithPrevious(previous interface{}) EventOption {
+	return func(o *eventOptions) {
+		o.previous = previous
+	}
+}
+
+// WithLivemode sets whether the Event, and its object, are in live mode.
+// Events are in test mode by default.
+func WithLivemode(livemode bool) EventOption {
+	return func(o *eventOptions) {
+		o.livemode = livemode
+	}
+}
+
+// WithCreated sets when the Event was created. It defaults to the current
+// time.
+func WithCreated(created time.Time) EventOption {
+	return func(o *eventOptions) {
+		o.created = created
+	}
+}
+
+// NewTestEvent returns an Event of type eventType about object, as Stripe
+// would send it, for tests of webhook handlers. object must be a pointer to
+// the resource the type is about, such as a *stripe.Charge for
+// "charge.succeeded", or nil for an empty one.
+//
+// A copy of object is sent, with its id, object and timestamps filled in when
+// they are not set, and its livemode set as the Event's. Fields that depend on
+// what happened, such as Paid for "invoice.payment_succeeded", are left as
+// given:
+//
+//	event, _ := webhook.NewTestEvent("customer.subscription.updated",
+//		&stripe.Subscription{Customer: "cus_123", Quantity: 2},
+//		webhook.WithPrevious(map[string]interface{}{"quantity": 1}))
+func NewTestEvent(eventType string, object interface{}, opts ...EventOption) (*stripe.Event, error) {
+	o := &eventOptions{created: time.Now()}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	kind, ok := eventKinds[eventType]
+	if !ok {
+		return nil, fmt.Errorf("webhook: unknown event type %q", eventType)
+	}
+	newObject, _ := stripe.NewEventObject(kind)
+
+	v := reflect.ValueOf(newObject)
+	if object != nil {
+		given := reflect.ValueOf(object)
+		if given.Type() != v.Type() || given.IsNil() {
+			return nil, fmt.Errorf("webhook: %s is about a %s, not a %T", eventType, v.Type(), object)
+		}
+		v.Elem().Set(given.Elem())
+	}
+
+	setDefault(v.Elem(), "Id", idPrefixes[kind]+randomId())
+	setDefault(v.Elem(), "Object", kind)
+	for _, name := range []string{"Created", "Date", "Start"} {
+		setDefault(v.Elem(), name, o.created.Unix())
+	}
+	if f := v.Elem().FieldByName("Livemode"); f.IsValid() {
+		f.SetBool(o.livemode)
+	}
+
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := previousAttributes(data, o.previous)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stripe.Event{
+		Id:              "evt_" + randomId(),
+		Object:          "event",
+		Data:            &stripe.EventData{Object: data, PreviousAttributes: previous},
+		Livemode:        o.livemode,
+		Created:         o.created.Unix(),
+		PendingWebhooks: 1,
+		Type:            eventType,
+	}, nil
+}
+
+// NewTestPayload builds an Event as NewTestEvent does, and returns it encoded
+// along with the Stripe-Signature header signing it with secret, as
+// GenerateTestPayload does.
+func NewTestPayload(eventType string, object interface{}, secret string, opts ...EventOption) ([]byte, string, error) {
+	event, err := NewTestEvent(eventType, object, opts...)
+	if err != nil {
+		return nil, "", err
+	}
+	return GenerateTestPayload(event, secret)
+}
+
+// setDefault sets the field of v with the given name, if v has it and it is
+// the zero value.
+func setDefault(v reflect.Value, name string, value interface{}) {
+	f := v.FieldByName(name)
+	if f.IsValid() && f.Interface() == reflect.Zero(f.Type()).Interface() {
+		f.Set(reflect.ValueOf(value))
+	}
+}
+
+// filled are the attributes NewTestEvent fills in when not set.
+var filled = map[string]bool{"id": true, "object": true, "livemode": true, "created": true, "date": true, "start": true}
+
+// previousAttributes returns the previous_attributes of the object encoded in
+// data, given the previous option.
+func previousAttributes(data []byte, previous interface{}) (map[string]interface{}, error) {
+	switch previous := previous.(type) {
+	case nil:
+		return nil, nil
+	case map[string]interface{}:
+		return previous, nil
+	}
+
+	var current, before map[string]interface{}
+	if err := json.Unmarshal(data, &current); err != nil {
+		return nil, err
+	}
+	previousData, err := json.Marshal(previous)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(previousData, &before); err != nil {
+		return nil, fmt.Errorf("webhook: previous attributes must be an object, not a %T", previous)
+	}
+	if before["object"] != nil && before["object"] != "" && before["object"] != current["object"] {
+		return nil, fmt.Errorf("webhook: previous attributes are of a %v, not a %v", before["object"], current["object"])
+	}
+
+	changed := map[string]interface{}{}
+	for name, value := range before {
+		// Filled in by NewTestEvent, rather than changed.
+		if filled[name] && (value == nil || value == "" || value == float64(0) || value == false) {
+			continue
+		}
+		if !reflect.DeepEqual(value, current[name]) {
+			changed[name] = value
+		}
+	}
+	return changed, nil
+}
+
+// randomId returns 24 random hexadecimal characters, for the ids of test
+// Events and objects.
+func randomId() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
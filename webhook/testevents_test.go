@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"encoding/json"
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"github.com/bmizerany/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewTestEventTypes(t *testing.T) {
+	types := EventTypes()
+	assert.Equal(t, len(types), len(eventKinds))
+
+	for _, typ := range types {
+		event, err := NewTestEvent(typ, nil, WithCreated(signedAt))
+		assert.Equal(t, err, nil, typ)
+		assert.Equal(t, event.Type, typ)
+		assert.T(t, strings.HasPrefix(event.Id, "evt_"), event.Id)
+		assert.Equal(t, event.Created, signedAt.Unix())
+
+		// The object decodes to its resource.
+		assert.Equal(t, event.DataObjectType(), eventKinds[typ], typ)
+		object, err := event.DataObject()
+		assert.Equal(t, err, nil, typ)
+		_, raw := object.(json.RawMessage)
+		assert.Equal(t, raw, false, typ)
+	}
+}
+
+func TestNewTestEvent(t *testing.T) {
+	charge := &stripe.Charge{Amount: 1000, Currency: "usd", Paid: true}
+	event, err := NewTestEvent("charge.succeeded", charge, WithLivemode(true), WithCreated(signedAt))
+	assert.Equal(t, err, nil)
+	assert.T(t, event.Livemode)
+
+	decoded, err := event.Charge()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, decoded.Amount, int64(1000))
+	assert.T(t, decoded.Paid)
+	assert.T(t, decoded.Livemode)
+	assert.Equal(t, decoded.Object, "charge")
+	assert.Equal(t, decoded.Created, signedAt.Unix())
+	assert.T(t, strings.HasPrefix(decoded.Id, "ch_"), decoded.Id)
+
+	// The object given is left as it is.
+	assert.Equal(t, *charge, stripe.Charge{Amount: 1000, Currency: "usd", Paid: true})
+
+	// Fields that are set are kept.
+	event, _ = NewTestEvent("invoice.payment_failed", &stripe.Invoice{Id: "in_123", Date: 1300000000})
+	invoice, _ := event.Invoice()
+	assert.Equal(t, invoice.Id, "in_123")
+	assert.Equal(t, invoice.Date, int64(1300000000))
+	assert.T(t, !invoice.Livemode)
+}
+
+func TestNewTestEventPrevious(t *testing.T) {
+	plan := &stripe.Plan{Id: "gold", Amount: 2000}
+	current := &stripe.Subscription{Id: "sub_123", Customer: "cus_123", Plan: plan, Quantity: 2, Status: "active"}
+	previous := *current
+	previous.Quantity = 1
+	previous.Status = "trialing"
+
+	event, err := NewTestEvent("customer.subscription.updated", current, WithPrevious(&previous))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, event.Data.PreviousAttributes, map[string]interface{}{"quantity": float64(1), "status": "trialing"})
+
+	diff, err := event.SubscriptionDiff()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, diff.Previous.Quantity, int64(1))
+	assert.Equal(t, diff.Current.Quantity, int64(2))
+	assert.T(t, diff.Changes.Changed("status"))
+	assert.T(t, !diff.Changes.Changed("plan.id"))
+
+	// The attributes themselves.
+	event, _ = NewTestEvent("customer.subscription.updated", current, WithPrevious(map[string]interface{}{"plan": map[string]interface{}{"id": "silver"}}))
+	diff, _ = event.SubscriptionDiff()
+	change, _ := diff.Changes.Get("plan.id")
+	assert.Equal(t, change, stripe.FieldChange{Path: "plan.id", Old: "silver", New: "gold"})
+
+	_, err = NewTestEvent("customer.subscription.updated", current, WithPrevious(&stripe.Customer{Object: "customer"}))
+	assert.Equal(t, err.Error(), "webhook: previous attributes are of a customer, not a subscription")
+}
+
+func TestNewTestEventErrors(t *testing.T) {
+	_, err := NewTestEvent("charge.exploded", nil)
+	assert.Equal(t, err.Error(), `webhook: unknown event type "charge.exploded"`)
+
+	_, err = NewTestEvent("charge.succeeded", &stripe.Invoice{})
+	assert.Equal(t, err.Error(), "webhook: charge.succeeded is about a *stripe.Charge, not a *stripe.Invoice")
+
+	_, err = NewTestEvent("charge.succeeded", stripe.Charge{})
+	assert.Equal(t, err.Error(), "webhook: charge.succeeded is about a *stripe.Charge, not a stripe.Charge")
+}
+
+func TestNewTestPayload(t *testing.T) {
+	payload, header, err := NewTestPayload("customer.created", &stripe.Customer{Email: "apt@stripe.com"}, secret, WithCreated(time.Now().Add(-time.Hour)))
+	assert.Equal(t, err, nil)
+
+	event, err := ConstructEvent(payload, header, []string{secret})
+	assert.Equal(t, err, nil)
+	customer, _ := event.Customer()
+	assert.Equal(t, customer.Email, "apt@stripe.com")
+}
@@ -0,0 +1,245 @@
+// Package webhook verifies the signatures of the events Stripe sends to
+// webhook endpoints, and decodes them into a stripe.Event:
+//
+//	payload, _ := ioutil.ReadAll(req.Body)
+//	event, err := webhook.ConstructEvent(payload, req.Header.Get("Stripe-Signature"), []string{secret})
+//	if err != nil {
+//		// Not sent by Stripe, or replayed: reject it.
+//	}
+//
+// For more information: https://stripe.com/docs/webhooks/signatures
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SignatureHeader is the header Stripe sends the signature of a payload in.
+	SignatureHeader = "Stripe-Signature"
+
+	// DefaultTolerance is how far the timestamp of a signature may be from the
+	// current time before it is rejected, unless set with WithTolerance.
+	DefaultTolerance = 5 * time.Minute
+
+	// signingScheme is the only scheme signatures are verified for. Others,
+	// such as the v0 test signatures, are ignored.
+	signingScheme = "v1"
+)
+
+var (
+	// ErrNotSigned is returned when the Stripe-Signature header is missing.
+	ErrNotSigned = errors.New("webhook: no Stripe-Signature header")
+
+	// ErrInvalidHeader is returned when the Stripe-Signature header has no
+	// timestamp or no v1 signature, or can't be parsed.
+	ErrInvalidHeader = errors.New("webhook: malformed Stripe-Signature header")
+
+	// ErrNoValidSignature is returned when none of the signatures in the header
+	// match the payload signed with any of the secrets.
+	ErrNoValidSignature = errors.New("webhook: no signature matches the payload")
+
+	// ErrTooOld is returned when the timestamp of the signatures is further from
+	// the current time than the tolerance, as for a replayed payload.
+	ErrTooOld = errors.New("webhook: timestamp outside of the tolerance window")
+
+	// ErrInvalidPayload matches, through errors.Is, the PayloadError returned
+	// when a correctly signed payload is not an event.
+	ErrInvalidPayload = errors.New("webhook: malformed payload")
+)
+
+// PayloadError is returned when a payload has a valid signature, but can't be
+// decoded into a stripe.Event.
+type PayloadError struct {
+	Err error
+}
+
+// PayloadError must implement an Error() method to satisfy the error interface.
+func (e *PayloadError) Error() string {
+	return ErrInvalidPayload.Error() + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying encoding/json error.
+func (e *PayloadError) Unwrap() error { return e.Err }
+
+// Is reports whether target is ErrInvalidPayload.
+func (e *PayloadError) Is(target error) bool { return target == ErrInvalidPayload }
+
+// Option configures how signatures are verified.
+type Option func(*options)
+
+type options struct {
+	tolerance time.Duration
+	now       func() time.Time
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{tolerance: DefaultTolerance, now: time.Now}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithTolerance sets how far the timestamp of a signature may be from the
+// current time. A tolerance of 0 or less disables the check, which is only
+// safe for payloads that were verified when they were received.
+func WithTolerance(tolerance time.Duration) Option {
+	return func(o *options) {
+		o.tolerance = tolerance
+	}
+}
+
+// WithClock sets the function that returns the current time, against which
+// timestamps are checked. It defaults to time.Now.
+func WithClock(now func() time.Time) Option {
+	return func(o *options) {
+		o.now = now
+	}
+}
+
+// ConstructEvent verifies the signature of payload, as given in the
+// Stripe-Signature header, and decodes it into an Event. The signature must
+// match one of secrets: pass both the old and the new secret while rolling
+// them, and the events signed with either are accepted.
+func ConstructEvent(payload []byte, header string, secrets []string, opts ...Option) (*stripe.Event, error) {
+	if err := ValidatePayload(payload, header, secrets, opts...); err != nil {
+		return nil, err
+	}
+
+	event := stripe.Event{}
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, &PayloadError{Err: err}
+	}
+	if event.Id == "" {
+		return nil, &PayloadError{Err: errors.New("no event id")}
+	}
+	return &event, nil
+}
+
+// ValidatePayload verifies the signature of payload, as ConstructEvent does,
+// without decoding it.
+func ValidatePayload(payload []byte, header string, secrets []string, opts ...Option) error {
+	o := newOptions(opts)
+
+	timestamp, signatures, err := parseHeader(header)
+	if err != nil {
+		return err
+	}
+
+	if !validSignature(payload, timestamp, signatures, secrets) {
+		return ErrNoValidSignature
+	}
+
+	// The timestamp is only trusted once the signature over it is.
+	if o.tolerance > 0 {
+		age := o.now().Sub(timestamp)
+		if age > o.tolerance || age < -o.tolerance {
+			return fmt.Errorf("%w: signed at %s, %s from now, tolerance is %s",
+				ErrTooOld, timestamp.UTC().Format(time.RFC3339), age.Round(time.Second), o.tolerance)
+		}
+	}
+
+	return nil
+}
+
+// validSignature reports whether one of signatures is that of payload and
+// timestamp under one of secrets.
+func validSignature(payload []byte, timestamp time.Time, signatures [][]byte, secrets []string) bool {
+	for _, secret := range secrets {
+		expected := ComputeSignature(timestamp, payload, secret)
+		for _, signature := range signatures {
+			if hmac.Equal(expected, signature) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseHeader returns the timestamp and v1 signatures in a Stripe-Signature
+// header, such as "t=1492774577,v1=5257a869...,v0=6ffbb59b...".
+func parseHeader(header string) (time.Time, [][]byte, error) {
+	if header == "" {
+		return time.Time{}, nil, ErrNotSigned
+	}
+
+	var timestamp time.Time
+	var signatures [][]byte
+
+	for _, pair := range strings.Split(header, ",") {
+		parts := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(parts) != 2 {
+			return time.Time{}, nil, fmt.Errorf("%w: %q is not a key=value pair", ErrInvalidHeader, pair)
+		}
+
+		switch parts[0] {
+		case "t":
+			t, err := strconv.ParseInt(parts[1], 10, 64)
+			if err != nil {
+				return time.Time{}, nil, fmt.Errorf("%w: invalid timestamp %q", ErrInvalidHeader, parts[1])
+			}
+			timestamp = time.Unix(t, 0)
+		case signingScheme:
+			signature, err := hex.DecodeString(parts[1])
+			if err != nil {
+				continue
+			}
+			signatures = append(signatures, signature)
+		}
+	}
+
+	if timestamp.IsZero() {
+		return time.Time{}, nil, fmt.Errorf("%w: no timestamp", ErrInvalidHeader)
+	}
+	if len(signatures) == 0 {
+		return time.Time{}, nil, fmt.Errorf("%w: no %s signature", ErrInvalidHeader, signingScheme)
+	}
+
+	return timestamp, signatures, nil
+}
+
+// ComputeSignature returns the v1 signature of payload, sent at timestamp, for
+// secret: the HMAC-SHA256 of the timestamp, a dot, and the payload.
+func ComputeSignature(timestamp time.Time, payload []byte, secret string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// GenerateHeader returns the Stripe-Signature header Stripe would send with
+// payload at timestamp, with a v1 signature for each of secrets. It is meant
+// for tests of webhook endpoints.
+func GenerateHeader(payload []byte, timestamp time.Time, secrets ...string) string {
+	header := "t=" + strconv.FormatInt(timestamp.Unix(), 10)
+	for _, secret := range secrets {
+		header += "," + signingScheme + "=" + hex.EncodeToString(ComputeSignature(timestamp, payload, secret))
+	}
+	return header
+}
+
+// GenerateTestPayload encodes event, and returns it along with the
+// Stripe-Signature header signing it with secret at the current time. It is
+// meant for tests of webhook endpoints:
+//
+//	payload, header, _ := webhook.GenerateTestPayload(&stripe.Event{Id: "evt_123", Type: "charge.succeeded"}, secret)
+//	req := httptest.NewRequest("POST", "/webhook", bytes.NewReader(payload))
+//	req.Header.Set(webhook.SignatureHeader, header)
+func GenerateTestPayload(event *stripe.Event, secret string) ([]byte, string, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, "", err
+	}
+	return payload, GenerateHeader(payload, time.Now(), secret), nil
+}
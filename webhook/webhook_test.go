@@ -0,0 +1,158 @@
+package webhook
+
+import (
+	"errors"
+	"github.com/andrewpthorp/stripe-go/stripe"
+	"github.com/bmizerany/assert"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+const secret = "whsec_123456789"
+
+var signedAt = time.Unix(1400000000, 0)
+
+// at returns an Option that fixes the current time to d after signedAt.
+func at(d time.Duration) Option {
+	return WithClock(func() time.Time { return signedAt.Add(d) })
+}
+
+func loadFixture(t *testing.T, f string) []byte {
+	payload, err := ioutil.ReadFile("../fixtures/" + f)
+	assert.Equal(t, err, nil)
+	return payload
+}
+
+func TestConstructEvent(t *testing.T) {
+	payload := loadFixture(t, "events/event.json")
+	header := GenerateHeader(payload, signedAt, secret)
+
+	event, err := ConstructEvent(payload, header, []string{secret}, at(time.Minute))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, event.Id, "evt_123456789")
+	assert.Equal(t, event.Type, "customer.subscription.updated")
+	subscription, err := event.Subscription()
+	assert.Equal(t, err, nil)
+	assert.Equal(t, subscription.Id, "sub_123456789")
+}
+
+func TestConstructEventRotatedSecrets(t *testing.T) {
+	payload := loadFixture(t, "events/event.json")
+
+	// Signed with the old secret only, while both are accepted.
+	header := GenerateHeader(payload, signedAt, "whsec_old")
+	_, err := ConstructEvent(payload, header, []string{secret, "whsec_old"}, at(0))
+	assert.Equal(t, err, nil)
+
+	// Signed with both, while only the new one is accepted.
+	header = GenerateHeader(payload, signedAt, "whsec_old", secret)
+	_, err = ConstructEvent(payload, header, []string{secret}, at(0))
+	assert.Equal(t, err, nil)
+
+	_, err = ConstructEvent(payload, header, []string{"whsec_other"}, at(0))
+	assert.Equal(t, err, ErrNoValidSignature)
+}
+
+func TestConstructEventBadSignature(t *testing.T) {
+	payload := loadFixture(t, "events/event.json")
+	header := GenerateHeader(payload, signedAt, secret)
+
+	tampered := []byte(strings.Replace(string(payload), "sub_123456789", "sub_987654321", 1))
+	event, err := ConstructEvent(tampered, header, []string{secret}, at(0))
+	assert.Equal(t, event, (*stripe.Event)(nil))
+	assert.Equal(t, err, ErrNoValidSignature)
+
+	_, err = ConstructEvent(payload, header, []string{"whsec_wrong"}, at(0))
+	assert.Equal(t, err, ErrNoValidSignature)
+
+	// The signature is over the timestamp too.
+	header = strings.Replace(header, "t=1400000000", "t=1400000001", 1)
+	_, err = ConstructEvent(payload, header, []string{secret}, at(0))
+	assert.Equal(t, err, ErrNoValidSignature)
+}
+
+func TestConstructEventStaleTimestamp(t *testing.T) {
+	payload := loadFixture(t, "events/event.json")
+	header := GenerateHeader(payload, signedAt, secret)
+
+	_, err := ConstructEvent(payload, header, []string{secret}, at(DefaultTolerance+time.Second))
+	assert.T(t, errors.Is(err, ErrTooOld), err)
+	assert.Equal(t, err.Error(), "webhook: timestamp outside of the tolerance window: signed at 2014-05-13T16:53:20Z, 5m1s from now, tolerance is 5m0s")
+
+	_, err = ConstructEvent(payload, header, []string{secret}, at(-DefaultTolerance-time.Second))
+	assert.T(t, errors.Is(err, ErrTooOld), err)
+
+	_, err = ConstructEvent(payload, header, []string{secret}, at(time.Hour), WithTolerance(2*time.Hour))
+	assert.Equal(t, err, nil)
+
+	_, err = ConstructEvent(payload, header, []string{secret}, at(24*time.Hour), WithTolerance(0))
+	assert.Equal(t, err, nil)
+}
+
+func TestConstructEventBadSignatureStaleTimestamp(t *testing.T) {
+	payload := loadFixture(t, "events/event.json")
+	header := GenerateHeader(payload, signedAt, "whsec_wrong")
+
+	_, err := ConstructEvent(payload, header, []string{secret}, at(DefaultTolerance+time.Second))
+	assert.Equal(t, err, ErrNoValidSignature)
+}
+
+func TestConstructEventInvalidHeader(t *testing.T) {
+	payload := loadFixture(t, "events/event.json")
+	signature := GenerateHeader(payload, signedAt, secret)[len("t=1400000000,"):]
+
+	_, err := ConstructEvent(payload, "", []string{secret}, at(0))
+	assert.Equal(t, err, ErrNotSigned)
+
+	tests := []struct {
+		header  string
+		message string
+	}{
+		{signature, "webhook: malformed Stripe-Signature header: no timestamp"},
+		{"t=1400000000", "webhook: malformed Stripe-Signature header: no v1 signature"},
+		{"t=1400000000,v0=abcdef", "webhook: malformed Stripe-Signature header: no v1 signature"},
+		{"t=1400000000,v1=not-hex", "webhook: malformed Stripe-Signature header: no v1 signature"},
+		{"t=yesterday," + signature, `webhook: malformed Stripe-Signature header: invalid timestamp "yesterday"`},
+		{"garbage", `webhook: malformed Stripe-Signature header: "garbage" is not a key=value pair`},
+	}
+
+	for _, test := range tests {
+		_, err := ConstructEvent(payload, test.header, []string{secret}, at(0))
+		assert.T(t, errors.Is(err, ErrInvalidHeader), err)
+		assert.Equal(t, err.Error(), test.message)
+	}
+
+	// Signatures of other schemes, and those that are not hex, are ignored.
+	_, err = ConstructEvent(payload, "t=1400000000,v0=abcdef,v1=zz,"+signature, []string{secret}, at(0))
+	assert.Equal(t, err, nil)
+}
+
+func TestConstructEventMalformedPayload(t *testing.T) {
+	for _, payload := range []string{`{"id": "evt_123", "created": "yesterday"}`, `{"object": "event"}`, `<html></html>`} {
+		header := GenerateHeader([]byte(payload), signedAt, secret)
+		event, err := ConstructEvent([]byte(payload), header, []string{secret}, at(0))
+		assert.Equal(t, event, (*stripe.Event)(nil))
+		assert.T(t, errors.Is(err, ErrInvalidPayload), err)
+
+		var payloadErr *PayloadError
+		assert.T(t, errors.As(err, &payloadErr))
+		assert.T(t, strings.HasPrefix(err.Error(), "webhook: malformed payload: "), err)
+	}
+}
+
+func TestGenerateHeader(t *testing.T) {
+	header := GenerateHeader([]byte(`{"id": "evt_123"}`), signedAt, secret)
+	assert.Equal(t, header, "t=1400000000,v1=8c9b9a3f934a3ca6a426f6b6ae028e6296b158b5d69ab90323bc83920e25ed6d")
+}
+
+func TestGenerateTestPayload(t *testing.T) {
+	payload, header, err := GenerateTestPayload(&stripe.Event{Id: "evt_123", Type: "charge.succeeded"}, secret)
+	assert.Equal(t, err, nil)
+
+	event, err := ConstructEvent(payload, header, []string{secret})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, event.Id, "evt_123")
+	assert.Equal(t, event.Type, "charge.succeeded")
+}